@@ -0,0 +1,141 @@
+package jsondiff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PathStats describes how often a value at a certain path differs, see
+// Aggregator.
+type PathStats struct {
+	// JSON Pointer to the value, see Change.
+	Path string
+	// Number of comparisons where the value differs, and its breakdown by the
+	// kind of the change.
+	Count    int
+	Added    int
+	Removed  int
+	Modified int
+	// First changes of the value in the order they were added.
+	Examples []Change
+}
+
+// Aggregator collects changes produced by many comparisons and reports which
+// paths differ most often, e.g. to analyze results of diffing shadow traffic.
+// It's safe to use concurrently.
+type Aggregator struct {
+	mu          sync.Mutex
+	examples    int
+	comparisons int
+	paths       map[string]*PathStats
+}
+
+// NewAggregator returns an aggregator which keeps up to the given number of
+// example changes per path.
+func NewAggregator(examples int) *Aggregator {
+	return &Aggregator{
+		examples: examples,
+		paths:    make(map[string]*PathStats),
+	}
+}
+
+// Add records changes produced by a single comparison, see CompareWithChanges.
+// Unchanged values are ignored.
+func (a *Aggregator) Add(changes []Change) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.comparisons++
+	for _, c := range changes {
+		if c.Kind == ChangeUnchanged {
+			continue
+		}
+		s := a.paths[c.Path]
+		if s == nil {
+			s = &PathStats{Path: c.Path}
+			a.paths[c.Path] = s
+		}
+		s.Count++
+		switch c.Kind {
+		case ChangeAdded:
+			s.Added++
+		case ChangeRemoved:
+			s.Removed++
+		case ChangeModified:
+			s.Modified++
+		}
+		if len(s.Examples) < a.examples {
+			s.Examples = append(s.Examples, c)
+		}
+	}
+}
+
+// Comparisons returns the number of comparisons recorded so far.
+func (a *Aggregator) Comparisons() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.comparisons
+}
+
+// Report returns statistics of all the paths which differed at least once,
+// ordered by the number of differences starting from the most frequent one.
+// Paths with the same number of differences are ordered alphabetically.
+func (a *Aggregator) Report() []PathStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	report := make([]PathStats, 0, len(a.paths))
+	for _, s := range a.paths {
+		r := *s
+		r.Examples = append([]Change(nil), s.Examples...)
+		report = append(report, r)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Count != report[j].Count {
+			return report[i].Count > report[j].Count
+		}
+		return report[i].Path < report[j].Path
+	})
+	return report
+}
+
+var prometheusEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus writes the counts in the Prometheus text exposition format,
+// so that they can be served by a metrics endpoint. Metric names start with the
+// given prefix, e.g. with prefix "jsondiff" it writes:
+//
+//	# HELP jsondiff_comparisons_total Number of comparisons.
+//	# TYPE jsondiff_comparisons_total counter
+//	jsondiff_comparisons_total 10
+//	# HELP jsondiff_differences_total Number of comparisons where the value at the path differs.
+//	# TYPE jsondiff_differences_total counter
+//	jsondiff_differences_total{path="/a",kind="modified"} 3
+//
+// Kind is one of "added", "removed" and "modified", kinds with zero counts are
+// omitted.
+func (a *Aggregator) WritePrometheus(w io.Writer, prefix string) error {
+	report := a.Report()
+	bw := bufio.NewWriter(w)
+	comparisons := prefix + "_comparisons_total"
+	differences := prefix + "_differences_total"
+	fmt.Fprintf(bw, "# HELP %s Number of comparisons.\n", comparisons)
+	fmt.Fprintf(bw, "# TYPE %s counter\n", comparisons)
+	fmt.Fprintf(bw, "%s %d\n", comparisons, a.Comparisons())
+	fmt.Fprintf(bw, "# HELP %s Number of comparisons where the value at the path differs.\n", differences)
+	fmt.Fprintf(bw, "# TYPE %s counter\n", differences)
+	for _, s := range report {
+		path := prometheusEscaper.Replace(s.Path)
+		for _, c := range [...]struct {
+			kind  string
+			count int
+		}{{"added", s.Added}, {"removed", s.Removed}, {"modified", s.Modified}} {
+			if c.count != 0 {
+				fmt.Fprintf(bw, "%s{path=\"%s\",kind=\"%s\"} %d\n", differences, path, c.kind, c.count)
+			}
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,60 @@
+package jsondiff
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAggregator(t *testing.T) {
+	opts := DefaultJSONOptions()
+	agg := NewAggregator(1)
+	pairs := [][2]string{
+		{`{"a": 1, "b": 1}`, `{"a": 2, "b": 1}`},
+		{`{"a": 1, "b": 1}`, `{"a": 3}`},
+		{`{"a": 1, "b": 1}`, `{"a": 1, "b": 1}`},
+		{`{"a": 1}`, `{"a": 4, "c": 1}`},
+	}
+	for _, p := range pairs {
+		_, _, changes := CompareWithChanges([]byte(p[0]), []byte(p[1]), &opts)
+		agg.Add(changes)
+	}
+	if n := agg.Comparisons(); n != 4 {
+		t.Errorf("got %d comparisons, expected 4", n)
+	}
+	report := agg.Report()
+	if len(report) != 3 {
+		t.Fatalf("got %d paths, expected 3: %+v", len(report), report)
+	}
+	a := report[0]
+	if a.Path != "/a" || a.Count != 3 || a.Modified != 3 || len(a.Examples) != 1 {
+		t.Errorf("unexpected stats: %+v", a)
+	}
+	if report[1].Path != "/b" || report[1].Removed != 1 || report[2].Path != "/c" || report[2].Added != 1 {
+		t.Errorf("unexpected stats order: %+v", report)
+	}
+}
+
+func TestAggregatorWritePrometheus(t *testing.T) {
+	agg := NewAggregator(0)
+	agg.Add([]Change{
+		{Path: "/a", Kind: ChangeModified},
+		{Path: `/b"c`, Kind: ChangeAdded},
+	})
+	agg.Add([]Change{{Path: "/a", Kind: ChangeRemoved}})
+	var buf bytes.Buffer
+	if err := agg.WritePrometheus(&buf, "shadow"); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP shadow_comparisons_total Number of comparisons.
+# TYPE shadow_comparisons_total counter
+shadow_comparisons_total 2
+# HELP shadow_differences_total Number of comparisons where the value at the path differs.
+# TYPE shadow_differences_total counter
+shadow_differences_total{path="/a",kind="removed"} 1
+shadow_differences_total{path="/a",kind="modified"} 1
+shadow_differences_total{path="/b\"c",kind="added"} 1
+`
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
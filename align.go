@@ -0,0 +1,41 @@
+package jsondiff
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// pushKeyWidth starts printing an object whose keys selected by include are
+// aligned, see Options.AlignKeys. It must be followed by popKeyWidth.
+func (ctx *context) pushKeyWidth(keys []string, include func(i int) bool) {
+	width := 0
+	if ctx.opts.AlignKeys {
+		for i, k := range keys {
+			if n := ctx.keyLen(k); n > width && include(i) {
+				width = n
+			}
+		}
+	}
+	ctx.keyWidths = append(ctx.keyWidths, width)
+}
+
+func (ctx *context) popKeyWidth() {
+	ctx.keyWidths = ctx.keyWidths[:len(ctx.keyWidths)-1]
+}
+
+// keyLen returns the width of the quoted key in the text output.
+func (ctx *context) keyLen(k string) int {
+	return utf8.RuneCountInString(quoteKey(k, ctx.opts))
+}
+
+// writeKey writes the object key padded to the width of the keys of the
+// object being printed.
+func (ctx *context) writeKey(k string) {
+	ctx.w.Key(k)
+	if len(ctx.keyWidths) == 0 || ctx.keyWidths[len(ctx.keyWidths)-1] == 0 {
+		return
+	}
+	if pad := ctx.keyWidths[len(ctx.keyWidths)-1] - ctx.keyLen(k); pad > 0 {
+		ctx.w.Text(strings.Repeat(" ", pad))
+	}
+}
@@ -0,0 +1,45 @@
+package jsondiff
+
+import "testing"
+
+func TestAlignKeys(t *testing.T) {
+	a := `{"name": "api", "replicas": 3, "env": {"LOG": "info", "TIMEOUT": 5}, "x": [1]}`
+	b := `{"name": "api", "replicas": 5, "env": {"LOG": "debug", "TIMEOUT": 5, "REGION": {"id": 1}}, "x": [1]}`
+	opts := DefaultMarkerOptions()
+	opts.AlignKeys = true
+	_, diff := Compare([]byte(a), []byte(b), &opts)
+	expected := `{
+    "env":      {
+        "LOG":     {~"info" => "debug"~},
+        {+"REGION":  {+}
+            {+"id": 1+}
+        {+}+},
+        "TIMEOUT": 5
+    },
+    "name":     "api",
+    "replicas": {~3 => 5~},
+    "x":        [
+        1
+    ]
+}`
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+
+	// only printed keys are aligned
+	opts.SkipMatches = true
+	opts.SkippedObjectProperty = nil
+	_, diff = Compare([]byte(a), []byte(b), &opts)
+	expected = `{
+    "env":      {
+        "LOG":    {~"info" => "debug"~},
+        {+"REGION": {+}
+            {+"id": 1+}
+        {+}+}
+    },
+    "replicas": {~3 => 5~}
+}`
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+}
@@ -0,0 +1,312 @@
+package jsondiff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// splitPointer splits a JSON Pointer into unescaped reference tokens.
+func splitPointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, errors.New("jsondiff: invalid json pointer: " + p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = pointerUnescaper.Replace(t)
+	}
+	return tokens, nil
+}
+
+// isPointerPrefix tells whether the value at pointer q is inside the value at
+// pointer p.
+func isPointerPrefix(p, q string) bool {
+	return len(q) > len(p) && strings.HasPrefix(q, p) && q[len(p)] == '/'
+}
+
+// arrayIndex parses an array index token, "-" refers to the element past the
+// end of the array.
+func arrayIndex(token string, n int) (int, bool) {
+	if token == "-" {
+		return n, true
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > n || (i != 0 && token[0] == '0') {
+		return 0, false
+	}
+	return i, true
+}
+
+// patch returns a copy of v with the change applied at the given path. Only
+// collections along the path are copied. Added array elements are inserted at
+// their index, removed ones are deleted shifting the following elements.
+func patch(v interface{}, path []string, kind ChangeKind, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		if kind == ChangeRemoved {
+			return nil, nil
+		}
+		return value, nil
+	}
+	token, last := path[0], len(path) == 1
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		e, ok := vv[token]
+		if last && ok == (kind == ChangeAdded) {
+			return nil, errors.New("jsondiff: unexpected object property " + strconv.Quote(token))
+		}
+		if !last && !ok {
+			return nil, errors.New("jsondiff: missing object property " + strconv.Quote(token))
+		}
+		m := make(map[string]interface{}, len(vv)+1)
+		for k, e := range vv {
+			m[k] = e
+		}
+		if !last {
+			var err error
+			if m[token], err = patch(e, path[1:], kind, value); err != nil {
+				return nil, err
+			}
+		} else if kind == ChangeRemoved {
+			delete(m, token)
+		} else {
+			m[token] = value
+		}
+		return m, nil
+	case []interface{}:
+		i, ok := arrayIndex(token, len(vv))
+		if !ok || (i == len(vv) && (kind != ChangeAdded || !last)) {
+			return nil, errors.New("jsondiff: invalid array index " + strconv.Quote(token))
+		}
+		s := make([]interface{}, 0, len(vv)+1)
+		switch {
+		case !last:
+			e, err := patch(vv[i], path[1:], kind, value)
+			if err != nil {
+				return nil, err
+			}
+			s = append(append(append(s, vv[:i]...), e), vv[i+1:]...)
+		case kind == ChangeAdded:
+			s = append(append(append(s, vv[:i]...), value), vv[i:]...)
+		case kind == ChangeRemoved:
+			s = append(append(s, vv[:i]...), vv[i+1:]...)
+		default:
+			s = append(append(append(s, vv[:i]...), value), vv[i+1:]...)
+		}
+		return s, nil
+	}
+	return nil, errors.New("jsondiff: not a collection at " + strconv.Quote(token))
+}
+
+// arrayHole marks array elements which are removed, or not yet added, by
+// Apply. Holes are removed once all the changes are applied, so that array
+// indices of all the changes refer to the original document.
+type arrayHole struct{}
+
+func isHole(v interface{}) bool {
+	_, ok := v.(arrayHole)
+	return ok
+}
+
+func removeHoles(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, e := range vv {
+			vv[k] = removeHoles(e)
+		}
+	case []interface{}:
+		s := vv[:0]
+		for _, e := range vv {
+			if !isHole(e) {
+				s = append(s, removeHoles(e))
+			}
+		}
+		return s
+	}
+	return v
+}
+
+// applyChange applies the change at the given path modifying v in place where
+// possible and returns the new value.
+func applyChange(v interface{}, path []string, c *Change) (interface{}, error) {
+	if len(path) == 0 {
+		return c.New, nil
+	}
+	token, last := path[0], len(path) == 1
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		e, ok := vv[token]
+		switch {
+		case last && c.Kind == ChangeAdded:
+			if ok {
+				return nil, errors.New("unexpected object property " + strconv.Quote(token))
+			}
+		case !ok:
+			return nil, errors.New("missing object property " + strconv.Quote(token))
+		case last && c.Kind == ChangeRemoved:
+			delete(vv, token)
+			return vv, nil
+		}
+		if !last {
+			var err error
+			if vv[token], err = applyChange(e, path[1:], c); err != nil {
+				return nil, err
+			}
+		} else {
+			vv[token] = c.New
+		}
+		return vv, nil
+	case []interface{}:
+		i, err := strconv.Atoi(token)
+		if err != nil || i < 0 {
+			return nil, errors.New("invalid array index " + strconv.Quote(token))
+		}
+		if last && c.Kind == ChangeAdded {
+			switch {
+			case i >= len(vv):
+				for len(vv) <= i {
+					vv = append(vv, arrayHole{})
+				}
+				vv[i] = c.New
+			case isHole(vv[i]):
+				vv[i] = c.New
+			default:
+				vv = append(vv[:i], append([]interface{}{c.New}, vv[i:]...)...)
+			}
+			return vv, nil
+		}
+		if i >= len(vv) || isHole(vv[i]) {
+			return nil, errors.New("missing array element " + token)
+		}
+		switch {
+		case !last:
+			if vv[i], err = applyChange(vv[i], path[1:], c); err != nil {
+				return nil, err
+			}
+		case c.Kind == ChangeRemoved:
+			vv[i] = arrayHole{}
+		default:
+			vv[i] = c.New
+		}
+		return vv, nil
+	}
+	return nil, errors.New("not a collection at " + strconv.Quote(token))
+}
+
+// Apply applies changes to the JSON document and returns the resulting
+// document, e.g. applying changes produced by CompareWithChanges to the first
+// document produces the second one, including documents compared with options
+// which change the shape of collections, see Options.KeyedArrays. Paths of
+// changes refer to the original document, so changes can be applied in any
+// order. Old values of changed and removed values must be equal to the values
+// in the document, otherwise *ValidationError is returned, while added values
+// are not checked, see Validate. Returns an
+// error if the document is invalid JSON, or if a change can't be applied
+// because the value at its path is missing or, in case of added values,
+// already exists. The resulting document is compact.
+func Apply(doc []byte, changes []Change) ([]byte, error) {
+	v, err := decode(bytes.NewReader(doc))
+	if err != nil {
+		return nil, err
+	}
+	for i := range changes {
+		c := &changes[i]
+		path, err := splitPointer(c.Path)
+		if err != nil {
+			return nil, err
+		}
+		if c.Kind == ChangeRemoved && len(path) == 0 {
+			return nil, errors.New("jsondiff: can't remove the root value")
+		}
+		if c.Kind != ChangeAdded {
+			// changes of collections compared in a different shape, or of
+			// another document, would corrupt the document
+			if cur, ok := lookupPath(v, path); ok && !reflect.DeepEqual(cur, c.Old) {
+				return nil, &ValidationError{Path: c.Path, Reason: "value differs"}
+			}
+		}
+		if v, err = applyChange(v, path, c); err != nil {
+			return nil, fmt.Errorf("jsondiff: can't apply change at %q: %v", c.Path, err)
+		}
+	}
+	return []byte(encodeJSON(removeHoles(v), "", "")), nil
+}
+
+// ApplyAt works like Apply, but applies only the changes with paths matching
+// any of the path patterns, see Compare documentation for the path pattern
+// syntax. E.g. ApplyAt(doc, changes, "spec.**") applies only the changes
+// inside of the "spec" property.
+func ApplyAt(doc []byte, changes []Change, patterns ...string) ([]byte, error) {
+	var selected []Change
+	for _, c := range changes {
+		path, err := splitPointer(c.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range patterns {
+			if matchPath(splitPath(p), path) {
+				selected = append(selected, c)
+				break
+			}
+		}
+	}
+	return Apply(doc, selected)
+}
+
+// ValidationError describes a change which doesn't match the document, see
+// Validate.
+type ValidationError struct {
+	// JSON Pointer of the change.
+	Path   string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return "jsondiff: change at " + strconv.Quote(e.Path) + " doesn't match the document: " + e.Reason
+}
+
+// Validate checks whether changes can be applied to the JSON document without
+// modifying it, like the test operation of JSON Patch: old values of changed,
+// removed and unchanged values must be equal to the values in the document,
+// while added values must be missing from it. Returns *ValidationError for the
+// first change which doesn't match the document, see Apply.
+func Validate(doc []byte, changes []Change) error {
+	v, err := decode(bytes.NewReader(doc))
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		path, err := splitPointer(c.Path)
+		if err != nil {
+			return err
+		}
+		cur, ok := lookupPath(v, path)
+		switch {
+		case c.Kind == ChangeAdded:
+			if ok {
+				return &ValidationError{Path: c.Path, Reason: "value already exists"}
+			}
+			parent, ok := lookupPath(v, path[:len(path)-1])
+			switch parent.(type) {
+			case map[string]interface{}, []interface{}:
+			default:
+				ok = false
+			}
+			if !ok {
+				return &ValidationError{Path: c.Path, Reason: "parent collection is missing"}
+			}
+		case !ok:
+			return &ValidationError{Path: c.Path, Reason: "value is missing"}
+		case !reflect.DeepEqual(cur, c.Old):
+			return &ValidationError{Path: c.Path, Reason: "value differs"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+var applyCases = []struct {
+	a string
+	b string
+}{
+	{`{"a": 1, "b": [1, 2, 3]}`, `{"a": 2, "b": [1]}`},
+	{`{"a": [1], "b": {"x/y": null}}`, `{"a": [1, 2, 3], "b": {"x/y": {"c": true}}, "c": "d"}`},
+	{`[{"a": 1}, [1, 2], 3]`, `[{"a": 2, "b": 1}, [], 3, 4]`},
+	{`{"a": 1}`, `[1]`},
+	{`{"a": "<b>"}`, `{"a": "<b>", "c": 1.50}`},
+}
+
+func TestApply(t *testing.T) {
+	opts := DefaultJSONOptions()
+	for _, c := range applyCases {
+		_, _, changes := CompareWithChanges([]byte(c.a), []byte(c.b), &opts)
+		result, err := Apply([]byte(c.a), changes)
+		if err != nil {
+			t.Errorf("%s %s: %v", c.a, c.b, err)
+			continue
+		}
+		if diff, _ := Compare(result, []byte(c.b), &opts); diff != FullMatch {
+			t.Errorf("%s %s: got %s", c.a, c.b, result)
+		}
+		result, err = Apply([]byte(c.b), Invert(changes))
+		if err != nil {
+			t.Errorf("%s %s: inverted: %v", c.a, c.b, err)
+			continue
+		}
+		if diff, _ := Compare(result, []byte(c.a), &opts); diff != FullMatch {
+			t.Errorf("%s %s: inverted: got %s", c.a, c.b, result)
+		}
+	}
+
+	errCases := []struct {
+		doc     string
+		changes []Change
+	}{
+		{`{`, nil},
+		{`{"a": 1}`, []Change{{Path: "/b", Kind: ChangeRemoved}}},
+		{`{"a": 1}`, []Change{{Path: "/a", Kind: ChangeAdded}}},
+		{`[1]`, []Change{{Path: "/1", Kind: ChangeModified}}},
+		{`[1]`, []Change{{Path: "/0", Kind: ChangeRemoved}, {Path: "/0", Kind: ChangeModified}}},
+		{`[1]`, []Change{{Path: "/0/a", Kind: ChangeAdded}}},
+		{`[1]`, []Change{{Path: "0", Kind: ChangeAdded}}},
+		{`[1]`, []Change{{Path: "", Kind: ChangeRemoved}}},
+	}
+	for _, c := range errCases {
+		if result, err := Apply([]byte(c.doc), c.changes); err == nil {
+			t.Errorf("%s %+v: expected an error, got %s", c.doc, c.changes, result)
+		}
+	}
+}
+
+func TestApplyRewritingOptions(t *testing.T) {
+	for i, c := range rewritingCases {
+		opts := c.opts
+		_, _, changes := CompareWithChanges([]byte(c.a), []byte(c.b), &opts)
+		result, err := Apply([]byte(c.a), changes)
+		if err != nil {
+			t.Errorf("%s %s: %v", c.a, c.b, err)
+			continue
+		}
+		checkRewritingResult(t, i, result)
+		if result, err = ApplyAt([]byte(c.a), changes, "**"); err != nil {
+			t.Errorf("%s %s: ApplyAt: %v", c.a, c.b, err)
+			continue
+		}
+		checkRewritingResult(t, i, result)
+	}
+
+	// changes of another document are refused instead of corrupting it
+	opts := DefaultJSONOptions()
+	_, _, changes := CompareWithChanges([]byte(`{"x": [1, 2]}`), []byte(`{"x": [2, 3]}`), &opts)
+	_, err := Apply([]byte(`{"x": [2, 1]}`), changes)
+	if verr, ok := err.(*ValidationError); !ok || verr.Path != "/x/0" {
+		t.Errorf("got %v, expected conflict at /x/0", err)
+	}
+}
+
+func TestApplyAt(t *testing.T) {
+	opts := DefaultJSONOptions()
+	a := []byte(`{"spec": {"replicas": 1, "image": "a"}, "status": {"ready": 1}}`)
+	b := []byte(`{"spec": {"replicas": 3, "image": "b"}, "status": {"ready": 3}}`)
+	_, _, changes := CompareWithChanges(a, b, &opts)
+	result, err := ApplyAt(a, changes, "spec.**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"spec":{"image":"b","replicas":3},"status":{"ready":1}}`
+	if string(result) != expected {
+		t.Errorf("got %s, expected %s", result, expected)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	opts := DefaultJSONOptions()
+	for _, c := range applyCases {
+		_, _, changes := CompareWithChanges([]byte(c.a), []byte(c.b), &opts)
+		if err := Validate([]byte(c.a), changes); err != nil {
+			t.Errorf("%s %s: %v", c.a, c.b, err)
+		}
+	}
+
+	a := []byte(`{"a": 1, "b": [1, 2], "c": "x"}`)
+	_, _, changes := CompareWithChanges(a, []byte(`{"a": 2, "b": [1], "c": "x", "d": 1}`), &opts)
+	cases := []struct {
+		doc  string
+		path string
+	}{
+		{`{"a": 3, "b": [1, 2]}`, "/a"},
+		{`{"a": 1, "b": [1]}`, "/b/1"},
+		{`{"a": 1, "b": [1, 2], "d": 1}`, "/d"},
+		{`[]`, "/a"},
+	}
+	for _, c := range cases {
+		err := Validate([]byte(c.doc), changes)
+		if verr, ok := err.(*ValidationError); !ok || verr.Path != c.path {
+			t.Errorf("%s: got %v, expected conflict at %s", c.doc, err, c.path)
+		}
+	}
+	err := Validate([]byte(`1`), []Change{{Path: "/a/b", Kind: ChangeAdded}})
+	if verr, ok := err.(*ValidationError); !ok || verr.Reason != "parent collection is missing" {
+		t.Errorf("got %v for missing parent", err)
+	}
+}
@@ -0,0 +1,389 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"unicode/utf8"
+)
+
+const arenaBlockSize = 256
+
+// approximate sizes of the pooled memory besides deltaSize, see Arena.Budget
+var (
+	pointerSize   = int(reflect.TypeOf(&delta{}).Size())
+	valueSize     = int(reflect.TypeOf((*interface{})(nil)).Elem().Size())
+	mapEntrySize  = 3 * valueSize
+	mapHeaderSize = 48
+)
+
+// Arena reuses memory allocated by comparisons, which reduces allocation rate
+// of services comparing many documents. Comparisons performed with an arena
+// read documents into pooled buffers, decode their objects and arrays into
+// pooled maps and slices, and allocate their internal structures in it.
+// Release makes the memory available for the following comparisons. Results
+// of comparisons remain valid after Release, while decoded values passed to
+// callbacks, e.g. Options.Trace, must not be retained past it.
+//
+// Memory of an arena grows until it's released, so Release should be called
+// after every comparison or a batch of them. An arena is not safe for
+// concurrent use, use an arena per goroutine or a sync.Pool of arenas.
+type Arena struct {
+	// Budget limits the memory an arena keeps for the following comparisons,
+	// in bytes. Release frees pooled memory until the arena fits in the
+	// budget, e.g. after comparing unusually large documents. Zero means no
+	// limit. Sizes are approximate.
+	Budget int
+
+	deltas    [][]delta
+	block     int
+	pos       int
+	elems     []*delta
+	elemsUsed int
+	buf       bytes.Buffer
+
+	// buffers of the documents being decoded
+	inputs     [][]byte
+	inputsUsed int
+	// elements of decoded arrays, and a stack of elements of arrays being
+	// decoded
+	values     []interface{}
+	valuesUsed int
+	scratch    []interface{}
+	// decoded objects, cleared by Release, and the largest number of
+	// entries each of them had
+	maps       []map[string]interface{}
+	mapsUsed   int
+	mapEntries int
+	mapSizes   []int
+	// interned object keys
+	keys     map[string]string
+	keyBytes int
+}
+
+// NewArena returns an empty arena.
+func NewArena() *Arena {
+	return &Arena{}
+}
+
+func (a *Arena) delta() *delta {
+	if a.block < len(a.deltas) && a.pos == len(a.deltas[a.block]) {
+		a.block++
+		a.pos = 0
+	}
+	if a.block == len(a.deltas) {
+		a.deltas = append(a.deltas, make([]delta, arenaBlockSize))
+	}
+	d := &a.deltas[a.block][a.pos]
+	a.pos++
+	return d
+}
+
+func (a *Arena) newElems(n int) []*delta {
+	if a.elemsUsed+n > len(a.elems) {
+		size := 4 * len(a.elems)
+		if size < n {
+			size = n
+		}
+		if size < arenaBlockSize {
+			size = arenaBlockSize
+		}
+		// previously returned slices still point to the old block, which is
+		// freed by the garbage collector after Release
+		a.elems = make([]*delta, size)
+		a.elemsUsed = 0
+	}
+	s := a.elems[a.elemsUsed : a.elemsUsed : a.elemsUsed+n]
+	a.elemsUsed += n
+	return s
+}
+
+// Release makes memory used by comparisons available for the following ones,
+// and frees the memory beyond the Budget.
+func (a *Arena) Release() {
+	for i := 0; i <= a.block && i < len(a.deltas); i++ {
+		block := a.deltas[i]
+		if i == a.block {
+			block = block[:a.pos]
+		}
+		for j := range block {
+			// drop references to decoded documents
+			block[j] = delta{}
+		}
+	}
+	for i := range a.elems[:a.elemsUsed] {
+		a.elems[i] = nil
+	}
+	for i := range a.values[:a.valuesUsed] {
+		a.values[i] = nil
+	}
+	for i, m := range a.maps[:a.mapsUsed] {
+		if len(m) > a.mapSizes[i] {
+			a.mapEntries += len(m) - a.mapSizes[i]
+			a.mapSizes[i] = len(m)
+		}
+		for k := range m {
+			delete(m, k)
+		}
+	}
+	a.block, a.pos, a.elemsUsed = 0, 0, 0
+	a.inputsUsed, a.valuesUsed, a.mapsUsed = 0, 0, 0
+	a.buf.Reset()
+	if a.Budget > 0 {
+		a.trim()
+	}
+}
+
+// size returns the approximate size of the pooled memory in bytes.
+func (a *Arena) size() int {
+	n := len(a.deltas)*arenaBlockSize*deltaSize + cap(a.elems)*pointerSize + a.buf.Cap()
+	for _, in := range a.inputs {
+		n += cap(in)
+	}
+	n += (cap(a.values) + cap(a.scratch)) * valueSize
+	n += len(a.maps)*mapHeaderSize + a.mapEntries*mapEntrySize + a.keyBytes
+	return n
+}
+
+// trim frees pools of the released arena, starting with the ones which are
+// the cheapest to refill, until it fits in the budget.
+func (a *Arena) trim() {
+	for step := 0; step < 5 && a.size() > a.Budget; step++ {
+		switch step {
+		case 0:
+			a.maps, a.mapSizes, a.mapEntries = nil, nil, 0
+			a.keys, a.keyBytes = nil, 0
+		case 1:
+			a.inputs = nil
+		case 2:
+			a.values, a.scratch = nil, nil
+		case 3:
+			a.elems = nil
+			a.buf = bytes.Buffer{}
+		case 4:
+			a.deltas = nil
+		}
+	}
+}
+
+// Compare works like Compare, but allocates internal structures in the arena.
+func (a *Arena) Compare(x, y []byte, opts *Options) (Difference, string) {
+	ctx := context{opts: opts, arena: a}
+	return ctx.compareStreams(bytes.NewReader(x), bytes.NewReader(y))
+}
+
+func (ctx *context) newDelta(d delta) *delta {
+	if ctx.arena == nil {
+		return &d
+	}
+	p := ctx.arena.delta()
+	*p = d
+	return p
+}
+
+func (ctx *context) newElems(n int) []*delta {
+	if ctx.arena == nil {
+		return make([]*delta, 0, n)
+	}
+	return ctx.arena.newElems(n)
+}
+
+// decode decodes a JSON document read into a pooled buffer, objects and arrays
+// are allocated in the arena. Documents which are not valid UTF-8 encoded
+// JSON, or have data after the value, are decoded as usual.
+func (a *Arena) decode(r io.Reader, strict bool) (interface{}, error) {
+	data, err := a.read(r)
+	if err != nil {
+		return nil, newDecodeError(err, int64(len(data)))
+	}
+	if !json.Valid(data) || !utf8.Valid(data) {
+		return decodeValue(newEncodingReader(bytes.NewReader(data)), strict)
+	}
+	d := arenaDecoder{arena: a, data: data}
+	return d.value(), nil
+}
+
+// read reads the whole stream into a pooled buffer.
+func (a *Arena) read(r io.Reader) ([]byte, error) {
+	if a.inputsUsed == len(a.inputs) {
+		a.inputs = append(a.inputs, make([]byte, 0, 512))
+	}
+	buf := a.inputs[a.inputsUsed][:0]
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err != nil {
+			a.inputs[a.inputsUsed] = buf
+			a.inputsUsed++
+			if err == io.EOF {
+				err = nil
+			}
+			return buf, err
+		}
+	}
+}
+
+func (a *Arena) newMap() map[string]interface{} {
+	if a.mapsUsed == len(a.maps) {
+		a.maps = append(a.maps, make(map[string]interface{}))
+		a.mapSizes = append(a.mapSizes, 0)
+	}
+	m := a.maps[a.mapsUsed]
+	a.mapsUsed++
+	return m
+}
+
+// newValues returns a copy of array elements allocated in the arena, appending
+// to it doesn't overwrite other arrays.
+func (a *Arena) newValues(vs []interface{}) []interface{} {
+	n := len(vs)
+	if n == 0 {
+		return []interface{}{}
+	}
+	if a.valuesUsed+n > len(a.values) {
+		size := 4 * len(a.values)
+		if size < n {
+			size = n
+		}
+		if size < arenaBlockSize {
+			size = arenaBlockSize
+		}
+		// previously returned slices still point to the old block, which is
+		// freed by the garbage collector after Release
+		a.values = make([]interface{}, size)
+		a.valuesUsed = 0
+	}
+	s := a.values[a.valuesUsed : a.valuesUsed+n : a.valuesUsed+n]
+	copy(s, vs)
+	a.valuesUsed += n
+	return s
+}
+
+// key returns an interned object key, so that keys repeated across documents
+// are allocated once.
+func (a *Arena) key(b []byte) string {
+	if k, ok := a.keys[string(b)]; ok {
+		return k
+	}
+	if a.keys == nil {
+		a.keys = make(map[string]string)
+	}
+	k := string(b)
+	a.keys[k] = k
+	a.keyBytes += len(k) + mapEntrySize
+	return k
+}
+
+// arenaDecoder decodes valid JSON documents to the same values as
+// json.Decoder.UseNumber, allocating objects and arrays in the arena.
+type arenaDecoder struct {
+	arena *Arena
+	data  []byte
+	pos   int
+}
+
+func (d *arenaDecoder) skipSpace() {
+	for d.pos < len(d.data) && isSpace(d.data[d.pos]) {
+		d.pos++
+	}
+}
+
+func (d *arenaDecoder) value() interface{} {
+	d.skipSpace()
+	switch d.data[d.pos] {
+	case '{':
+		return d.object()
+	case '[':
+		return d.array()
+	case '"':
+		return d.string(false)
+	case 't':
+		d.pos += len("true")
+		return true
+	case 'f':
+		d.pos += len("false")
+		return false
+	case 'n':
+		d.pos += len("null")
+		return nil
+	}
+	start := d.pos
+	for d.pos < len(d.data) && isNumberByte(d.data[d.pos]) {
+		d.pos++
+	}
+	return json.Number(d.data[start:d.pos])
+}
+
+// string decodes a string literal, keys without escape sequences are
+// interned.
+func (d *arenaDecoder) string(key bool) string {
+	start := d.pos
+	escaped := false
+	d.pos++
+	for d.data[d.pos] != '"' {
+		if d.data[d.pos] == '\\' {
+			escaped = true
+			d.pos++
+		}
+		d.pos++
+	}
+	d.pos++
+	if escaped {
+		var s string
+		json.Unmarshal(d.data[start:d.pos], &s)
+		return s
+	}
+	lit := d.data[start+1 : d.pos-1]
+	if key {
+		return d.arena.key(lit)
+	}
+	return string(lit)
+}
+
+func (d *arenaDecoder) object() interface{} {
+	m := d.arena.newMap()
+	d.pos++
+	for {
+		d.skipSpace()
+		switch d.data[d.pos] {
+		case '}':
+			d.pos++
+			return m
+		case ',':
+			d.pos++
+			d.skipSpace()
+		}
+		k := d.string(true)
+		d.skipSpace()
+		// colon
+		d.pos++
+		m[k] = d.value()
+	}
+}
+
+func (d *arenaDecoder) array() interface{} {
+	a := d.arena
+	base := len(a.scratch)
+	d.pos++
+	for {
+		d.skipSpace()
+		switch d.data[d.pos] {
+		case ']':
+			d.pos++
+			s := a.newValues(a.scratch[base:])
+			for i := range a.scratch[base:] {
+				a.scratch[base+i] = nil
+			}
+			a.scratch = a.scratch[:base]
+			return s
+		case ',':
+			d.pos++
+		default:
+			a.scratch = append(a.scratch, d.value())
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package jsondiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArena(t *testing.T) {
+	opts := DefaultConsoleOptions()
+	arena := NewArena()
+	for _, c := range compareCases {
+		diff, s := arena.Compare([]byte(c.a), []byte(c.b), &opts)
+		expectedDiff, expected := Compare([]byte(c.a), []byte(c.b), &opts)
+		if diff != expectedDiff || s != expected {
+			t.Errorf("%s %s: got %s:\n%s\nexpected %s:\n%s", c.a, c.b, diff, s, expectedDiff, expected)
+		}
+		arena.Release()
+	}
+
+	// more values than a single block holds
+	a := []byte("[" + strings.Repeat("1,", 1000) + "1]")
+	b := []byte("[" + strings.Repeat("1,", 1000) + "2]")
+	_, first := arena.Compare(a, b, &opts)
+	arena.Release()
+	if _, s := arena.Compare(a, b, &opts); s != first {
+		t.Error("result differs after release")
+	}
+	arena.Release()
+
+	plain := testing.AllocsPerRun(10, func() { Compare(a, b, &opts) })
+	pooled := testing.AllocsPerRun(10, func() {
+		arena.Compare(a, b, &opts)
+		arena.Release()
+	})
+	if pooled >= plain {
+		t.Errorf("got %v allocations with arena, %v without", pooled, plain)
+	}
+}
+
+func TestArenaDecode(t *testing.T) {
+	opts := Options{VerboseErrors: true}
+	arena := NewArena()
+	docs := []string{
+		`{"a": [1, -2.5e3, [], {}], "b": {"c": [true, false, null]}, "d": "x\"y\u00e9\n", "é": ""}`,
+		`{"a": 1, "a": 2}`,
+		"\xef\xbb\xbf{\"bom\": 1}",
+		`{"trailing": 1} 2`,
+		`{"invalid": }`,
+		"[\"\xff\"]",
+		`  []  `,
+	}
+	for _, a := range docs {
+		for _, b := range docs {
+			diff, s := arena.Compare([]byte(a), []byte(b), &opts)
+			arena.Release()
+			expectedDiff, expected := Compare([]byte(a), []byte(b), &opts)
+			if diff != expectedDiff || s != expected {
+				t.Errorf("%q %q: got %s:\n%s\nexpected %s:\n%s", a, b, diff, s, expectedDiff, expected)
+			}
+		}
+	}
+}
+
+func TestArenaBudget(t *testing.T) {
+	opts := DefaultJSONOptions()
+	a := []byte("[" + strings.Repeat(`{"a": 1},`, 5000) + "1]")
+	b := []byte("[" + strings.Repeat(`{"a": 2},`, 5000) + "1]")
+	arena := &Arena{Budget: 64 << 10}
+	_, first := arena.Compare(a, b, &opts)
+	arena.Release()
+	if size := arena.size(); size > arena.Budget {
+		t.Errorf("got %d bytes after release, expected at most %d", size, arena.Budget)
+	}
+	if _, s := arena.Compare(a, b, &opts); s != first {
+		t.Error("result differs after release")
+	}
+	arena.Release()
+
+	unlimited := NewArena()
+	unlimited.Compare(a, b, &opts)
+	unlimited.Release()
+	if unlimited.size() <= arena.Budget {
+		t.Errorf("got %d bytes without budget, expected more than %d", unlimited.size(), arena.Budget)
+	}
+}
+
+func BenchmarkArena(b *testing.B) {
+	opts := DefaultJSONOptions()
+	x := []byte(`{"users": [` + strings.Repeat(`{"id": 1, "name": "a", "tags": ["x", "y"], "active": true},`, 100) + `{}]}`)
+	y := []byte(`{"users": [` + strings.Repeat(`{"id": 1, "name": "b", "tags": ["x", "y"], "active": true},`, 100) + `{}]}`)
+	b.Run("plain", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Compare(x, y, &opts)
+		}
+	})
+	b.Run("arena", func(b *testing.B) {
+		b.ReportAllocs()
+		arena := NewArena()
+		for i := 0; i < b.N; i++ {
+			arena.Compare(x, y, &opts)
+			arena.Release()
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package jsondiff
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// AuditSchemaVersion is the version of the AuditRecord schema. It's
+// incremented whenever the schema changes incompatibly.
+const AuditSchemaVersion = 1
+
+// AuditRecord is a canonical representation of a change suitable for writing to
+// append-only audit logs. Its JSON encoding is stable within a schema version:
+//
+//	{"version": 1, "op": "modified", "pointer": "/a/0", "before": 1, "after": 2, "timestamp": "2006-01-02T15:04:05Z"}
+//
+// Op is one of "added", "removed", "modified" and "unchanged". Before and after
+// are omitted when the value is missing in the corresponding document,
+// timestamp is omitted when it's not set.
+type AuditRecord struct {
+	Version   int             `json:"version"`
+	Op        string          `json:"op"`
+	Pointer   string          `json:"pointer"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	Timestamp *time.Time      `json:"timestamp,omitempty"`
+}
+
+// AuditRecords converts changes to audit records with the given timestamp, a
+// zero timestamp is omitted, see CompareWithChanges.
+func AuditRecords(changes []Change, timestamp time.Time) []AuditRecord {
+	var ts *time.Time
+	if !timestamp.IsZero() {
+		ts = &timestamp
+	}
+	records := make([]AuditRecord, len(changes))
+	for i := range changes {
+		c := &changes[i]
+		r := AuditRecord{
+			Version:   AuditSchemaVersion,
+			Op:        c.Kind.name(),
+			Pointer:   c.Path,
+			Timestamp: ts,
+		}
+		before, after := exportValues(c)
+		if before != "" {
+			r.Before = json.RawMessage(before)
+		}
+		if after != "" {
+			r.After = json.RawMessage(after)
+		}
+		records[i] = r
+	}
+	return records
+}
+
+// WriteAuditLog writes changes as audit records in the JSON Lines format, one
+// record per line, see AuditRecords.
+func WriteAuditLog(w io.Writer, changes []Change, timestamp time.Time) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	for _, r := range AuditRecords(changes, timestamp) {
+		if err := enc.Encode(&r); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
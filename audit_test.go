@@ -0,0 +1,32 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWriteAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := WriteAuditLog(&buf, exportChanges, ts); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"version":1,"op":"modified","pointer":"/a","before":1,"after":"x,y","timestamp":"2020-01-02T03:04:05Z"}
+{"version":1,"op":"added","pointer":"/b","after":null,"timestamp":"2020-01-02T03:04:05Z"}
+{"version":1,"op":"removed","pointer":"/c","before":{"d":true},"timestamp":"2020-01-02T03:04:05Z"}
+`
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	records := AuditRecords(exportChanges[:1], time.Time{})
+	data, err := json.Marshal(records[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"version":1,"op":"modified","pointer":"/a","before":1,"after":"x,y"}` {
+		t.Errorf("got %s without timestamp", data)
+	}
+}
@@ -0,0 +1,96 @@
+package jsondiff
+
+import (
+	gocontext "context"
+	"runtime"
+	"sync"
+)
+
+// Pair is a pair of JSON documents compared by CompareBatch.
+type Pair struct {
+	A []byte
+	B []byte
+}
+
+// Result is the result of comparing a Pair, see CompareBatch.
+type Result struct {
+	Difference Difference
+	Diff       string
+	// Error of the context when the pair wasn't compared because the batch
+	// was canceled, the Difference is UnknownDifference then.
+	Err error
+}
+
+// BatchStats aggregates verdicts of a batch, see CompareBatch.
+type BatchStats struct {
+	// Number of pairs with each verdict.
+	FullMatch     int
+	SupersetMatch int
+	NoMatch       int
+	// Number of pairs where any of the documents is invalid JSON.
+	Invalid int
+	// Number of pairs which weren't compared because the batch was canceled.
+	Canceled int
+}
+
+func (s *BatchStats) add(r *Result) {
+	switch {
+	case r.Err != nil:
+		s.Canceled++
+	case r.Difference == FullMatch:
+		s.FullMatch++
+	case r.Difference == SupersetMatch:
+		s.SupersetMatch++
+	case r.Difference == NoMatch:
+		s.NoMatch++
+	default:
+		s.Invalid++
+	}
+}
+
+// CompareBatch compares pairs of documents like Compare using a pool of
+// concurrent workers, GOMAXPROCS of them if concurrency is not positive.
+// Results are returned in the order of pairs along with the aggregated
+// statistics. When the context is canceled, pairs which weren't compared yet
+// get results with the context error, comparisons in progress are finished.
+//
+// Options are shared by the workers, so functions in them must be safe for
+// concurrent use.
+func CompareBatch(ctx gocontext.Context, pairs []Pair, opts *Options, concurrency int) ([]Result, BatchStats) {
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	if concurrency > len(pairs) {
+		concurrency = len(pairs)
+	}
+	results := make([]Result, len(pairs))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			arena := NewArena()
+			for i := range indices {
+				if err := ctx.Err(); err != nil {
+					results[i] = Result{Difference: UnknownDifference, Err: err}
+					continue
+				}
+				diff, s := arena.Compare(pairs[i].A, pairs[i].B, opts)
+				arena.Release()
+				results[i] = Result{Difference: diff, Diff: s}
+			}
+		}()
+	}
+	for i := range pairs {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	var stats BatchStats
+	for i := range results {
+		stats.add(&results[i])
+	}
+	return results, stats
+}
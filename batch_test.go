@@ -0,0 +1,35 @@
+package jsondiff
+
+import (
+	gocontext "context"
+	"testing"
+)
+
+func TestCompareBatch(t *testing.T) {
+	opts := DefaultConsoleOptions()
+	var pairs []Pair
+	for i := 0; i < 10; i++ {
+		for _, c := range compareCases {
+			pairs = append(pairs, Pair{[]byte(c.a), []byte(c.b)})
+		}
+	}
+	pairs = append(pairs, Pair{[]byte(`{`), []byte(`{}`)})
+
+	results, stats := CompareBatch(gocontext.Background(), pairs, &opts, 4)
+	for i, p := range pairs {
+		diff, s := Compare(p.A, p.B, &opts)
+		if results[i].Difference != diff || results[i].Diff != s || results[i].Err != nil {
+			t.Errorf("pair %d: got %+v, expected %s:\n%s", i, results[i], diff, s)
+		}
+	}
+	if stats.FullMatch != 40 || stats.SupersetMatch != 20 || stats.NoMatch != 80 || stats.Invalid != 1 || stats.Canceled != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	cancel()
+	results, stats = CompareBatch(ctx, pairs, &opts, 0)
+	if stats.Canceled != len(pairs) || results[0].Err != gocontext.Canceled || results[0].Difference != UnknownDifference {
+		t.Errorf("unexpected result of canceled batch: %+v %+v", results[0], stats)
+	}
+}
@@ -0,0 +1,462 @@
+package jsondiff
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// maxBinaryDepth limits nesting of collections in binary and lenient JSON
+// documents, like encoding/json limits it for JSON.
+const maxBinaryDepth = 10000
+
+// binaryDecoder decodes CBOR and MessagePack documents to the values JSON
+// documents are decoded to, see Options.InputFormat.
+type binaryDecoder struct {
+	r      *bufio.Reader
+	offset int64
+	depth  int
+}
+
+var errBinaryDepth = errors.New("exceeded max depth")
+
+func (d *binaryDecoder) readByte() (byte, error) {
+	c, err := d.r.ReadByte()
+	if err == nil {
+		d.offset++
+	}
+	return c, err
+}
+
+// read reads n bytes, the buffer grows as the data arrives, so that bogus
+// lengths don't cause huge allocations.
+func (d *binaryDecoder) read(n uint64) ([]byte, error) {
+	if n > math.MaxInt32 {
+		return nil, errors.New("length " + strconv.FormatUint(n, 10) + " is too large")
+	}
+	var buf bytes.Buffer
+	m, err := io.CopyN(&buf, d.r, int64(n))
+	d.offset += m
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return buf.Bytes(), err
+}
+
+func (d *binaryDecoder) uint(n int) (uint64, error) {
+	b, err := d.read(uint64(n))
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// decodeBinary decodes the document using the value decoding function of the
+// format. When strict is true, the document must end after the value.
+func decodeBinary(r io.Reader, strict bool, value func(d *binaryDecoder) (interface{}, error)) (interface{}, error) {
+	d := &binaryDecoder{r: bufio.NewReader(r)}
+	v, err := value(d)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, &decodeError{err: err, offset: d.offset}
+	}
+	if strict {
+		if c, err := d.r.ReadByte(); err == nil {
+			return nil, &decodeError{
+				err:    errors.New("unexpected byte " + strconv.Quote(string(rune(c))) + " after top-level value"),
+				offset: d.offset,
+			}
+		}
+	}
+	return v, nil
+}
+
+// binaryFloat converts a floating point number to a decoded JSON value,
+// non-finite numbers are represented as by the lenient parser.
+func binaryFloat(f float64, bits int) json.Number {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, bits))
+}
+
+// binaryKey converts a map key to an object key: strings are used as is,
+// other values as their JSON representation, e.g. 1 becomes "1".
+func binaryKey(k interface{}) (string, error) {
+	if s, ok := keyString(k); ok {
+		return s, nil
+	}
+	return "", errors.New("unsupported map key")
+}
+
+func bytesValue(b []byte) string {
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// cborValue decodes a CBOR (RFC 8949) data item.
+func cborValue(d *binaryDecoder) (interface{}, error) {
+	c, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := c>>5, c&0x1f
+	if major == 7 {
+		return d.cborSimple(info)
+	}
+	var n uint64
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info <= 27:
+		if n, err = d.uint(1 << (info - 24)); err != nil {
+			return nil, err
+		}
+	case info == 31 && major >= 2 && major <= 5:
+		return d.cborIndefinite(major)
+	default:
+		return nil, errors.New("invalid additional information " + strconv.Itoa(int(info)))
+	}
+
+	switch major {
+	case 0:
+		return json.Number(strconv.FormatUint(n, 10)), nil
+	case 1:
+		v := new(big.Int).SetUint64(n)
+		return json.Number(v.Neg(v.Add(v, big.NewInt(1))).String()), nil
+	case 2:
+		b, err := d.read(n)
+		return bytesValue(b), err
+	case 3:
+		b, err := d.read(n)
+		return string(b), err
+	case 4:
+		return d.array(n, cborValue)
+	case 5:
+		return d.object(n, cborValue)
+	}
+	// tags are ignored, except for bignums
+	if n == 2 || n == 3 {
+		if c, err := d.r.Peek(1); err == nil && c[0]>>5 != 2 {
+			return nil, errors.New("bignum content is not a byte string")
+		}
+	}
+	v, err := d.nested(cborValue)
+	if err != nil || n != 2 && n != 3 {
+		return v, err
+	}
+	b, _ := base64.StdEncoding.DecodeString(v.(string))
+	bn := new(big.Int).SetBytes(b)
+	if n == 3 {
+		bn.Neg(bn.Add(bn, big.NewInt(1)))
+	}
+	return json.Number(bn.String()), nil
+}
+
+func (d *binaryDecoder) cborSimple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		// null and undefined
+		return nil, nil
+	case 25:
+		v, err := d.uint(2)
+		return binaryFloat(halfFloat(uint16(v)), 32), err
+	case 26:
+		v, err := d.uint(4)
+		return binaryFloat(float64(math.Float32frombits(uint32(v))), 32), err
+	case 27:
+		v, err := d.uint(8)
+		return binaryFloat(math.Float64frombits(v), 64), err
+	}
+	return nil, errors.New("unsupported simple value " + strconv.Itoa(int(info)))
+}
+
+// halfFloat converts an IEEE 754 half-precision number.
+func halfFloat(h uint16) float64 {
+	exp, mant := int(h>>10)&0x1f, float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// cborIndefinite decodes an indefinite-length string, array or map, whose
+// items are followed by the break code.
+func (d *binaryDecoder) cborIndefinite(major byte) (interface{}, error) {
+	// isBreak consumes the break code if it's next
+	isBreak := func() (bool, error) {
+		c, err := d.r.Peek(1)
+		if err != nil {
+			return false, err
+		}
+		if c[0] != 0xff {
+			return false, nil
+		}
+		d.readByte()
+		return true, nil
+	}
+	if major == 2 || major == 3 {
+		var buf []byte
+		for {
+			end, err := isBreak()
+			if err != nil {
+				return nil, err
+			}
+			if end {
+				break
+			}
+			// chunks must be definite-length strings of the same type
+			if c, _ := d.r.Peek(1); c[0]>>5 != major || c[0]&0x1f == 31 {
+				return nil, errors.New("invalid chunk of indefinite-length string")
+			}
+			v, err := cborValue(d)
+			if err != nil {
+				return nil, err
+			}
+			s := v.(string)
+			if major == 2 {
+				b, _ := base64.StdEncoding.DecodeString(s)
+				s = string(b)
+			}
+			buf = append(buf, s...)
+		}
+		if major == 2 {
+			return bytesValue(buf), nil
+		}
+		return string(buf), nil
+	}
+
+	if d.depth++; d.depth > maxBinaryDepth {
+		return nil, errBinaryDepth
+	}
+	defer func() { d.depth-- }()
+	arr := []interface{}{}
+	obj := map[string]interface{}{}
+	for {
+		end, err := isBreak()
+		if err != nil {
+			return nil, err
+		}
+		if end {
+			break
+		}
+		v, err := cborValue(d)
+		if err != nil {
+			return nil, err
+		}
+		if major == 4 {
+			arr = append(arr, v)
+			continue
+		}
+		k, err := binaryKey(v)
+		if err != nil {
+			return nil, err
+		}
+		if obj[k], err = cborValue(d); err != nil {
+			return nil, err
+		}
+	}
+	if major == 4 {
+		return arr, nil
+	}
+	return obj, nil
+}
+
+// capacity returns the initial capacity of a collection of n items, which is
+// limited, so that bogus lengths don't cause huge allocations.
+func capacity(n uint64) int {
+	if n > 1024 {
+		return 1024
+	}
+	return int(n)
+}
+
+// nested decodes a value nested in a collection or a tag.
+func (d *binaryDecoder) nested(value func(d *binaryDecoder) (interface{}, error)) (interface{}, error) {
+	if d.depth++; d.depth > maxBinaryDepth {
+		return nil, errBinaryDepth
+	}
+	v, err := value(d)
+	d.depth--
+	return v, err
+}
+
+func (d *binaryDecoder) array(n uint64, value func(d *binaryDecoder) (interface{}, error)) (interface{}, error) {
+	arr := make([]interface{}, 0, capacity(n))
+	for i := uint64(0); i < n; i++ {
+		v, err := d.nested(value)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+// object decodes a map, the last of duplicate keys wins like in JSON.
+func (d *binaryDecoder) object(n uint64, value func(d *binaryDecoder) (interface{}, error)) (interface{}, error) {
+	obj := make(map[string]interface{}, capacity(n))
+	for i := uint64(0); i < n; i++ {
+		kv, err := d.nested(value)
+		if err != nil {
+			return nil, err
+		}
+		k, err := binaryKey(kv)
+		if err != nil {
+			return nil, err
+		}
+		if obj[k], err = d.nested(value); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// msgpackValue decodes a MessagePack value.
+func msgpackValue(d *binaryDecoder) (interface{}, error) {
+	c, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return json.Number(strconv.Itoa(int(c))), nil
+	case c >= 0xe0:
+		return json.Number(strconv.Itoa(int(int8(c)))), nil
+	case c&0xf0 == 0x80:
+		return d.object(uint64(c&0x0f), msgpackValue)
+	case c&0xf0 == 0x90:
+		return d.array(uint64(c&0x0f), msgpackValue)
+	case c&0xe0 == 0xa0:
+		b, err := d.read(uint64(c & 0x1f))
+		return string(b), err
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		return bytesValue(b), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		return string(b), err
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.msgpackExt(n)
+	case 0xca:
+		v, err := d.uint(4)
+		return binaryFloat(float64(math.Float32frombits(uint32(v))), 32), err
+	case 0xcb:
+		v, err := d.uint(8)
+		return binaryFloat(math.Float64frombits(v), 64), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.uint(1 << (c - 0xcc))
+		return json.Number(strconv.FormatUint(v, 10)), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		v, err := d.uint(size)
+		// sign extend
+		shift := 64 - 8*uint(size)
+		return json.Number(strconv.FormatInt(int64(v<<shift)>>shift, 10)), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.msgpackExt(1 << (c - 0xd4))
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n, msgpackValue)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.object(n, msgpackValue)
+	}
+	return nil, errors.New("invalid type byte " + strconv.Itoa(int(c)))
+}
+
+// msgpackExt decodes an extension value of n bytes. Timestamps become RFC
+// 3339 strings, other extensions become {"type": type, "data": base64}.
+func (d *binaryDecoder) msgpackExt(n uint64) (interface{}, error) {
+	typ, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ) == -1 {
+		var sec int64
+		var nsec uint32
+		switch len(data) {
+		case 4:
+			sec = int64(binary.BigEndian.Uint32(data))
+		case 8:
+			v := binary.BigEndian.Uint64(data)
+			sec, nsec = int64(v&(1<<34-1)), uint32(v>>34)
+		case 12:
+			nsec = binary.BigEndian.Uint32(data)
+			sec = int64(binary.BigEndian.Uint64(data[4:]))
+		default:
+			return nil, errors.New("invalid timestamp length " + strconv.Itoa(len(data)))
+		}
+		return time.Unix(sec, int64(nsec)).UTC().Format(time.RFC3339Nano), nil
+	}
+	return map[string]interface{}{
+		"type": json.Number(strconv.Itoa(int(int8(typ)))),
+		"data": bytesValue(data),
+	}, nil
+}
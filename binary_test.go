@@ -0,0 +1,87 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeBinary(t *testing.T) {
+	cases := []struct {
+		format   InputFormat
+		hex      string
+		expected string
+	}{
+		{CBORInput, "a4616183012142010201f56166f93e00616ef6", `{"a": [1, -2, "AQI="], "1": true, "f": 1.5, "n": null}`},
+		{CBORInput, "7f62616261636162ff", `"abcb"`},
+		{CBORInput, "9f01bf6178f4ffff", `[1, {"x": false}]`},
+		{CBORInput, "c2420100", `256`},
+		{CBORInput, "c34100", `-1`},
+		{CBORInput, "1bffffffffffffffff", `18446744073709551615`},
+		{CBORInput, "3bffffffffffffffff", `-18446744073709551616`},
+		{CBORInput, "c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`},
+		{CBORInput, "fa7f800000", `Infinity`},
+		{MessagePackInput, "84a16193" + "01fe" + "c4020102" + "01c3" + "a166cb3ff8000000000000" + "a16ec0", `{"a": [1, -2, "AQI="], "1": true, "f": 1.5, "n": null}`},
+		{MessagePackInput, "d080", `-128`},
+		{MessagePackInput, "d3ffffffffffffffff", `-1`},
+		{MessagePackInput, "cfffffffffffffffff", `18446744073709551615`},
+		{MessagePackInput, "ca3fc00000", `1.5`},
+		{MessagePackInput, "d90178", `"x"`},
+		{MessagePackInput, "d6ff00000000", `"1970-01-01T00:00:00Z"`},
+		{MessagePackInput, "d405aa", `{"type": 5, "data": "qg=="}`},
+		{MessagePackInput, "cb7ff8000000000000", `NaN`},
+	}
+	for i, c := range cases {
+		data, _ := hex.DecodeString(c.hex)
+		ctx := context{opts: &Options{InputFormat: c.format}}
+		got, err := ctx.decode(bytes.NewReader(data))
+		expected, _ := decodeLenient(strings.NewReader(c.expected), false)
+		if err != nil || !reflect.DeepEqual(got, expected) {
+			t.Errorf("case %d failed, got: %#v, %v, expected: %#v", i, got, err, expected)
+		}
+	}
+
+	errCases := []struct {
+		format InputFormat
+		hex    string
+	}{
+		{CBORInput, "6261"},
+		{CBORInput, "7f01ff"},
+		{CBORInput, "c26161"},
+		{CBORInput, "0102"},
+		{MessagePackInput, "c1"},
+		{MessagePackInput, "92c0"},
+		{MessagePackInput, strings.Repeat("91", maxBinaryDepth+1) + "c0"},
+	}
+	for i, c := range errCases {
+		data, _ := hex.DecodeString(c.hex)
+		ctx := context{opts: &Options{InputFormat: c.format, Strict: true}}
+		if v, err := ctx.decode(bytes.NewReader(data)); err == nil {
+			t.Errorf("error case %d failed, got: %#v, expected an error", i, v)
+		}
+	}
+}
+
+func TestInputFormat(t *testing.T) {
+	a, _ := hex.DecodeString("a2616101616202")
+	b, _ := hex.DecodeString("a2616101616203")
+	opts := Options{Changed: Tag{Begin: "~"}, ChangedSeparator: " => ", InputFormat: CBORInput}
+	diff, s := Compare(a, b, &opts)
+	if diff != NoMatch || s != "{\n\"a\": 1,\n\"b\": ~2 => 3\n}" {
+		t.Errorf("got %s, %q, expected NoMatch", diff, s)
+	}
+	if diff := Verdict(a, a, &opts); diff != FullMatch {
+		t.Errorf("got %s, expected FullMatch", diff)
+	}
+	errA, errB := InputErrors(a[:3], b, &opts)
+	if errA == nil || errB != nil || errA.Offset != 3 || errA.Line != 0 {
+		t.Errorf("got %v, %v, expected an error at offset 3 of the first document", errA, errB)
+	}
+
+	opts, err := LoadOptions(strings.NewReader(`{"inputFormat": "msgpack"}`))
+	if err != nil || opts.InputFormat != MessagePackInput {
+		t.Errorf("got %v, %v, expected msgpack input", opts.InputFormat, err)
+	}
+}
@@ -0,0 +1,138 @@
+package jsondiff
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind is a kind of the change, see Change.
+type ChangeKind int
+
+const (
+	// Value is present only in the second document.
+	ChangeAdded ChangeKind = iota
+	// Value is present only in the first document.
+	ChangeRemoved
+	// Value is present in both documents, but differs.
+	ChangeModified
+	// Value is present in both documents and matches, see
+	// Options.IncludeUnchanged.
+	ChangeUnchanged
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "ChangeAdded"
+	case ChangeRemoved:
+		return "ChangeRemoved"
+	case ChangeModified:
+		return "ChangeModified"
+	case ChangeUnchanged:
+		return "ChangeUnchanged"
+	}
+	return "Invalid"
+}
+
+// name returns a lowercase name of the kind used by the export formats.
+func (k ChangeKind) name() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	case ChangeUnchanged:
+		return "unchanged"
+	}
+	return "invalid"
+}
+
+// Change describes a single difference between two documents. Path is a JSON
+// Pointer (RFC 6901) to the value, e.g. "/items/0/name", root value has an
+// empty path. Old and New are values in the first and the second documents,
+// nil if the value is missing in the corresponding document. Values are of the
+// types produced by decoding JSON with json.Decoder.UseNumber.
+type Change struct {
+	Path string
+	Kind ChangeKind
+	Old  interface{}
+	New  interface{}
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func pointerChild(path, elem string) string {
+	return path + "/" + pointerEscaper.Replace(elem)
+}
+
+func (ctx *context) changes(d *delta, path string, out []Change) []Change {
+	if orig, ok := ctx.originals[d]; ok {
+		switch {
+		case d.differs:
+			out = append(out, Change{Path: path, Kind: ChangeModified, Old: orig.a, New: orig.b})
+		case ctx.opts.IncludeUnchanged && d.kind != deltaSkipped:
+			out = append(out, Change{Path: path, Kind: ChangeUnchanged, Old: orig.a, New: orig.b})
+		}
+		return out
+	}
+	if ctx.opts.IncludeUnchanged && !d.differs && d.kind != deltaSkipped {
+		return append(out, Change{Path: path, Kind: ChangeUnchanged, Old: d.a, New: d.b})
+	}
+	switch d.kind {
+	case deltaChanged:
+		out = append(out, Change{Path: path, Kind: ChangeModified, Old: d.a, New: d.b})
+	case deltaAdded:
+		out = append(out, Change{Path: path, Kind: ChangeAdded, New: d.b})
+	case deltaRemoved:
+		out = append(out, Change{Path: path, Kind: ChangeRemoved, Old: d.a})
+	case deltaCollection:
+		for i, e := range d.elems {
+			if !e.differs && !ctx.opts.IncludeUnchanged {
+				continue
+			}
+			if d.isObject() {
+				out = ctx.changes(e, pointerChild(path, d.keys[i]), out)
+			} else {
+				out = ctx.changes(e, pointerChild(path, strconv.Itoa(i)), out)
+			}
+		}
+	}
+	return out
+}
+
+// CompareWithChanges works like Compare, but also returns a list of changes
+// between the documents in the order of the text output. Changes are
+// collected regardless of the rendering options, e.g. SkipMatches. Skipped
+// values don't produce changes, optional keys do. Collections compared in a
+// different shape, e.g. Options.KeyedArrays, are reported as a single
+// modified value, so that paths of changes exist in the documents.
+func CompareWithChanges(a, b []byte, opts *Options) (Difference, string, []Change) {
+	ctx := context{opts: opts}
+	d, diff, msg := ctx.decodeAndCompare(bytes.NewReader(a), bytes.NewReader(b))
+	if d == nil {
+		return diff, msg, nil
+	}
+	return ctx.diff, ctx.render(d), ctx.changes(d, ctx.root, nil)
+}
+
+// Invert returns changes which undo the given ones: added values become
+// removed and vice versa, old and new values are swapped. Changes are returned
+// in reverse order, so that changes of array elements past the end of the
+// shorter array go from the last element to the first one.
+func Invert(changes []Change) []Change {
+	inverted := make([]Change, len(changes))
+	for i, c := range changes {
+		switch c.Kind {
+		case ChangeAdded:
+			c.Kind = ChangeRemoved
+		case ChangeRemoved:
+			c.Kind = ChangeAdded
+		}
+		c.Old, c.New = c.New, c.Old
+		inverted[len(changes)-1-i] = c
+	}
+	return inverted
+}
@@ -0,0 +1,78 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCompareWithChanges(t *testing.T) {
+	opts := DefaultJSONOptions()
+	result, _, changes := CompareWithChanges(
+		[]byte(`{"a/b": [1, 2, 3], "c": "x", "d": true, "e~": {}}`),
+		[]byte(`{"a/b": [1, 5], "c": "x", "e~": {"f": null}}`),
+		&opts,
+	)
+	if result != NoMatch {
+		t.Errorf("got %s, expected NoMatch", result)
+	}
+	expected := []Change{
+		{Path: "/a~1b/1", Kind: ChangeModified, Old: json.Number("2"), New: json.Number("5")},
+		{Path: "/a~1b/2", Kind: ChangeRemoved, Old: json.Number("3")},
+		{Path: "/d", Kind: ChangeRemoved, Old: true},
+		{Path: "/e~0/f", Kind: ChangeAdded},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("got %+v, expected %+v", changes, expected)
+	}
+
+	result, _, changes = CompareWithChanges([]byte(`1`), []byte(`2`), &opts)
+	if result != NoMatch || !reflect.DeepEqual(changes, []Change{
+		{Kind: ChangeModified, Old: json.Number("1"), New: json.Number("2")},
+	}) {
+		t.Errorf("got %s %+v for root change", result, changes)
+	}
+
+	result, _, changes = CompareWithChanges([]byte(`{`), []byte(`{}`), &opts)
+	if result != FirstArgIsInvalidJson || changes != nil {
+		t.Errorf("got %s %+v for invalid json", result, changes)
+	}
+}
+
+func TestCompareWithChangesIncludeUnchanged(t *testing.T) {
+	opts := DefaultJSONOptions()
+	opts.IncludeUnchanged = true
+	opts.Ignore = []string{"t"}
+	_, _, changes := CompareWithChanges(
+		[]byte(`{"a": [1, 2], "b": {"c": 1}, "t": 1}`),
+		[]byte(`{"a": [1, 3], "b": {"c": 1}, "t": 2}`),
+		&opts,
+	)
+	b := map[string]interface{}{"c": json.Number("1")}
+	expected := []Change{
+		{Path: "/a/0", Kind: ChangeUnchanged, Old: json.Number("1"), New: json.Number("1")},
+		{Path: "/a/1", Kind: ChangeModified, Old: json.Number("2"), New: json.Number("3")},
+		{Path: "/b", Kind: ChangeUnchanged, Old: b, New: b},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("got %+v, expected %+v", changes, expected)
+	}
+}
+
+func TestInvert(t *testing.T) {
+	opts := DefaultJSONOptions()
+	a := []byte(`{"a": [1, 2, 3], "b": 1}`)
+	b := []byte(`{"a": [1, 5], "c": 2}`)
+	_, _, changes := CompareWithChanges(a, b, &opts)
+	_, _, expected := CompareWithChanges(b, a, &opts)
+	inverted := Invert(changes)
+	// changes of the inverse comparison go in the document order
+	sort.Slice(inverted, func(i, j int) bool { return inverted[i].Path < inverted[j].Path })
+	if !reflect.DeepEqual(inverted, expected) {
+		t.Errorf("got %+v, expected %+v", inverted, expected)
+	}
+	if !reflect.DeepEqual(Invert(Invert(changes)), changes) {
+		t.Errorf("double inversion doesn't restore changes: %+v", Invert(Invert(changes)))
+	}
+}
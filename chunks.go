@@ -0,0 +1,107 @@
+package jsondiff
+
+import (
+	"bytes"
+)
+
+// Checkpoint records progress of CompareChunks, so that an interrupted
+// comparison can be resumed. Its fields can be stored and restored as is.
+type Checkpoint struct {
+	// Number of chunks compared so far.
+	Chunks int
+	// Verdict of the chunks compared so far.
+	Difference Difference
+}
+
+// Chunk is a partial result of CompareChunks.
+type Chunk struct {
+	// Top-level key compared in the chunk, empty if the documents are
+	// compared as a whole.
+	Key string
+	// Verdict of the chunk alone.
+	Difference Difference
+	// Difference of the chunk rendered according to the options as if the
+	// documents were objects with only the key, so that paths are relative
+	// to the root of the documents.
+	Diff string
+	// Checkpoint to resume the comparison after the chunk.
+	Checkpoint Checkpoint
+}
+
+// CompareChunks compares two JSON documents one top-level key at a time and
+// calls emit with the result of each key as soon as it's compared, so that
+// differences of giant documents can be shown progressively. Keys are
+// compared in the output order. Documents which aren't objects (after
+// selecting their roots and applying transformations), and objects compared
+// by a Comparator or by EmptyObject mode, are compared in a single chunk.
+//
+// Comparison starts after the given checkpoint, the zero Checkpoint starts it
+// from the beginning. The checkpoint has to come from a comparison of the
+// same documents with the same options. When emit returns an error,
+// comparison stops and the error is returned along with the verdict so far,
+// the last emitted checkpoint resumes it. Returns the verdict of the whole
+// comparison, if any of the documents is invalid no chunks are emitted.
+func CompareChunks(a, b []byte, opts *Options, from Checkpoint, emit func(Chunk) error) (Difference, error) {
+	ctx := context{opts: opts}
+	av, errA := ctx.decode(bytes.NewReader(a))
+	bv, errB := ctx.decode(bytes.NewReader(b))
+	switch {
+	case errA != nil && errB != nil:
+		return BothArgsAreInvalidJson, nil
+	case errA != nil:
+		return FirstArgIsInvalidJson, nil
+	case errB != nil:
+		return SecondArgIsInvalidJson, nil
+	}
+
+	av = ctx.transform(selectRoot(av, opts.FirstRoot))
+	bv = ctx.transform(selectRoot(bv, opts.SecondRoot))
+	if opts.NumericKeysAsArrays {
+		av, bv = numericKeysToArray(av), numericKeysToArray(bv)
+	}
+	ctx.diff = from.Difference
+	ma, okA := av.(map[string]interface{})
+	mb, okB := bv.(map[string]interface{})
+	if okA && okB {
+		ma = aliasKeys(ma, opts.KeyAliases)
+		if opts.NormalizeKeys != nil {
+			ma, mb = normalizeKeys(ma, mb, opts.NormalizeKeys)
+		}
+	}
+	if !okA || !okB || ctx.comparator(av, bv) != nil ||
+		len(ma) != 0 && len(mb) == 0 && opts.EmptyObject != EmptyCollectionDefault {
+		if from.Chunks > 0 {
+			return ctx.diff, nil
+		}
+		d := ctx.compare(av, bv)
+		ctx.trace(d)
+		c := Chunk{Difference: ctx.diff, Diff: ctx.render(d)}
+		c.Checkpoint = Checkpoint{Chunks: 1, Difference: ctx.diff}
+		return ctx.diff, emit(c)
+	}
+
+	keys := unionKeys(ma, mb)
+	ctx.sortKeys(keys)
+	for i := from.Chunks; i < len(keys); i++ {
+		k := keys[i]
+		total := ctx.diff
+		ctx.diff = FullMatch
+		e := ctx.compareProperty(ma, mb, k)
+		c := Chunk{Key: k, Difference: ctx.diff}
+		c.Diff = ctx.render(ctx.newDelta(delta{
+			kind:    deltaCollection,
+			a:       ma,
+			b:       mb,
+			keys:    []string{k},
+			elems:   []*delta{e},
+			differs: e.differs,
+		}))
+		ctx.diff = total
+		ctx.result(c.Difference)
+		c.Checkpoint = Checkpoint{Chunks: i + 1, Difference: ctx.diff}
+		if err := emit(c); err != nil {
+			return ctx.diff, err
+		}
+	}
+	return ctx.diff, nil
+}
@@ -0,0 +1,59 @@
+package jsondiff
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompareChunks(t *testing.T) {
+	a := []byte(`{"a": 1, "b": [1, 2], "c": {"x": 1}}`)
+	b := []byte(`{"a": 1, "b": [1, 3]}`)
+	opts := Options{Format: JSONPatchOutput}
+	expected := []Chunk{
+		{Key: "a", Difference: FullMatch, Diff: `[]`, Checkpoint: Checkpoint{1, FullMatch}},
+		{Key: "b", Difference: NoMatch, Diff: `[{"op":"replace","path":"/b/1","value":3}]`, Checkpoint: Checkpoint{2, NoMatch}},
+		{Key: "c", Difference: SupersetMatch, Diff: `[{"op":"remove","path":"/c"}]`, Checkpoint: Checkpoint{3, NoMatch}},
+	}
+
+	var chunks []Chunk
+	stop := errors.New("stop")
+	result, err := CompareChunks(a, b, &opts, Checkpoint{}, func(c Chunk) error {
+		chunks = append(chunks, c)
+		if len(chunks) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if result != NoMatch || err != stop {
+		t.Errorf("got %s, %v, expected NoMatch, stop", result, err)
+	}
+	result, err = CompareChunks(a, b, &opts, chunks[1].Checkpoint, func(c Chunk) error {
+		chunks = append(chunks, c)
+		return nil
+	})
+	if result != NoMatch || err != nil {
+		t.Errorf("got %s, %v, expected NoMatch", result, err)
+	}
+	if len(chunks) != len(expected) {
+		t.Fatalf("got %d chunks, expected %d", len(chunks), len(expected))
+	}
+	for i, c := range chunks {
+		if c != expected[i] {
+			t.Errorf("chunk %d: got %+v, expected %+v", i, c, expected[i])
+		}
+	}
+
+	// documents which aren't objects are compared at once
+	chunks = nil
+	result, _ = CompareChunks([]byte(`[1]`), []byte(`[1]`), &opts, Checkpoint{}, func(c Chunk) error {
+		chunks = append(chunks, c)
+		return nil
+	})
+	if result != FullMatch || len(chunks) != 1 || chunks[0] != (Chunk{Difference: FullMatch, Diff: `[]`, Checkpoint: Checkpoint{1, FullMatch}}) {
+		t.Errorf("got %s, %+v", result, chunks)
+	}
+
+	if result, _ := CompareChunks([]byte(`{`), b, &opts, Checkpoint{}, nil); result != FirstArgIsInvalidJson {
+		t.Errorf("got %s, expected FirstArgIsInvalidJson", result)
+	}
+}
@@ -0,0 +1,53 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+)
+
+// attributes of CloudEvents which differ between producers of the same events
+var cloudEventsVolatile = []string{"id", "time", "traceparent"}
+
+// CloudEvents returns a normalizer of events in the CloudEvents JSON format
+// (https://github.com/cloudevents/spec), e.g. events of two producers of the
+// same stream. It's applied to every object which has a "specversion"
+// string, so that it works for single events as well as arrays of them. The
+// "id", "time" and "traceparent" attributes are removed, while "data" is
+// left intact and compared as any other value.
+//
+// When decodeBase64 is true, binary data in "data_base64" is decoded and
+// moved to "data", so that events of producers using different encodings of
+// data can be compared. Decoded data which is valid JSON is compared
+// structurally, otherwise it's compared as a string.
+func CloudEvents(decodeBase64 bool) Normalizer {
+	return func(path string, v interface{}) interface{} {
+		event, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		if _, ok := event["specversion"].(string); !ok {
+			return v
+		}
+		out := make(map[string]interface{}, len(event))
+		for k, v := range event {
+			out[k] = v
+		}
+		for _, k := range cloudEventsVolatile {
+			delete(out, k)
+		}
+		if !decodeBase64 {
+			return out
+		}
+		if s, ok := event["data_base64"].(string); ok {
+			if data, err := base64.StdEncoding.DecodeString(s); err == nil {
+				delete(out, "data_base64")
+				out["data"] = string(data)
+				if json.Valid(data) {
+					out["data"], _ = decode(bytes.NewReader(data))
+				}
+			}
+		}
+		return out
+	}
+}
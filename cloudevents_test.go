@@ -0,0 +1,34 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+func TestCloudEvents(t *testing.T) {
+	a := []byte(`[
+		{"specversion": "1.0", "type": "order.created", "source": "/orders", "id": "a1",
+		 "time": "2024-01-01T00:00:00Z", "traceparent": "00-abc-01", "data": {"order": 1}},
+		{"specversion": "1.0", "type": "order.paid", "source": "/orders", "id": "a2", "data_base64": "eyJvcmRlciI6IDF9"}
+	]`)
+	b := []byte(`[
+		{"specversion": "1.0", "type": "order.created", "source": "/orders", "id": "b1",
+		 "time": "2024-01-01T00:00:05Z", "data": {"order": 1}},
+		{"specversion": "1.0", "type": "order.paid", "source": "/orders", "id": "b2", "data": {"order": 1}}
+	]`)
+	opts, _ := PresetOptions("cloudevents")
+	if result, diff := Compare(a, b, &opts); result != FullMatch {
+		t.Errorf("got %s, expected FullMatch:\n%s", result, diff)
+	}
+
+	opts.Transform = Normalize(CloudEvents(false))
+	if result, _ := Compare(a, b, &opts); result != NoMatch {
+		t.Errorf("got %s, expected NoMatch", result)
+	}
+
+	opts.Transform = Normalize(CloudEvents(true))
+	a = []byte(`{"specversion": "1.0", "data_base64": "aGVsbG8="}`)
+	b = []byte(`{"specversion": "1.0", "data": "hello"}`)
+	if result, diff := Compare(a, b, &opts); result != FullMatch {
+		t.Errorf("got %s, expected FullMatch:\n%s", result, diff)
+	}
+}
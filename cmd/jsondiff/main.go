@@ -0,0 +1,131 @@
+// Command jsondiff compares JSON documents.
+//
+// Usage:
+//
+//	jsondiff [-config file] a.json b.json
+//	jsondiff corpus [-config file] [-strict] [-json report.json] [-html report.html] dir
+//
+// The first form prints the difference of two documents, which are paths of
+// local files or URIs, e.g. s3://bucket/key or redis://host/key, see the
+// source package for the supported schemes. The corpus subcommand compares
+// every NAME.actual.json file in the directory against NAME.expected.json,
+// see jsondiff.RunCorpus, and writes the reports.
+//
+// Options are loaded from the configuration file if it's given, see
+// jsondiff.LoadOptions, and are based on jsondiff.ConsoleOptions, or
+// jsondiff.DefaultMarkerOptions for the corpus subcommand, otherwise.
+//
+// The exit status is 0 if the documents match, 1 if they don't match or any
+// of the corpus cases fails, and 2 on errors, see jsondiff.ExitCode.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nsf/jsondiff"
+	"github.com/nsf/jsondiff/source"
+)
+
+func loadOptions(path string, def jsondiff.Options) jsondiff.Options {
+	if path == "" {
+		return def
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		fatal(err)
+	}
+	defer f.Close()
+	opts, err := jsondiff.LoadOptions(f)
+	if err != nil {
+		fatal(err)
+	}
+	return opts
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "jsondiff:", err)
+	os.Exit(2)
+}
+
+func writeReport(path string, write func(f *os.File) error) {
+	if path == "" {
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fatal(err)
+	}
+	if err := write(f); err != nil {
+		fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		fatal(err)
+	}
+}
+
+func corpus(args []string) {
+	fl := flag.NewFlagSet("corpus", flag.ExitOnError)
+	config := fl.String("config", "", "options `file`")
+	strict := fl.Bool("strict", false, "fail on SupersetMatch")
+	jsonReport := fl.String("json", "", "write the JSON report to `file`")
+	htmlReport := fl.String("html", "", "write the HTML report to `file`")
+	fl.Parse(args)
+	if fl.NArg() != 1 {
+		fl.Usage()
+		os.Exit(2)
+	}
+
+	opts := loadOptions(*config, jsondiff.DefaultMarkerOptions())
+	report, err := jsondiff.RunCorpus(fl.Arg(0), &opts, *strict)
+	if err != nil {
+		fatal(err)
+	}
+	for _, c := range report.Cases {
+		if c.Failed {
+			fmt.Printf("FAIL %s: %s\n%s\n", c.Name, c.Verdict, c.Diff)
+		}
+	}
+	fmt.Printf("%d failed, %d passed\n", report.Failures, report.Passed)
+	writeReport(*jsonReport, func(f *os.File) error { return report.WriteJSON(f) })
+	writeReport(*htmlReport, func(f *os.File) error { return report.WriteHTML(f) })
+	if report.Failures != 0 {
+		os.Exit(1)
+	}
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "corpus" {
+		corpus(os.Args[2:])
+		return
+	}
+	config := flag.String("config", "", "options `file`")
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	a, err := source.ReadAll(context.Background(), flag.Arg(0))
+	if err != nil {
+		fatal(err)
+	}
+	b, err := source.ReadAll(context.Background(), flag.Arg(1))
+	if err != nil {
+		fatal(err)
+	}
+	opts := loadOptions(*config, jsondiff.ConsoleOptions(os.Stdout))
+	diff, s := jsondiff.Compare(a, b, &opts)
+	if diff.IsError() {
+		errA, errB := jsondiff.InputErrors(a, b, &opts)
+		for i, err := range []*jsondiff.InputError{errA, errB} {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "jsondiff: %s: %v\n%s\n", flag.Arg(i), err, err.Snippet)
+			}
+		}
+	} else {
+		fmt.Println(s)
+	}
+	os.Exit(jsondiff.ExitCode(diff))
+}
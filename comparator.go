@@ -0,0 +1,74 @@
+package jsondiff
+
+// Comparator is a custom comparison rule for values selected by path or by
+// shape, so that domain rules can live in user code, e.g. amounts in different
+// currencies:
+//
+//	jsondiff.Comparator{
+//		Keys: []string{"amount", "currency"},
+//		Equal: func(a, b interface{}) bool {
+//			return toUSD(a) == toUSD(b)
+//		},
+//	}
+//
+// A comparator applies to values present in both documents whose path matches
+// any of Paths and which are objects having all of Keys in both documents.
+// Empty Paths match any path, empty Keys match values of any type.
+type Comparator struct {
+	// Path patterns of the values, see Compare.
+	Paths []string
+	// Keys which both objects must have, e.g. a discriminator like "currency"
+	// along with the compared properties.
+	Keys []string
+	// Returns true if the values match. Values are passed as decoded from
+	// JSON with json.Decoder.UseNumber. When values don't match, they are
+	// compared as usual to render the difference, and the verdict is NoMatch
+	// even if there is no difference otherwise.
+	Equal func(a, b interface{}) bool
+}
+
+func (c *Comparator) applies(ctx *context, a, b interface{}) bool {
+	if len(c.Paths) != 0 && !ctx.pathMatches(c.Paths) {
+		return false
+	}
+	return hasKeys(a, c.Keys) && hasKeys(b, c.Keys)
+}
+
+func hasKeys(v interface{}, keys []string) bool {
+	if len(keys) == 0 {
+		return true
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, k := range keys {
+		if _, ok := m[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// comparator returns the first comparator applicable to the values at the
+// current path, nil if there is none.
+func (ctx *context) comparator(a, b interface{}) *Comparator {
+	for i := range ctx.opts.Comparators {
+		if c := &ctx.opts.Comparators[i]; c.applies(ctx, a, b) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (ctx *context) compareWith(c *Comparator, a, b interface{}) *delta {
+	if c.Equal(a, b) {
+		ctx.result(FullMatch)
+		return ctx.newDelta(delta{kind: deltaMatch, a: a, b: b})
+	}
+	ctx.downgrade(ValueChanged)
+	if d := ctx.compareStructure(a, b); d.differs {
+		return d
+	}
+	return ctx.newDelta(delta{kind: deltaChanged, a: a, b: b, differs: true})
+}
@@ -0,0 +1,48 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestComparators(t *testing.T) {
+	rates := map[string]float64{"USD": 1, "EUR": 1.25}
+	usd := func(v interface{}) (float64, bool) {
+		m := v.(map[string]interface{})
+		amount, _ := m["amount"].(json.Number).Float64()
+		rate, ok := rates[m["currency"].(string)]
+		return amount * rate, ok
+	}
+	sameAmount := func(a, b interface{}) bool {
+		ua, okA := usd(a)
+		ub, okB := usd(b)
+		return okA && okB && ua == ub
+	}
+	opts := Options{
+		Comparators: []Comparator{
+			{Keys: []string{"amount", "currency"}, Equal: sameAmount},
+			{Paths: []string{"name"}, Equal: func(a, b interface{}) bool { return true }},
+		},
+	}
+	cases := []struct {
+		a      string
+		b      string
+		result Difference
+	}{
+		{`{"price": {"amount": 10, "currency": "EUR"}, "name": "a"}`, `{"price": {"amount": 12.5, "currency": "USD"}, "name": "b"}`, FullMatch},
+		{`{"price": {"amount": 10, "currency": "EUR"}}`, `{"price": {"amount": 10, "currency": "USD"}}`, NoMatch},
+		// values are equal, but the currency is unknown
+		{`{"price": {"amount": 10, "currency": "GBP"}}`, `{"price": {"amount": 10, "currency": "GBP"}}`, NoMatch},
+		// shape doesn't match
+		{`{"price": {"amount": 10}}`, `{"price": {"amount": 10}}`, FullMatch},
+		{`{"name": 1}`, `{}`, SupersetMatch},
+	}
+	for i, c := range cases {
+		if result, diff := Compare([]byte(c.a), []byte(c.b), &opts); result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s\n%s", i, result, c.result, diff)
+		}
+		if result := Verdict([]byte(c.a), []byte(c.b), &opts); result != c.result {
+			t.Errorf("case %d failed, got verdict: %s, expected: %s", i, result, c.result)
+		}
+	}
+}
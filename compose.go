@@ -0,0 +1,148 @@
+package jsondiff
+
+import (
+	"reflect"
+)
+
+// squash returns a change from the old value to the new one, where oldOK and
+// newOK tell whether the value exists before and after the change. Returns
+// false if there is no change, unchanged values are kept only if keep is true.
+func squash(path string, ov interface{}, oldOK bool, nv interface{}, newOK bool, keep bool) (Change, bool) {
+	switch {
+	case !oldOK && !newOK:
+		return Change{}, false
+	case !oldOK:
+		return Change{Path: path, Kind: ChangeAdded, New: nv}, true
+	case !newOK:
+		return Change{Path: path, Kind: ChangeRemoved, Old: ov}, true
+	case reflect.DeepEqual(ov, nv):
+		return Change{Path: path, Kind: ChangeUnchanged, Old: ov, New: nv}, keep
+	}
+	return Change{Path: path, Kind: ChangeModified, Old: ov, New: nv}, true
+}
+
+func relativePointer(p, q string) []string {
+	tokens, _ := splitPointer(q[len(p):])
+	return tokens
+}
+
+// Compose merges two sequential change sets into one, so that it describes the
+// changes from the document before d1 to the document after d2, e.g. to get
+// the net change between non-adjacent snapshots. Changes which are overwritten
+// or undone are dropped. Changes of d2 inside the values changed by d1 are
+// merged into them, changes of d2 containing the values changed by d1 replace
+// them. Unchanged values are kept only if any of the merged changes is
+// ChangeUnchanged.
+//
+// D2 has to describe changes of the document produced by d1, e.g. both change
+// sets are produced by CompareWithChanges from consecutive snapshots,
+// otherwise the result is unspecified.
+func Compose(d1, d2 []Change) []Change {
+	result := make([]*Change, len(d1))
+	for i := range d1 {
+		c := d1[i]
+		result[i] = &c
+	}
+
+next:
+	for _, c2 := range d2 {
+		keep := c2.Kind == ChangeUnchanged
+		for i, c1 := range result {
+			if c1 == nil {
+				continue
+			}
+			keep := keep || c1.Kind == ChangeUnchanged
+			if c1.Path == c2.Path {
+				c, ok := squash(c2.Path, c1.Old, c1.Kind != ChangeAdded, c2.New, c2.Kind != ChangeRemoved, keep)
+				result[i] = nil
+				if ok {
+					result[i] = &c
+				}
+				continue next
+			}
+			if isPointerPrefix(c1.Path, c2.Path) && c1.Kind != ChangeRemoved {
+				nv, err := patch(c1.New, relativePointer(c1.Path, c2.Path), c2.Kind, c2.New)
+				if err != nil {
+					break
+				}
+				c, ok := squash(c1.Path, c1.Old, c1.Kind != ChangeAdded, nv, true, keep)
+				result[i] = nil
+				if ok {
+					result[i] = &c
+				}
+				continue next
+			}
+		}
+
+		// restore the original value from the changes inside of it, going
+		// backwards so that array indices are valid
+		old := c2.Old
+		for i := len(result) - 1; i >= 0; i-- {
+			c1 := result[i]
+			if c1 == nil || !isPointerPrefix(c2.Path, c1.Path) {
+				continue
+			}
+			inv := Invert([]Change{*c1})[0]
+			v, err := patch(old, relativePointer(c2.Path, c1.Path), inv.Kind, inv.New)
+			if err != nil {
+				continue
+			}
+			old = v
+			keep = keep || c1.Kind == ChangeUnchanged
+			result[i] = nil
+		}
+		if c, ok := squash(c2.Path, old, c2.Kind != ChangeAdded, c2.New, c2.Kind != ChangeRemoved, keep); ok {
+			result = append(result, &c)
+		}
+	}
+
+	var composed []Change
+	for _, c := range result {
+		if c != nil {
+			composed = append(composed, *c)
+		}
+	}
+	return composed
+}
+
+// Conflict describes two changes which modify the same value incompatibly, see
+// Conflicts. Path is a JSON Pointer to the value, which is the outer one when
+// one of the changes is inside of the value changed by the other one.
+type Conflict struct {
+	Path string
+	A    Change
+	B    Change
+}
+
+// Conflicts reports changes of two change sets produced independently from the
+// same document which can't be both applied, e.g. for optimistic concurrency
+// control. Changes conflict when they change the same value differently, or
+// when one of them changes a value inside of the value changed by the other
+// one. Unchanged values never conflict. Conflicts are ordered as the changes of
+// d1.
+func Conflicts(d1, d2 []Change) []Conflict {
+	var conflicts []Conflict
+	for _, a := range d1 {
+		if a.Kind == ChangeUnchanged {
+			continue
+		}
+		for _, b := range d2 {
+			if b.Kind == ChangeUnchanged {
+				continue
+			}
+			switch {
+			case a.Path == b.Path:
+				if a.Kind == b.Kind && reflect.DeepEqual(a.New, b.New) {
+					continue
+				}
+			case isPointerPrefix(b.Path, a.Path):
+				conflicts = append(conflicts, Conflict{Path: b.Path, A: a, B: b})
+				continue
+			case !isPointerPrefix(a.Path, b.Path):
+				continue
+			}
+			conflicts = append(conflicts, Conflict{Path: a.Path, A: a, B: b})
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,61 @@
+package jsondiff
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func sortedChanges(changes []Change) []Change {
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func TestCompose(t *testing.T) {
+	opts := DefaultJSONOptions()
+	cases := []struct {
+		s1, s2, s3 string
+	}{
+		{`{"a": 1, "b": 2}`, `{"a": 2, "b": 2}`, `{"a": 3, "b": 2}`},
+		{`{"a": 1}`, `{"a": 2}`, `{"a": 1}`},
+		{`{"a": 1}`, `{}`, `{"a": 1}`},
+		{`{}`, `{"a": {"x": 1}}`, `{"a": {"x": 2, "y": 3}}`},
+		{`{"a": {"x": 1, "y": 1}}`, `{"a": {"x": 2, "y": 1}}`, `{"a": [1]}`},
+		{`{"a": {"x": 1, "y": 1}}`, `{"a": {"x": 2, "y": 1}}`, `{}`},
+		{`{"a": [1, 2, 3]}`, `{"a": [1]}`, `{"a": [1, 5, 6, 7]}`},
+		{`{"a": [1, 2, 3]}`, `{"a": [1, 2, 3, 4]}`, `{"a": [1, 9]}`},
+		{`{"a": [{"x": 1}]}`, `{"a": [{"x": 1, "y": 2}]}`, `{"a": [{"x": 3, "y": 4}]}`},
+		{`{"a~/b": 1}`, `{"a~/b": {"c": 1}}`, `{"a~/b": {"c": 2}}`},
+	}
+	for _, c := range cases {
+		_, _, d1 := CompareWithChanges([]byte(c.s1), []byte(c.s2), &opts)
+		_, _, d2 := CompareWithChanges([]byte(c.s2), []byte(c.s3), &opts)
+		_, _, expected := CompareWithChanges([]byte(c.s1), []byte(c.s3), &opts)
+		got := sortedChanges(Compose(d1, d2))
+		if !reflect.DeepEqual(got, sortedChanges(expected)) {
+			t.Errorf("%s -> %s -> %s: got %+v, expected %+v", c.s1, c.s2, c.s3, got, expected)
+		}
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	opts := DefaultJSONOptions()
+	opts.IncludeUnchanged = true
+	base := []byte(`{"a": 1, "b": {"x": 1}, "c": [1, 2], "d": 1}`)
+	_, _, d1 := CompareWithChanges(base, []byte(`{"a": 2, "b": {"x": 2}, "c": [1, 2, 3], "d": 2}`), &opts)
+	_, _, d2 := CompareWithChanges(base, []byte(`{"a": 3, "b": 1, "c": [1, 2, 3], "d": 1}`), &opts)
+	conflicts := Conflicts(d1, d2)
+	var paths []string
+	for _, c := range conflicts {
+		paths = append(paths, c.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"/a", "/b"}) {
+		t.Errorf("got conflicts %+v", conflicts)
+	}
+	if len(conflicts) == 2 && (conflicts[1].A.Path != "/b/x" || conflicts[1].B.Path != "/b") {
+		t.Errorf("unexpected conflict: %+v", conflicts[1])
+	}
+	if c := Conflicts(d2, d1); len(c) != 2 || c[1].Path != "/b" || c[1].A.Path != "/b" {
+		t.Errorf("got reversed conflicts %+v", c)
+	}
+}
@@ -0,0 +1,396 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"regexp"
+)
+
+// config is a declarative representation of Options, see LoadOptions.
+type config struct {
+	Preset              string            `json:"preset"`
+	Prefix              *string           `json:"prefix"`
+	Indent              *string           `json:"indent"`
+	ChangedSeparator    *string           `json:"changedSeparator"`
+	LeftLabel           *string           `json:"leftLabel"`
+	RightLabel          *string           `json:"rightLabel"`
+	PrintTypes          *bool             `json:"printTypes"`
+	PrintSizes          *bool             `json:"printSizes"`
+	SkipMatches         *bool             `json:"skipMatches"`
+	QuickFullMatch      *bool             `json:"quickFullMatch"`
+	VerboseErrors       *bool             `json:"verboseErrors"`
+	Lenient             *bool             `json:"lenient"`
+	Strict              *bool             `json:"strict"`
+	NumericKeyOrder     *bool             `json:"numericKeyOrder"`
+	NumericKeysAsArrays *bool             `json:"numericKeysAsArrays"`
+	DocumentHeader      *bool             `json:"documentHeader"`
+	IncludeUnchanged    *bool             `json:"includeUnchanged"`
+	CompactPatch        *bool             `json:"compactPatch"`
+	Placeholders        *bool             `json:"placeholders"`
+	NormalizeNumbers    *bool             `json:"normalizeNumbers"`
+	AlignKeys           *bool             `json:"alignKeys"`
+	UnquotedKeys        *bool             `json:"unquotedKeys"`
+	SeparateSkipped     *bool             `json:"separateSkipped"`
+	OptionalKeys        []string          `json:"optionalKeys"`
+	Ignore              []string          `json:"ignore"`
+	UnorderedArrays     []string          `json:"unorderedArrays"`
+	KeyedArrays         map[string]string `json:"keyedArrays"`
+	IgnoreValues        []string          `json:"ignoreValuesMatching"`
+	EmptyObject         *string           `json:"emptyObject"`
+	EmptyArray          *string           `json:"emptyArray"`
+	ChangedLayout       *string           `json:"changedLayout"`
+	SkippedPlacement    *string           `json:"skippedPlacement"`
+	Format              *string           `json:"format"`
+	QuoteMode           *string           `json:"quoteMode"`
+	InputFormat         *string           `json:"inputFormat"`
+	Epsilon             *float64          `json:"epsilon"`
+	DecimalPlaces       *int              `json:"decimalPlaces"`
+	MaxLineWidth        *int              `json:"maxLineWidth"`
+	RollupDepth         *int              `json:"rollupDepth"`
+	Normalize           []normalizeStep   `json:"normalize"`
+	ArraySampling       *samplingConfig   `json:"arraySampling"`
+	Tolerance           []toleranceConfig `json:"tolerance"`
+}
+
+type samplingConfig struct {
+	MinLength int   `json:"minLength"`
+	Head      int   `json:"head"`
+	Tail      int   `json:"tail"`
+	Random    int   `json:"random"`
+	Seed      int64 `json:"seed"`
+}
+
+type toleranceConfig struct {
+	Paths                []string `json:"paths"`
+	MaxDifferingElements float64  `json:"maxDifferingElements"`
+	RelativeDrift        float64  `json:"relativeDrift"`
+}
+
+type normalizeStep struct {
+	Op           string   `json:"op"`
+	Paths        []string `json:"paths"`
+	Places       int      `json:"places"`
+	DecodeBase64 bool     `json:"decodeBase64"`
+	IgnoreClaims []string `json:"ignoreClaims"`
+}
+
+func (s *normalizeStep) normalizer() (Normalizer, error) {
+	switch s.Op {
+	case "sortArrays":
+		return SortArrays(s.Paths...), nil
+	case "dropKeys":
+		return DropKeys(s.Paths...), nil
+	case "roundNumbers":
+		return RoundNumbers(s.Places, s.Paths...), nil
+	case "lowercaseStrings":
+		return LowercaseStrings(s.Paths...), nil
+	case "jsonapi":
+		return JSONAPI(), nil
+	case "halLinks":
+		return HALLinks(), nil
+	case "graphqlResponse":
+		return GraphQLResponse(), nil
+	case "cloudEvents":
+		return CloudEvents(s.DecodeBase64), nil
+	case "jwt":
+		return DecodeJWTs(s.IgnoreClaims...), nil
+	case "forms":
+		return DecodeForms(s.Paths...), nil
+	case "extendedJSON":
+		return ExtendedJSON(), nil
+	}
+	return nil, errors.New("jsondiff: invalid normalize op: " + s.Op)
+}
+
+var emptyCollectionModes = map[string]int{
+	"default":       int(EmptyCollectionDefault),
+	"matches-any":   int(EmptyCollectionMatchesAny),
+	"matches-empty": int(EmptyCollectionMatchesEmpty),
+}
+
+var changedLayouts = map[string]int{
+	"inline":         int(ChangedInline),
+	"separate-lines": int(ChangedSeparateLines),
+}
+
+var skippedPlacements = map[string]int{
+	"in-place": int(SkippedInPlace),
+	"before":   int(SkippedBefore),
+	"after":    int(SkippedAfter),
+	"around":   int(SkippedAround),
+}
+
+var quoteModes = map[string]int{
+	"go":   int(QuoteGo),
+	"json": int(QuoteJSON),
+	"raw":  int(QuoteRaw),
+}
+
+var inputFormats = map[string]int{
+	"json":    int(JSONInput),
+	"cbor":    int(CBORInput),
+	"msgpack": int(MessagePackInput),
+}
+
+var outputFormats = map[string]int{
+	"text":      int(TextOutput),
+	"document":  int(DocumentOutput),
+	"jd":        int(JDOutput),
+	"jsonpatch": int(JSONPatchOutput),
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setBool(dst *bool, src *bool) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// lookupEnum returns a value of the named enum field, or def if the field is
+// not set.
+func lookupEnum(m map[string]int, name string, s *string, def int) (int, error) {
+	if s == nil {
+		return def, nil
+	}
+	v, ok := m[*s]
+	if !ok {
+		return 0, errors.New("jsondiff: invalid " + name + " value: " + *s)
+	}
+	return v, nil
+}
+
+// epsilonComparator returns a number comparator which treats numbers within
+// epsilon from each other as equal. Numbers which can't be parsed as floats
+// are compared byte by byte.
+func epsilonComparator(epsilon float64) func(a, b json.Number) bool {
+	return func(a, b json.Number) bool {
+		af, errA := a.Float64()
+		bf, errB := b.Float64()
+		if errA != nil || errB != nil {
+			return a == b
+		}
+		return math.Abs(af-bf) <= epsilon
+	}
+}
+
+// LoadOptions reads options from a JSON configuration document, so that the
+// comparison policy can be shared between Go code and other tools. All the
+// fields are optional:
+//
+//	{
+//	    "preset": "console",
+//	    "prefix": "",
+//	    "indent": "  ",
+//	    "changedSeparator": " => ",
+//	    "leftLabel": "expected",
+//	    "rightLabel": "actual",
+//	    "printTypes": false,
+//	    "printSizes": false,
+//	    "skipMatches": true,
+//	    "quickFullMatch": false,
+//	    "verboseErrors": false,
+//	    "lenient": false,
+//	    "strict": false,
+//	    "numericKeyOrder": false,
+//	    "numericKeysAsArrays": false,
+//	    "documentHeader": false,
+//	    "includeUnchanged": false,
+//	    "compactPatch": false,
+//	    "placeholders": false,
+//	    "normalizeNumbers": false,
+//	    "alignKeys": false,
+//	    "unquotedKeys": false,
+//	    "optionalKeys": ["**.etag"],
+//	    "ignore": ["**.createdAt"],
+//	    "unorderedArrays": ["tags"],
+//	    "keyedArrays": {"users": "id"},
+//	    "ignoreValuesMatching": ["^[0-9a-f]{24}$"],
+//	    "emptyObject": "default" | "matches-any" | "matches-empty",
+//	    "emptyArray": "default" | "matches-any" | "matches-empty",
+//	    "changedLayout": "inline" | "separate-lines",
+//	    "skippedPlacement": "in-place" | "before" | "after" | "around",
+//	    "separateSkipped": false,
+//	    "format": "text" | "document" | "jd" | "jsonpatch",
+//	    "quoteMode": "go" | "json" | "raw",
+//	    "inputFormat": "json" | "cbor" | "msgpack",
+//	    "epsilon": 0.001,
+//	    "decimalPlaces": 2,
+//	    "maxLineWidth": 120,
+//	    "rollupDepth": 2,
+//	    "normalize": [
+//	        {"op": "sortArrays", "paths": ["tags"]},
+//	        {"op": "dropKeys", "paths": ["**.updatedAt"]},
+//	        {"op": "roundNumbers", "places": 2, "paths": ["**.price"]},
+//	        {"op": "lowercaseStrings", "paths": ["**.email"]},
+//	        {"op": "jsonapi"},
+//	        {"op": "halLinks"},
+//	        {"op": "graphqlResponse"},
+//	        {"op": "cloudEvents", "decodeBase64": true},
+//	        {"op": "jwt", "ignoreClaims": ["iat", "exp"]},
+//	        {"op": "forms", "paths": ["request.query"]},
+//	        {"op": "extendedJSON"}
+//	    ],
+//	    "arraySampling": {"minLength": 100000, "head": 100, "tail": 100, "random": 1000, "seed": 1},
+//	    "tolerance": [
+//	        {"paths": ["results"], "maxDifferingElements": 0.05},
+//	        {"paths": ["**.latency"], "relativeDrift": 0.001}
+//	    ]
+//	}
+//
+// Options are based on the named preset (see RegisterPreset) if it's
+// specified, on zero Options otherwise. Fields which are set replace the
+// corresponding options of the preset, lists and maps such as ignore,
+// keyedArrays and tolerance are not merged with the preset's ones. Epsilon
+// sets CompareNumbers to a function which treats numbers within epsilon from
+// each other as equal. Normalize sets Transform to a composition of the
+// corresponding normalizers, see Normalize. Unknown fields are reported as
+// errors. Only JSON is supported, configuration in other formats such as YAML
+// has to be converted to JSON first.
+func LoadOptions(r io.Reader) (Options, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Options{}, err
+	}
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) != 0 && trimmed[0] != '{' {
+		return Options{}, errors.New("jsondiff: options must be a JSON object, YAML and other formats are not supported")
+	}
+	var cfg config
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&cfg); err != nil {
+		return Options{}, err
+	}
+	var rest json.RawMessage
+	if err := d.Decode(&rest); err != io.EOF {
+		return Options{}, errors.New("jsondiff: unexpected data after the options")
+	}
+
+	var opts Options
+	if cfg.Preset != "" {
+		var ok bool
+		opts, ok = PresetOptions(cfg.Preset)
+		if !ok {
+			return Options{}, errors.New("jsondiff: unknown preset: " + cfg.Preset)
+		}
+	}
+	setString(&opts.Prefix, cfg.Prefix)
+	setString(&opts.Indent, cfg.Indent)
+	setString(&opts.ChangedSeparator, cfg.ChangedSeparator)
+	setString(&opts.LeftLabel, cfg.LeftLabel)
+	setString(&opts.RightLabel, cfg.RightLabel)
+	setBool(&opts.PrintTypes, cfg.PrintTypes)
+	setBool(&opts.PrintSizes, cfg.PrintSizes)
+	setBool(&opts.SkipMatches, cfg.SkipMatches)
+	setBool(&opts.QuickFullMatch, cfg.QuickFullMatch)
+	setBool(&opts.VerboseErrors, cfg.VerboseErrors)
+	setBool(&opts.Lenient, cfg.Lenient)
+	setBool(&opts.Strict, cfg.Strict)
+	setBool(&opts.NumericKeyOrder, cfg.NumericKeyOrder)
+	setBool(&opts.NumericKeysAsArrays, cfg.NumericKeysAsArrays)
+	setBool(&opts.DocumentHeader, cfg.DocumentHeader)
+	setBool(&opts.IncludeUnchanged, cfg.IncludeUnchanged)
+	setBool(&opts.CompactPatch, cfg.CompactPatch)
+	setBool(&opts.Placeholders, cfg.Placeholders)
+	setBool(&opts.NormalizeNumbers, cfg.NormalizeNumbers)
+	setBool(&opts.AlignKeys, cfg.AlignKeys)
+	setBool(&opts.UnquotedKeys, cfg.UnquotedKeys)
+	setBool(&opts.SeparateSkipped, cfg.SeparateSkipped)
+	if cfg.OptionalKeys != nil {
+		opts.OptionalKeys = cfg.OptionalKeys
+	}
+	if cfg.Ignore != nil {
+		opts.Ignore = cfg.Ignore
+	}
+	if cfg.UnorderedArrays != nil {
+		opts.UnorderedArrays = cfg.UnorderedArrays
+	}
+	if cfg.KeyedArrays != nil {
+		opts.KeyedArrays = cfg.KeyedArrays
+	}
+	if cfg.IgnoreValues != nil {
+		opts.IgnoreValuesMatching = make([]*regexp.Regexp, len(cfg.IgnoreValues))
+		for i, expr := range cfg.IgnoreValues {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return Options{}, errors.New("jsondiff: invalid ignoreValuesMatching: " + err.Error())
+			}
+			opts.IgnoreValuesMatching[i] = re
+		}
+	}
+	emptyObject, err := lookupEnum(emptyCollectionModes, "emptyObject", cfg.EmptyObject, int(opts.EmptyObject))
+	if err != nil {
+		return Options{}, err
+	}
+	emptyArray, err := lookupEnum(emptyCollectionModes, "emptyArray", cfg.EmptyArray, int(opts.EmptyArray))
+	if err != nil {
+		return Options{}, err
+	}
+	changedLayout, err := lookupEnum(changedLayouts, "changedLayout", cfg.ChangedLayout, int(opts.ChangedLayout))
+	if err != nil {
+		return Options{}, err
+	}
+	skippedPlacement, err := lookupEnum(skippedPlacements, "skippedPlacement", cfg.SkippedPlacement, int(opts.SkippedPlacement))
+	if err != nil {
+		return Options{}, err
+	}
+	format, err := lookupEnum(outputFormats, "format", cfg.Format, int(opts.Format))
+	if err != nil {
+		return Options{}, err
+	}
+	quoteMode, err := lookupEnum(quoteModes, "quoteMode", cfg.QuoteMode, int(opts.QuoteMode))
+	if err != nil {
+		return Options{}, err
+	}
+	inputFormat, err := lookupEnum(inputFormats, "inputFormat", cfg.InputFormat, int(opts.InputFormat))
+	if err != nil {
+		return Options{}, err
+	}
+	opts.EmptyObject = EmptyCollectionMode(emptyObject)
+	opts.EmptyArray = EmptyCollectionMode(emptyArray)
+	opts.ChangedLayout = ChangedLayout(changedLayout)
+	opts.SkippedPlacement = SkippedPlacement(skippedPlacement)
+	opts.Format = OutputFormat(format)
+	opts.QuoteMode = QuoteMode(quoteMode)
+	opts.InputFormat = InputFormat(inputFormat)
+	if cfg.MaxLineWidth != nil {
+		opts.MaxLineWidth = *cfg.MaxLineWidth
+	}
+	if cfg.DecimalPlaces != nil {
+		opts.DecimalPlaces = *cfg.DecimalPlaces
+	}
+	if cfg.RollupDepth != nil {
+		opts.RollupDepth = *cfg.RollupDepth
+	}
+	if s := cfg.ArraySampling; s != nil {
+		opts.ArraySampling = ArraySampling{MinLength: s.MinLength, Head: s.Head, Tail: s.Tail, Random: s.Random, Seed: s.Seed}
+	}
+	if cfg.Tolerance != nil {
+		opts.Tolerance = make([]Tolerance, len(cfg.Tolerance))
+		for i, t := range cfg.Tolerance {
+			opts.Tolerance[i] = Tolerance{Paths: t.Paths, MaxDifferingElements: t.MaxDifferingElements, RelativeDrift: t.RelativeDrift}
+		}
+	}
+	if cfg.Epsilon != nil {
+		opts.CompareNumbers = epsilonComparator(*cfg.Epsilon)
+	}
+	if cfg.Normalize != nil {
+		steps := make([]Normalizer, 0, len(cfg.Normalize))
+		for i := range cfg.Normalize {
+			step, err := cfg.Normalize[i].normalizer()
+			if err != nil {
+				return Options{}, err
+			}
+			steps = append(steps, step)
+		}
+		opts.Transform = Normalize(steps...)
+	}
+	return opts, nil
+}
@@ -0,0 +1,90 @@
+package jsondiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadOptions(t *testing.T) {
+	opts, err := LoadOptions(strings.NewReader(`{
+		"preset": "console",
+		"skipMatches": true,
+		"optionalKeys": ["**.etag"],
+		"emptyArray": "matches-any",
+		"format": "jd",
+		"epsilon": 0.01,
+		"ignoreValuesMatching": ["^id-"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Added != DefaultConsoleOptions().Added || !opts.SkipMatches ||
+		opts.EmptyArray != EmptyCollectionMatchesAny || opts.Format != JDOutput {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+	result, diff := Compare([]byte(`{"a": 1.001, "b": [1], "id": "id-1"}`), []byte(`{"a": 1, "b": [], "etag": "x", "id": "id-2"}`), &opts)
+	if result != FullMatch || diff != "@ [\"etag\"]\n+ \"x\"\n" {
+		t.Errorf("got %s:\n%s", result, diff)
+	}
+
+	errCases := []string{
+		`{"preset": "no-such-preset"}`,
+		`{"format": "yaml"}`,
+		`{"unknown": 1}`,
+		`{"skipMatches": "yes"}`,
+		`{"normalize": [{"op": "uppercase"}]}`,
+		`{"ignoreValuesMatching": ["("]}`,
+		"preset: console\nskipMatches: true\n",
+		`{"skipMatches": true} garbage`,
+		`{"skipMatches": true} {}`,
+		`{"skipMatches": true}}`,
+	}
+	for _, c := range errCases {
+		if _, err := LoadOptions(strings.NewReader(c)); err == nil {
+			t.Errorf("%s: expected an error", c)
+		}
+	}
+}
+
+func TestLoadOptionsNormalize(t *testing.T) {
+	opts, err := LoadOptions(strings.NewReader(`{
+		"normalize": [
+			{"op": "sortArrays", "paths": ["tags"]},
+			{"op": "roundNumbers", "places": 1, "paths": ["price"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, _ := Compare([]byte(`{"tags": [2, 1], "price": 1.04}`), []byte(`{"tags": [1, 2], "price": 1}`), &opts)
+	if result != FullMatch {
+		t.Errorf("got %s, expected FullMatch", result)
+	}
+}
+
+func TestLoadOptionsTolerance(t *testing.T) {
+	preset := Options{
+		Tolerance:       []Tolerance{{Paths: []string{"a"}, RelativeDrift: 0.1}},
+		Ignore:          []string{"a"},
+		UnorderedArrays: []string{"a"},
+	}
+	RegisterPreset("test-tolerance", preset)
+	opts, err := LoadOptions(strings.NewReader(`{"preset": "test-tolerance", "tolerance": [{"paths": ["b"]}], "ignore": ["b"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// lists which are set replace the preset's ones, the rest are kept
+	if len(opts.Tolerance) != 1 || opts.Tolerance[0].Paths[0] != "b" ||
+		!reflect.DeepEqual(opts.Ignore, []string{"b"}) || !reflect.DeepEqual(opts.UnorderedArrays, []string{"a"}) {
+		t.Errorf("got tolerance %+v, ignore %v, unordered arrays %v", opts.Tolerance, opts.Ignore, opts.UnorderedArrays)
+	}
+	if preset.Tolerance[0].Paths[0] != "a" {
+		t.Errorf("preset was modified: %+v", preset.Tolerance)
+	}
+
+	_, err = LoadOptions(strings.NewReader("preset: console\n"))
+	if err == nil || !strings.Contains(err.Error(), "YAML") {
+		t.Errorf("got %v for YAML input", err)
+	}
+}
@@ -0,0 +1,33 @@
+package jsondiff
+
+import (
+	"os"
+)
+
+// DefaultMarkerOptions provides a set of options for terminals which don't
+// support colors: changes are marked with wdiff style brackets, e.g.
+// [-removed-] and {+added+}.
+func DefaultMarkerOptions() Options {
+	return Options{
+		Added:                 Tag{Begin: "{+", End: "+}"},
+		Removed:               Tag{Begin: "[-", End: "-]"},
+		Changed:               Tag{Begin: "{~", End: "~}"},
+		SkippedArrayElement:   SkippedArrayElement,
+		SkippedObjectProperty: SkippedObjectProperty,
+		ChangedSeparator:      " => ",
+		Indent:                "    ",
+	}
+}
+
+// ConsoleOptions returns DefaultConsoleOptions if the terminal the output is
+// written to supports ANSI escape sequences, and DefaultMarkerOptions
+// otherwise. On Windows, it enables processing of escape sequences for the
+// console if needed, which fails on legacy consoles. On other systems, and
+// when the output is not a console, escape sequences are assumed to be
+// supported.
+func ConsoleOptions(f *os.File) Options {
+	if enableVirtualTerminal(f) {
+		return DefaultConsoleOptions()
+	}
+	return DefaultMarkerOptions()
+}
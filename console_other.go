@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package jsondiff
+
+import (
+	"os"
+)
+
+func enableVirtualTerminal(f *os.File) bool {
+	return true
+}
@@ -0,0 +1,29 @@
+package jsondiff
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestDefaultMarkerOptions(t *testing.T) {
+	opts := DefaultMarkerOptions()
+	opts.SkipMatches = true
+	_, diff := Compare([]byte(`{"a": 1, "b": 2}`), []byte(`{"a": 2, "c": 3}`), &opts)
+	expected := "{\n    \"a\": {~1 => 2~},\n    [-\"b\": 2-],\n    {+\"c\": 3+}\n}"
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+	if preset, ok := PresetOptions("markers"); !ok || preset.Added != opts.Added {
+		t.Error("expected markers preset")
+	}
+}
+
+func TestConsoleOptions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("depends on the console")
+	}
+	if opts := ConsoleOptions(os.Stdout); opts.Added != DefaultConsoleOptions().Added {
+		t.Errorf("expected console options, got %+v", opts)
+	}
+}
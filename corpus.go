@@ -0,0 +1,150 @@
+package jsondiff
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	corpusExpectedSuffix = ".expected.json"
+	corpusActualSuffix   = ".actual.json"
+)
+
+// CorpusCase is the result of comparing a single pair of a corpus, see
+// RunCorpus.
+type CorpusCase struct {
+	// Path of the pair relative to the corpus directory, without the
+	// ".expected.json" and ".actual.json" suffixes.
+	Name       string     `json:"name"`
+	Difference Difference `json:"-"`
+	Verdict    string     `json:"verdict"`
+	Diff       string     `json:"diff"`
+	Failed     bool       `json:"failed"`
+}
+
+// CorpusReport is the result of running a corpus, see RunCorpus.
+type CorpusReport struct {
+	// Cases ordered by name.
+	Cases    []CorpusCase `json:"cases"`
+	Passed   int          `json:"passed"`
+	Failures int          `json:"failures"`
+	// Library version and fingerprint of the options used, see Version and
+	// Options.Fingerprint.
+	Version     string `json:"version"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// RunCorpus compares every pair of documents in the corpus directory and its
+// subdirectories, turning the library into a contract-testing harness. A pair
+// consists of NAME.actual.json and NAME.expected.json files, the actual
+// document is compared against the expected one like Compare(actual,
+// expected, opts). A case fails unless the verdict is FullMatch, or
+// SupersetMatch when strict is false. Pairs are compared concurrently, see
+// CompareBatch.
+//
+// Returns an error if the directory can't be read or a file doesn't have its
+// counterpart.
+func RunCorpus(dir string, opts *Options, strict bool) (*CorpusReport, error) {
+	var names []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case strings.HasSuffix(rel, corpusExpectedSuffix):
+			name := strings.TrimSuffix(rel, corpusExpectedSuffix)
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name+corpusActualSuffix))); err != nil {
+				return errors.New("jsondiff: no actual document for " + name)
+			}
+			names = append(names, name)
+		case strings.HasSuffix(rel, corpusActualSuffix):
+			name := strings.TrimSuffix(rel, corpusActualSuffix)
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name+corpusExpectedSuffix))); err != nil {
+				return errors.New("jsondiff: no expected document for " + name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	pairs := make([]Pair, len(names))
+	for i, name := range names {
+		base := filepath.Join(dir, filepath.FromSlash(name))
+		if pairs[i].A, err = os.ReadFile(base + corpusActualSuffix); err != nil {
+			return nil, err
+		}
+		if pairs[i].B, err = os.ReadFile(base + corpusExpectedSuffix); err != nil {
+			return nil, err
+		}
+	}
+	results, _ := CompareBatch(gocontext.Background(), pairs, opts, 0)
+
+	report := &CorpusReport{
+		Cases:       make([]CorpusCase, len(names)),
+		Version:     version,
+		Fingerprint: opts.Fingerprint(),
+	}
+	for i, r := range results {
+		failed := r.Difference != FullMatch && (strict || r.Difference != SupersetMatch)
+		report.Cases[i] = CorpusCase{
+			Name:       names[i],
+			Difference: r.Difference,
+			Verdict:    r.Difference.String(),
+			Diff:       r.Diff,
+			Failed:     failed,
+		}
+		if failed {
+			report.Failures++
+		} else {
+			report.Passed++
+		}
+	}
+	return report, nil
+}
+
+// WriteJSON writes the report as an indented JSON document:
+//
+//	{"cases": [{"name": "users/list", "verdict": "NoMatch", "diff": "...", "failed": true}], "passed": 0, "failures": 1, "version": "x.y.z", "fingerprint": "..."}
+func (r *CorpusReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	return enc.Encode(r)
+}
+
+var corpusHTML = template.Must(template.New("corpus").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>jsondiff corpus report</title>
+</head>
+<body>
+<h1>{{.Failures}} failed, {{.Passed}} passed</h1>
+{{range .Cases}}{{if .Failed}}<h2>{{.Name}}: {{.Verdict}}</h2>
+<pre>{{.Diff}}</pre>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes an HTML page with the differences of the failed cases.
+// Differences are escaped, so they should be rendered with options which
+// don't produce HTML, e.g. DefaultMarkerOptions.
+func (r *CorpusReport) WriteHTML(w io.Writer) error {
+	return corpusHTML.Execute(w, r)
+}
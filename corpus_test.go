@@ -0,0 +1,72 @@
+package jsondiff
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCorpus(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ok.actual.json":            `{"a": 1}`,
+		"ok.expected.json":          `{"a": 1}`,
+		"api/extra.actual.json":     `{"a": 1, "b": 2}`,
+		"api/extra.expected.json":   `{"a": 1}`,
+		"api/changed.actual.json":   `{"a": 1}`,
+		"api/changed.expected.json": `{"a": "<b>"}`,
+		"README.md":                 `ignored`,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := DefaultMarkerOptions()
+	report, err := RunCorpus(dir, &opts, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range report.Cases {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, " ") != "api/changed api/extra ok" || report.Failures != 1 || report.Passed != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if c := report.Cases[0]; !c.Failed || c.Difference != NoMatch || c.Verdict != "NoMatch" {
+		t.Errorf("unexpected case: %+v", c)
+	}
+	if report, _ := RunCorpus(dir, &opts, true); report.Failures != 2 {
+		t.Errorf("got %d failures in strict mode, expected 2", report.Failures)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"name": "api/changed"`) || !strings.Contains(buf.String(), `"failures": 1`) {
+		t.Errorf("unexpected JSON report:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := report.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `&#34;&lt;b&gt;&#34;`) || strings.Contains(buf.String(), "api/extra") {
+		t.Errorf("unexpected HTML report:\n%s", buf.String())
+	}
+
+	if err := os.Remove(filepath.Join(dir, "ok.expected.json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RunCorpus(dir, &opts, false); err == nil {
+		t.Error("expected an error for a missing expected document")
+	}
+}
@@ -0,0 +1,91 @@
+package jsondiff
+
+import (
+	"errors"
+	"sync"
+)
+
+// PathCoverage describes how often a value of the expected document matched,
+// see Coverage.
+type PathCoverage struct {
+	// Path of the value, uses the syntax of path patterns.
+	Path string
+	// Number of comparisons where the value matched.
+	Matched int
+}
+
+// Coverage merges matched paths of many comparisons against the same expected
+// document, e.g. a golden file shared by a test suite, and reports which
+// values of it were never matched by any of the compared documents. Values
+// are the scalars and empty collections of the expected document, see
+// Node.MatchedPaths. It's safe to use concurrently.
+type Coverage struct {
+	mu          sync.Mutex
+	comparisons int
+	paths       []string
+	matched     map[string]int
+}
+
+// NewCoverage returns coverage of the expected document compared with the
+// given options, values skipped by the options are not reported. Returns an
+// error if the document is invalid JSON.
+func NewCoverage(expected []byte, opts *Options) (*Coverage, error) {
+	diff, root := CompareTree(expected, expected, opts)
+	if root == nil {
+		return nil, errors.New("jsondiff: expected document is invalid json")
+	}
+	if diff != FullMatch {
+		return nil, errors.New("jsondiff: expected document doesn't match itself")
+	}
+	c := &Coverage{paths: root.MatchedPaths(), matched: make(map[string]int)}
+	for _, p := range c.paths {
+		c.matched[p] = 0
+	}
+	return c, nil
+}
+
+// Add records the result of a single comparison of a document against the
+// expected one, i.e. the root node returned by CompareTree(actual, expected,
+// opts). Matched values which are not in the expected document are ignored.
+func (c *Coverage) Add(root *Node) {
+	paths := root.MatchedPaths()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.comparisons++
+	for _, p := range paths {
+		if n, ok := c.matched[p]; ok {
+			c.matched[p] = n + 1
+		}
+	}
+}
+
+// Comparisons returns the number of comparisons recorded so far.
+func (c *Coverage) Comparisons() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.comparisons
+}
+
+// Report returns coverage of all the values of the expected document in the
+// order of the text output.
+func (c *Coverage) Report() []PathCoverage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	report := make([]PathCoverage, len(c.paths))
+	for i, p := range c.paths {
+		report[i] = PathCoverage{Path: p, Matched: c.matched[p]}
+	}
+	return report
+}
+
+// Uncovered returns paths of the values of the expected document which were
+// never matched, in the order of the text output.
+func (c *Coverage) Uncovered() []string {
+	var paths []string
+	for _, r := range c.Report() {
+		if r.Matched == 0 {
+			paths = append(paths, r.Path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,37 @@
+package jsondiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	expected := []byte(`{"id": 1, "items": [{"name": "a"}, {"name": "b"}], "meta": {}}`)
+	opts := Options{}
+	c, err := NewCoverage(expected, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, actual := range []string{
+		`{"id": 1, "items": [{"name": "a"}]}`,
+		`{"id": 2, "items": [{"name": "x"}, {"name": "b"}]}`,
+		`{"id": 1, "extra": true}`,
+	} {
+		_, root := CompareTree([]byte(actual), expected, &opts)
+		c.Add(root)
+	}
+	if n := c.Comparisons(); n != 3 {
+		t.Errorf("got %d comparisons, expected 3", n)
+	}
+	report := c.Report()
+	if len(report) != 4 || report[0] != (PathCoverage{"id", 2}) || report[2] != (PathCoverage{"items.1.name", 1}) {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if uncovered := strings.Join(c.Uncovered(), " "); uncovered != "meta" {
+		t.Errorf("got uncovered paths %q, expected %q", uncovered, "meta")
+	}
+
+	if _, err := NewCoverage([]byte(`{`), &opts); err == nil {
+		t.Errorf("expected an error for invalid json")
+	}
+}
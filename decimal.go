@@ -0,0 +1,110 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalExponent limits exponents of numbers compared as decimals, so
+// that a number like 1e1000000000 can't make the comparison allocate
+// arbitrary amounts of memory. Such numbers are compared literally.
+const maxDecimalExponent = 1000
+
+// roundDecimal parses the number exactly and rounds it half away from zero to
+// the given number of decimal places. Returns the rounded number scaled by
+// 10^places, false if the number can't be parsed.
+func roundDecimal(n json.Number, places int) (*big.Int, bool) {
+	s := string(n)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil || exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return nil, false
+		}
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, false
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	num := new(big.Int).Mul(r.Num(), scale)
+	q, m := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	// remainder has the sign of the number, round away from zero when it's
+	// at least a half of the denominator
+	if m.Abs(m).Lsh(m, 1).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(num.Sign())))
+	}
+	return q, true
+}
+
+// decimalEqual compares numbers as decimals rounded to the given number of
+// places, see Options.DecimalPlaces.
+func decimalEqual(a, b json.Number, places int) bool {
+	ra, okA := roundDecimal(a, places)
+	rb, okB := roundDecimal(b, places)
+	if !okA || !okB {
+		return a == b
+	}
+	return ra.Cmp(rb) == 0
+}
+
+// normalizeNumber formats the number in a canonical form: without leading
+// and trailing zeros, in plain decimal notation when the exponent of its
+// leading digit is between -7 and 20, and in "1.5e+21" notation otherwise.
+// Negative zero becomes 0. Numbers which can't be parsed, e.g. NaN of the
+// lenient syntax, are returned as is.
+func normalizeNumber(n json.Number) string {
+	s := string(n)
+	neg := strings.HasPrefix(s, "-")
+	mantissa := strings.TrimPrefix(s, "-")
+	exp := 0
+	if i := strings.IndexAny(mantissa, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.Atoi(mantissa[i+1:]); err != nil {
+			return s
+		}
+		mantissa = mantissa[:i]
+	}
+	// digits of the number as an integer multiplied by 10^exp
+	digits := mantissa
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		digits = mantissa[:i] + mantissa[i+1:]
+		exp -= len(mantissa) - i - 1
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return s
+	}
+	digits = strings.TrimLeft(digits, "0")
+	trimmed := strings.TrimRight(digits, "0")
+	exp += len(digits) - len(trimmed)
+	digits = trimmed
+	if digits == "" {
+		return "0"
+	}
+
+	sign := ""
+	if neg {
+		sign = "-"
+	}
+	count := len(digits)
+	lead := count - 1 + exp
+	switch {
+	case lead < -7 || lead > 20:
+		frac := ""
+		if count > 1 {
+			frac = "." + digits[1:]
+		}
+		expSign := "+"
+		if lead < 0 {
+			expSign = "-"
+			lead = -lead
+		}
+		return sign + digits[:1] + frac + "e" + expSign + strconv.Itoa(lead)
+	case exp >= 0:
+		return sign + digits + strings.Repeat("0", exp)
+	case count+exp > 0:
+		return sign + digits[:count+exp] + "." + digits[count+exp:]
+	}
+	return sign + "0." + strings.Repeat("0", -(count+exp)) + digits
+}
@@ -0,0 +1,63 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecimalPlaces(t *testing.T) {
+	cases := []struct {
+		a      string
+		b      string
+		places int
+		result Difference
+	}{
+		{`10.5`, `10.50`, 2, FullMatch},
+		{`10.499`, `1.05e1`, 2, FullMatch},
+		{`1.005`, `1.01`, 2, FullMatch},
+		{`-1.005`, `-1.01`, 2, FullMatch},
+		{`-1.004`, `-1.01`, 2, NoMatch},
+		{`0.1`, `0.2`, 2, NoMatch},
+		{`2.5`, `3`, 0, NoMatch},
+		{`2.5`, `3`, 1, NoMatch},
+		{`1e1001`, `1e1001`, 2, FullMatch},
+		{`1e1001`, `10e1000`, 2, NoMatch},
+	}
+	for _, c := range cases {
+		opts := Options{DecimalPlaces: c.places}
+		if result, _ := Compare([]byte(c.a), []byte(c.b), &opts); result != c.result {
+			t.Errorf("%s %s with %d places: got %s, expected %s", c.a, c.b, c.places, result, c.result)
+		}
+	}
+}
+
+func TestNormalizeNumber(t *testing.T) {
+	cases := []struct {
+		n        json.Number
+		expected string
+	}{
+		{"1.50", "1.5"},
+		{"15e-1", "1.5"},
+		{"1.5E0", "1.5"},
+		{"100", "100"},
+		{"1e2", "100"},
+		{"0.000", "0"},
+		{"-0", "0"},
+		{"-0.0012", "-0.0012"},
+		{"12.5e-10", "1.25e-9"},
+		{"1e21", "1e+21"},
+		{"123456789012345678901234", "1.23456789012345678901234e+23"},
+		{"NaN", "NaN"},
+	}
+	for _, c := range cases {
+		if got := normalizeNumber(c.n); got != c.expected {
+			t.Errorf("%s: got %s, expected %s", c.n, got, c.expected)
+		}
+	}
+
+	opts := Options{NormalizeNumbers: true, Indent: " ", ChangedSeparator: " => "}
+	_, diff := Compare([]byte(`{"a": 1.50, "b": 1}`), []byte(`{"a": 1.50, "b": 2E0}`), &opts)
+	if expected := "{\n \"a\": 1.5,\n \"b\": 1 => 2\n}"; diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+}
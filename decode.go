@@ -0,0 +1,118 @@
+package jsondiff
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// decodeError is a JSON decoding error along with the byte offset in the input
+// where it happened.
+type decodeError struct {
+	err    error
+	offset int64
+}
+
+func (e *decodeError) Error() string {
+	return e.err.Error() + " (offset " + strconv.FormatInt(e.offset, 10) + ")"
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// newDecodeError returns a decoding error at the offset reported by err if it
+// has one, at the given offset otherwise.
+func newDecodeError(err error, offset int64) error {
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *EncodingError:
+		// already has an offset
+		return err
+	}
+	return &decodeError{err: err, offset: offset}
+}
+
+func decode(r io.Reader) (interface{}, error) {
+	return decodeValue(r, false)
+}
+
+// decodeValue decodes the first JSON value of the stream. When strict is true,
+// the rest of the stream must be whitespace.
+func decodeValue(r io.Reader, strict bool) (interface{}, error) {
+	var v interface{}
+	cr := &countingReader{r: r}
+	d := json.NewDecoder(cr)
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		// when it's not a syntax error, the input ended prematurely or
+		// reading failed, either way it happened at the end of what was read
+		return nil, newDecodeError(err, cr.n)
+	}
+	if strict {
+		offset := d.InputOffset()
+		br := bufio.NewReader(io.MultiReader(d.Buffered(), cr))
+		for {
+			c, err := br.ReadByte()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, newDecodeError(err, cr.n)
+			}
+			if !isSpace(c) {
+				return nil, trailingDataError(c, offset)
+			}
+			offset++
+		}
+	}
+	return v, nil
+}
+
+// trailingDataError reports a non-whitespace byte after the document, see
+// Options.Strict.
+func trailingDataError(c byte, offset int64) error {
+	return &decodeError{
+		err:    errors.New("invalid character " + strconv.QuoteRune(rune(c)) + " after top-level value"),
+		offset: offset,
+	}
+}
+
+func (ctx *context) decode(r io.Reader) (interface{}, error) {
+	switch ctx.opts.InputFormat {
+	case CBORInput:
+		return decodeBinary(r, ctx.opts.Strict, cborValue)
+	case MessagePackInput:
+		return decodeBinary(r, ctx.opts.Strict, msgpackValue)
+	}
+	if ctx.arena != nil && !ctx.opts.Lenient {
+		return ctx.arena.decode(r, ctx.opts.Strict)
+	}
+	r = newEncodingReader(r)
+	if ctx.opts.Lenient {
+		return decodeLenient(r, ctx.opts.Strict)
+	}
+	return decodeValue(r, ctx.opts.Strict)
+}
+
+func (ctx *context) invalidJsonMessage(msg string, errA, errB error) string {
+	if !ctx.opts.VerboseErrors {
+		return msg
+	}
+	if errA != nil && errB != nil {
+		return msg + ": first: " + errA.Error() + "; second: " + errB.Error()
+	} else if errA != nil {
+		return msg + ": " + errA.Error()
+	}
+	return msg + ": " + errB.Error()
+}
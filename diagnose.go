@@ -0,0 +1,116 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetRadius is the maximum number of bytes of the input shown on each side
+// of the error position in InputError.Snippet.
+const snippetRadius = 30
+
+// InputError describes where and why a document is invalid, see InputErrors.
+type InputError struct {
+	// Byte offset of the error in the document converted to UTF-8 without
+	// the byte order mark, see CheckEncoding, or in the document itself for
+	// encoding errors.
+	Offset int64
+	// Line and column of the error, starting from 1. Column counts
+	// characters. Zero for binary input formats, see Options.InputFormat,
+	// which have no snippets either.
+	Line   int
+	Column int
+	// Part of the line with the error followed by a line with a caret
+	// pointing at the error, e.g.:
+	//
+	//	{"a": tru}
+	//	         ^
+	Snippet string
+	Err     error
+}
+
+func (e *InputError) Error() string {
+	if e.Line == 0 {
+		return "offset " + strconv.FormatInt(e.Offset, 10) + ": " + e.Err.Error()
+	}
+	return "line " + strconv.Itoa(e.Line) + ", column " + strconv.Itoa(e.Column) + ": " + e.Err.Error()
+}
+
+// InputErrors decodes the documents using the options like Compare does and
+// describes why they are invalid, nil for valid documents. It's meant to be
+// called after Compare returns one of the invalid JSON verdicts, so that the
+// error can be shown along with the surrounding text.
+func InputErrors(a, b []byte, opts *Options) (*InputError, *InputError) {
+	ctx := context{opts: opts}
+	return ctx.inputError(a), ctx.inputError(b)
+}
+
+func (ctx *context) inputError(doc []byte) *InputError {
+	_, err := ctx.decode(bytes.NewReader(doc))
+	if err == nil {
+		return nil
+	}
+	if ctx.opts.InputFormat != JSONInput {
+		e := &InputError{Err: err}
+		if de, ok := err.(*decodeError); ok {
+			e.Err, e.Offset = de.err, de.offset
+		}
+		return e
+	}
+	text, encErr := CheckEncoding(doc)
+	if encErr != nil {
+		text = bytes.TrimPrefix(doc, utf8BOM)
+	}
+
+	e := &InputError{Err: err}
+	pos := len(text)
+	switch de := err.(type) {
+	case *EncodingError:
+		pos = int(de.Offset)
+	case *decodeError:
+		e.Err = de.err
+		pos = int(de.offset)
+		// syntax errors are reported after reading the offending byte
+		if _, ok := de.err.(*json.SyntaxError); ok && pos > 0 {
+			pos--
+		}
+	}
+	if pos > len(text) {
+		pos = len(text)
+	}
+	e.Offset = int64(pos)
+
+	lineStart := bytes.LastIndexByte(text[:pos], '\n') + 1
+	lineEnd := len(text)
+	if i := bytes.IndexByte(text[pos:], '\n'); i >= 0 {
+		lineEnd = pos + i
+	}
+	e.Line = bytes.Count(text[:pos], []byte("\n")) + 1
+	e.Column = utf8.RuneCount(text[lineStart:pos]) + 1
+
+	start, end := lineStart, lineEnd
+	if pos-start > snippetRadius {
+		start = pos - snippetRadius
+		for start < pos && !utf8.RuneStart(text[start]) {
+			start++
+		}
+	}
+	if end-pos > snippetRadius {
+		end = pos + snippetRadius
+		for end > pos && !utf8.RuneStart(text[end]) {
+			end--
+		}
+	}
+	// tabs are replaced, so that the caret is aligned regardless of the tab
+	// width
+	clean := func(b []byte) string {
+		return strings.ReplaceAll(strings.ToValidUTF8(string(b), "\uFFFD"), "\t", " ")
+	}
+	before := clean(text[start:pos])
+	after := strings.TrimRight(clean(text[pos:end]), "\r")
+	e.Snippet = before + after + "\n" + strings.Repeat(" ", utf8.RuneCountInString(before)) + "^"
+	return e
+}
@@ -0,0 +1,45 @@
+package jsondiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInputErrors(t *testing.T) {
+	opts := Options{}
+	errA, errB := InputErrors([]byte("{\n\t\"é\": tru}"), []byte(`{}`), &opts)
+	if errB != nil {
+		t.Errorf("got %v for a valid document", errB)
+	}
+	if errA == nil || errA.Line != 2 || errA.Column != 10 || errA.Offset != 12 {
+		t.Fatalf("unexpected error: %+v", *errA)
+	}
+	if errA.Snippet != " \"é\": tru}\n         ^" {
+		t.Errorf("unexpected snippet:\n%s", errA.Snippet)
+	}
+	if errA.Error() != "line 2, column 10: invalid character '}' in literal true (expecting 'e')" {
+		t.Errorf("unexpected message: %s", errA.Error())
+	}
+
+	long := `{"a": [` + strings.Repeat("1, ", 20) + `], "b": ` + strings.Repeat("2, ", 20) + `}`
+	_, errB = InputErrors([]byte(`{}`), []byte(long), &opts)
+	lines := strings.Split(errB.Snippet, "\n")
+	if len(lines) != 2 || len(lines[0]) != 2*snippetRadius || lines[1] != strings.Repeat(" ", snippetRadius)+"^" {
+		t.Errorf("unexpected snippet:\n%s", errB.Snippet)
+	}
+
+	_, errB = InputErrors([]byte(`{}`), []byte(`{"a": 1`), &opts)
+	if errB == nil || errB.Column != 8 || errB.Snippet != "{\"a\": 1\n       ^" {
+		t.Errorf("unexpected error at the end of input: %+v", errB)
+	}
+
+	errA, _ = InputErrors([]byte("[\"\xFF\"]"), []byte(`{}`), &opts)
+	if errA == nil || errA.Column != 3 || errA.Snippet != "[\"�\"]\n  ^" {
+		t.Errorf("unexpected encoding error: %+v", errA)
+	}
+
+	opts.Lenient = true
+	if errA, _ = InputErrors([]byte(`{a: 1,}`), []byte(`{}`), &opts); errA != nil {
+		t.Errorf("got %v for a valid lenient document", errA)
+	}
+}
@@ -0,0 +1,126 @@
+package jsondiff
+
+func (ctx *context) documentValue(d *delta) interface{} {
+	switch d.kind {
+	case deltaChanged:
+		return map[string]interface{}{"__old": d.a, "__new": d.b}
+	case deltaCollection:
+		if d.isObject() {
+			return ctx.documentObject(d)
+		}
+		return ctx.documentArray(d)
+	case deltaSkipped:
+		if d.placeholder != "" {
+			return d.placeholder
+		}
+	case deltaAdded:
+		return d.b
+	}
+	return d.a
+}
+
+func (ctx *context) documentObject(d *delta) interface{} {
+	m := make(map[string]interface{}, len(d.elems))
+	for i, e := range d.elems {
+		k := d.keys[i]
+		switch {
+		case e.kind == deltaAdded:
+			m[k+"__added"] = e.b
+		case e.kind == deltaRemoved:
+			m[k+"__deleted"] = e.a
+		case e.kind == deltaSkipped && e.placeholder != "":
+			m[k] = e.placeholder
+		case e.differs:
+			m[k] = ctx.documentValue(e)
+		case !ctx.opts.SkipMatches:
+			m[k] = e.a
+		}
+	}
+	return m
+}
+
+func (ctx *context) documentArray(d *delta) interface{} {
+	s := make([]interface{}, 0, len(d.elems))
+	for _, e := range d.elems {
+		switch {
+		case e.kind == deltaAdded:
+			s = append(s, []interface{}{"+", e.b})
+		case e.kind == deltaRemoved:
+			s = append(s, []interface{}{"-", e.a})
+		case e.kind == deltaChanged:
+			// scalar or type change inside of an array is shown as a
+			// removal followed by an addition
+			s = append(s, []interface{}{"-", e.a}, []interface{}{"+", e.b})
+		case e.kind == deltaSkipped && e.placeholder != "":
+			s = append(s, []interface{}{" ", e.placeholder})
+		case e.differs:
+			s = append(s, []interface{}{"~", ctx.documentValue(e)})
+		case ctx.opts.SkipMatches:
+			s = append(s, []interface{}{" "})
+		default:
+			s = append(s, []interface{}{" ", e.a})
+		}
+	}
+	return s
+}
+
+func (ctx *context) renderDocument(d *delta) string {
+	var v interface{}
+	skip := ctx.opts.SkipMatches && !d.differs
+	if !skip {
+		v = ctx.documentValue(d)
+	}
+	if ctx.opts.DocumentHeader {
+		var counts deltaCounts
+		counts.count(d)
+		header := map[string]interface{}{
+			"verdict": ctx.diff.String(),
+			"code":    int(ctx.diff),
+			"counts": map[string]int{
+				"changed": counts.changed,
+				"added":   counts.added,
+				"removed": counts.removed,
+			},
+			"version":     version,
+			"fingerprint": ctx.opts.Fingerprint(),
+			"diff":        v,
+		}
+		if ctx.sampled {
+			header["approximate"] = true
+		}
+		if ctx.opts.LeftLabel != "" {
+			header["left"] = ctx.opts.LeftLabel
+		}
+		if ctx.opts.RightLabel != "" {
+			header["right"] = ctx.opts.RightLabel
+		}
+		v = header
+	} else if skip {
+		return ""
+	}
+	return encodeJSON(v, ctx.opts.Prefix, ctx.opts.Indent)
+}
+
+type deltaCounts struct {
+	changed int
+	added   int
+	removed int
+	skipped int
+}
+
+func (c *deltaCounts) count(d *delta) {
+	switch d.kind {
+	case deltaChanged:
+		c.changed++
+	case deltaAdded:
+		c.added++
+	case deltaRemoved:
+		c.removed++
+	case deltaSkipped:
+		c.skipped++
+	case deltaCollection:
+		for _, e := range d.elems {
+			c.count(e)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var documentCases = []struct {
+	a           string
+	b           string
+	expected    string
+	skipMatches bool
+}{
+	{`{"b":"foo","a":[1,2,3],"c":"zoo","d":"Joe"}`, `{"a":[1,2,4,5],"b":"baz","c":"zoo"}`, `
+{
+  "a": [
+    [" ", 1],
+    [" ", 2],
+    ["-", 3],
+    ["+", 4],
+    ["+", 5]
+  ],
+  "b": {
+    "__new": "baz",
+    "__old": "foo"
+  },
+  "c": "zoo",
+  "d__deleted": "Joe"
+}
+	`, false},
+	{`{"b":"foo","a":[1,2,3],"c":"zoo","d":"Joe"}`, `{"a":[1,2,4,5],"b":"baz","c":"zoo"}`, `
+{
+  "a": [
+    [" "],
+    [" "],
+    ["-", 3],
+    ["+", 4],
+    ["+", 5]
+  ],
+  "b": {
+    "__new": "baz",
+    "__old": "foo"
+  },
+  "d__deleted": "Joe"
+}
+	`, true},
+	{`{"a":[{"foo":"bar"},{"b": "c"}]}`, `{"a":[{"foo":"bar"},{"b": "d", "e": 1}]}`, `
+{
+  "a": [
+    [" "],
+    ["~", {
+      "b": {
+        "__new": "d",
+        "__old": "c"
+      },
+      "e__added": 1
+    }]
+  ]
+}
+	`, true},
+	{`{"a":1}`, `{"a":1}`, ``, true},
+	{`1`, `"<b>"`, `{"__new": "<b>", "__old": 1}`, true},
+	{`null`, `null`, `null`, false},
+	{`null`, `null`, ``, true},
+}
+
+func TestDocumentOutput(t *testing.T) {
+	opts := Options{Format: DocumentOutput, Indent: "  "}
+	for i, c := range documentCases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			lopts := opts
+			lopts.SkipMatches = c.skipMatches
+			_, diff := Compare([]byte(c.a), []byte(c.b), &lopts)
+			var got, expected interface{}
+			if c.expected == "" {
+				if diff != "" {
+					t.Errorf("got:\n---\n%s\n---\nexpected empty output", diff)
+				}
+				return
+			}
+			if err := json.Unmarshal([]byte(diff), &got); err != nil {
+				t.Fatalf("output is not valid json: %s\n%s", err, diff)
+			}
+			if err := json.Unmarshal([]byte(strings.TrimSpace(c.expected)), &expected); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(expected) {
+				t.Errorf("got:\n---\n%s\n---\nexpected:\n---\n%s\n---\n", diff, c.expected)
+			}
+		})
+	}
+}
+
+func TestDocumentHeader(t *testing.T) {
+	opts := Options{Format: DocumentOutput, DocumentHeader: true, SkipMatches: true}
+	_, diff := Compare([]byte(`{"a": 1, "b": 2, "c": 3}`), []byte(`{"a": 2, "b": 2, "d": 4}`), &opts)
+	expected := `{"code":2,"counts":{"added":1,"changed":1,"removed":1},"diff":{"a":{"__new":2,"__old":1},"c__deleted":3,"d__added":4},"fingerprint":"` + opts.Fingerprint() + `","verdict":"NoMatch","version":"` + version + `"}`
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+
+	opts.QuickFullMatch = true
+	_, diff = Compare([]byte(`{"a": 1}`), []byte(`{"a": 1}`), &opts)
+	expected = `{"code":0,"counts":{"added":0,"changed":0,"removed":0},"diff":null,"fingerprint":"` + opts.Fingerprint() + `","verdict":"FullMatch","version":"` + version + `"}`
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+}
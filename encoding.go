@@ -0,0 +1,161 @@
+package jsondiff
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// EncodingError reports a document which isn't valid in its encoding.
+type EncodingError struct {
+	// "UTF-8" or "UTF-16".
+	Encoding string
+	// Byte offset of the first invalid byte or UTF-16 code unit, not counting
+	// the byte order mark.
+	Offset int64
+}
+
+func (e *EncodingError) Error() string {
+	return "invalid " + e.Encoding + " at offset " + strconv.FormatInt(e.Offset, 10)
+}
+
+// CheckEncoding returns the document encoded as UTF-8 without the byte order
+// mark, or an *EncodingError if it's not valid. Comparison functions perform
+// the same conversion: documents with a byte order mark are compared as if it
+// wasn't there, UTF-16 documents starting with a byte order mark or an ASCII
+// character (as JSON documents do) are converted to UTF-8 and documents which
+// aren't valid UTF-8 are invalid JSON.
+func CheckEncoding(doc []byte) ([]byte, error) {
+	if utf8.Valid(doc) && !bytes.ContainsRune(doc, 0) {
+		return bytes.TrimPrefix(doc, utf8BOM), nil
+	}
+	return io.ReadAll(newEncodingReader(bytes.NewReader(doc)))
+}
+
+// encodingReader strips the UTF-8 byte order mark and fails with an
+// *EncodingError when the stream isn't valid UTF-8.
+type encodingReader struct {
+	r io.Reader
+	// incomplete rune at the end of the previous read
+	pending []byte
+	// number of bytes returned so far
+	offset int64
+	err    error
+}
+
+func newEncodingReader(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(utf8BOM))
+	switch {
+	case bytes.HasPrefix(head, utf8BOM):
+		br.Discard(len(utf8BOM))
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		br.Discard(2)
+		return &encodingReader{r: &utf16Reader{r: br}}
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		br.Discard(2)
+		return &encodingReader{r: &utf16Reader{r: br, bigEndian: true}}
+	// without the byte order mark, UTF-16 is detected by the zero byte of
+	// the first character, which is always ASCII in JSON documents
+	case len(head) >= 2 && head[0] != 0 && head[1] == 0:
+		return &encodingReader{r: &utf16Reader{r: br}}
+	case len(head) >= 2 && head[0] == 0 && head[1] != 0:
+		return &encodingReader{r: &utf16Reader{r: br, bigEndian: true}}
+	}
+	return &encodingReader{r: br}
+}
+
+func (r *encodingReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[:0]
+	m, err := r.r.Read(p[n:])
+	n += m
+	for i := 0; i < n; {
+		if p[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+		if !utf8.FullRune(p[i:n]) && err == nil {
+			// the rest of the rune comes with the next read
+			r.pending = append(r.pending, p[i:n]...)
+			n = i
+			break
+		}
+		if c, size := utf8.DecodeRune(p[i:n]); c != utf8.RuneError || size != 1 {
+			i += size
+			continue
+		}
+		r.err = &EncodingError{Encoding: "UTF-8", Offset: r.offset + int64(i)}
+		r.offset += int64(i)
+		return i, r.err
+	}
+	r.offset += int64(n)
+	if err != nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// utf16Reader converts a UTF-16 stream to UTF-8.
+type utf16Reader struct {
+	r         *bufio.Reader
+	bigEndian bool
+	// converted bytes which didn't fit into the previous read
+	out []byte
+	// number of bytes read from r
+	offset int64
+	err    error
+}
+
+func (r *utf16Reader) unit() (rune, error) {
+	var b [2]byte
+	n, err := io.ReadFull(r.r, b[:])
+	r.offset += int64(n)
+	if err == io.ErrUnexpectedEOF {
+		return 0, &EncodingError{Encoding: "UTF-16", Offset: r.offset - int64(n)}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if r.bigEndian {
+		return rune(b[0])<<8 | rune(b[1]), nil
+	}
+	return rune(b[1])<<8 | rune(b[0]), nil
+}
+
+func (r *utf16Reader) Read(p []byte) (int, error) {
+	for len(r.out) < len(p) && r.err == nil {
+		start := r.offset
+		c, err := r.unit()
+		if err != nil {
+			r.err = err
+			break
+		}
+		if utf16.IsSurrogate(c) {
+			low, err := r.unit()
+			if err == nil {
+				c = utf16.DecodeRune(c, low)
+			}
+			if err != nil || c == utf8.RuneError {
+				r.err = &EncodingError{Encoding: "UTF-16", Offset: start}
+				break
+			}
+		}
+		var buf [utf8.UTFMax]byte
+		r.out = append(r.out, buf[:utf8.EncodeRune(buf[:], c)]...)
+	}
+	n := copy(p, r.out)
+	r.out = r.out[:copy(r.out, r.out[n:])]
+	if len(r.out) == 0 && r.err != nil {
+		return n, r.err
+	}
+	return n, nil
+}
@@ -0,0 +1,93 @@
+package jsondiff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+)
+
+func TestEncoding(t *testing.T) {
+	opts := Options{VerboseErrors: true}
+	bom := "\xEF\xBB\xBF"
+	result, msg := Compare([]byte(bom+`{"a": "é"}`), []byte(`{"a": "é"}`), &opts)
+	if result != FullMatch {
+		t.Errorf("got %s %s, expected FullMatch", result, msg)
+	}
+	opts.Lenient = true
+	if result, msg := Compare([]byte(bom+`{a: 1,}`), []byte(bom+`{"a": 1}`), &opts); result != FullMatch {
+		t.Errorf("got %s %s, expected FullMatch", result, msg)
+	}
+	opts.Lenient = false
+
+	invalid := []byte(`{"a": "` + "\xFF" + `"}`)
+	result, msg = Compare([]byte(`{}`), invalid, &opts)
+	if result != SecondArgIsInvalidJson || msg != "second argument is invalid json: invalid UTF-8 at offset 7" {
+		t.Errorf("got %s %q", result, msg)
+	}
+	opts.QuickFullMatch = true
+	if result := Verdict(invalid, invalid, &opts); result != BothArgsAreInvalidJson {
+		t.Errorf("got %s, expected BothArgsAreInvalidJson", result)
+	}
+
+	// runes split between reads
+	doc := `["` + strings.Repeat("é", 100) + `"]`
+	result, msg = CompareStreams(iotest.OneByteReader(strings.NewReader(doc)), strings.NewReader(doc), &Options{})
+	if result != FullMatch {
+		t.Errorf("got %s %s, expected FullMatch", result, msg)
+	}
+	_, msg = CompareStreams(iotest.OneByteReader(strings.NewReader(`["é`+"\xC3")), strings.NewReader(doc), &Options{VerboseErrors: true})
+	if msg != "first argument is invalid json: invalid UTF-8 at offset 4" {
+		t.Errorf("got %q", msg)
+	}
+
+	if out, err := CheckEncoding([]byte(bom + `1`)); err != nil || !bytes.Equal(out, []byte(`1`)) {
+		t.Errorf("got %q %v", out, err)
+	}
+	if _, err := CheckEncoding(invalid); err == nil || err.(*EncodingError).Offset != 7 {
+		t.Errorf("got %v, expected an error at offset 7", err)
+	}
+}
+
+func encodeUTF16(s string, bigEndian, bom bool) []byte {
+	var out []byte
+	if bom {
+		s = "\uFEFF" + s
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			out = append(out, byte(u>>8), byte(u))
+		} else {
+			out = append(out, byte(u), byte(u>>8))
+		}
+	}
+	return out
+}
+
+func TestUTF16(t *testing.T) {
+	doc := `{"a": "é😀", "b": [1, 2]}`
+	opts := Options{VerboseErrors: true}
+	for _, bigEndian := range []bool{false, true} {
+		for _, bom := range []bool{false, true} {
+			a := encodeUTF16(doc, bigEndian, bom)
+			if result, msg := Compare(a, []byte(doc), &opts); result != FullMatch {
+				t.Errorf("big endian %v, bom %v: got %s %s", bigEndian, bom, result, msg)
+			}
+			if out, err := CheckEncoding(a); err != nil || string(out) != doc {
+				t.Errorf("big endian %v, bom %v: got %q %v", bigEndian, bom, out, err)
+			}
+		}
+	}
+
+	// unpaired surrogate and odd length
+	for _, a := range [][]byte{
+		append(encodeUTF16(`["`, false, false), 0x00, 0xD8, '"', 0, ']', 0),
+		append(encodeUTF16(`[1`, false, false), ']'),
+	} {
+		result, msg := Compare(a, []byte(`[1]`), &opts)
+		if result != FirstArgIsInvalidJson || !strings.Contains(msg, "invalid UTF-16 at offset") {
+			t.Errorf("%q: got %s %s", a, result, msg)
+		}
+	}
+}
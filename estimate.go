@@ -0,0 +1,113 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// approximate number of bytes taken by a decoded value besides its contents:
+// the interface value, map entry or slice element holding it and the
+// allocation header
+const decodedValueSize = 64
+
+// number of bytes taken by a comparison result node and the pointer to it in
+// the parent collection
+var deltaSize = int(reflect.TypeOf(delta{}).Size() + reflect.TypeOf(&delta{}).Size())
+
+// DocumentStats describes the shape of a JSON document, see Estimate.
+type DocumentStats struct {
+	// Size of the document in bytes, without the byte order mark.
+	Bytes int
+	// Number of values in the document, including objects, arrays and the
+	// root value. Object keys are not counted.
+	Nodes int
+	// Maximum nesting level of objects and arrays, 0 for a scalar document.
+	Depth int
+}
+
+// Stats is the estimated cost of comparing two documents, see Estimate.
+type Stats struct {
+	First  DocumentStats
+	Second DocumentStats
+	// Approximate number of bytes allocated by the comparison of the
+	// documents, excluding rendering of the difference. It's an upper bound
+	// for most documents rather than a precise figure.
+	Memory int
+}
+
+// Estimate scans two JSON documents without decoding them and returns their
+// sizes along with the approximate memory needed to compare them, so that
+// oversized comparisons can be rejected or queued up front. Returns an error
+// if any of the documents is invalid JSON.
+func Estimate(a, b []byte) (Stats, error) {
+	var s Stats
+	var err error
+	if s.First, err = scanStats(a); err != nil {
+		return Stats{}, errors.New("jsondiff: first argument is invalid json: " + err.Error())
+	}
+	if s.Second, err = scanStats(b); err != nil {
+		return Stats{}, errors.New("jsondiff: second argument is invalid json: " + err.Error())
+	}
+	nodes := s.First.Nodes + s.Second.Nodes
+	// decoded documents keep the contents of strings and numbers, every
+	// value of both documents gets at most one comparison result node
+	s.Memory = s.First.Bytes + s.Second.Bytes + nodes*(decodedValueSize+deltaSize)
+	return s, nil
+}
+
+// scanStats validates the document and counts its values.
+func scanStats(data []byte) (DocumentStats, error) {
+	data, err := CheckEncoding(data)
+	if err != nil {
+		return DocumentStats{}, err
+	}
+	var raw json.RawMessage
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return DocumentStats{}, newDecodeError(err, int64(len(data)))
+	}
+
+	s := DocumentStats{Bytes: len(data)}
+	depth := 0
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; c {
+		case '"':
+			// the document is valid, so the string is terminated
+			for i++; data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			j := i + 1
+			for j < len(data) && isSpace(data[j]) {
+				j++
+			}
+			if j == len(data) || data[j] != ':' {
+				s.Nodes++
+			}
+		case '{', '[':
+			s.Nodes++
+			depth++
+			if depth > s.Depth {
+				s.Depth = depth
+			}
+		case '}', ']':
+			depth--
+		case ',', ':':
+		default:
+			if isSpace(c) {
+				continue
+			}
+			// number or literal, skip the rest of it
+			s.Nodes++
+			for i+1 < len(data) && !isDelimiter(data[i+1]) {
+				i++
+			}
+		}
+	}
+	return s, nil
+}
+
+func isDelimiter(c byte) bool {
+	return isSpace(c) || c == ',' || c == ']' || c == '}'
+}
@@ -0,0 +1,35 @@
+package jsondiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	s, err := Estimate(
+		[]byte(`{"a": [1, -2.5e3, true], "b\"c": {"d": null, "e": "x:y"}}`),
+		[]byte(`"foo"`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.First.Nodes != 8 || s.First.Depth != 2 || s.First.Bytes != 57 {
+		t.Errorf("unexpected first document stats: %+v", s.First)
+	}
+	if s.Second.Nodes != 1 || s.Second.Depth != 0 || s.Second.Bytes != 5 {
+		t.Errorf("unexpected second document stats: %+v", s.Second)
+	}
+
+	large := []byte("[" + strings.Repeat("1,", 1000) + "1]")
+	l, err := Estimate(large, large)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.First.Nodes != 1002 || l.Memory <= s.Memory {
+		t.Errorf("unexpected stats of large documents: %+v", l)
+	}
+
+	if _, err := Estimate([]byte(`{}`), []byte(`{"a" 1}`)); err == nil || !strings.HasPrefix(err.Error(), "jsondiff: second argument") {
+		t.Errorf("got %v, expected an error for the second argument", err)
+	}
+}
@@ -0,0 +1,275 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// maxEventLine limits the length of a line of a server-sent events stream.
+const maxEventLine = 64 << 20
+
+// StreamFormat is a format of the event streams compared by
+// CompareEventStreams.
+type StreamFormat int
+
+const (
+	// Server-sent events, every event with JSON data is a JSON document, see
+	// ExtractJSON.
+	EventStreamFormat StreamFormat = iota
+	// JSON documents following each other, optionally separated by
+	// whitespace, e.g. newline-delimited JSON or chunked JSON responses.
+	JSONStreamFormat
+)
+
+// StreamOptions configures CompareEventStreams.
+type StreamOptions struct {
+	// Options of the comparison of events.
+	Options *Options
+	// Format of both streams.
+	Format StreamFormat
+	// Path of the value identifying an event, in the syntax of
+	// Options.FirstRoot, e.g. "id" or "meta.sequence". Events are paired by
+	// position if empty.
+	IDPath string
+}
+
+// EventDiff is the comparison result of a pair of events, see
+// CompareEventStreams.
+type EventDiff struct {
+	// Identifier of the events, empty if events are paired by position or
+	// the event has no identifier.
+	ID string
+	// Positions of the events in the first and the second streams, -1 if the
+	// event is missing in the corresponding stream.
+	A, B int
+	// Verdict of the events, an event present only in the first stream is a
+	// SupersetMatch, an event present only in the second one is a NoMatch.
+	Difference Difference
+	// Rendering of the difference of the events, an event present in one
+	// stream only is rendered as removed or added.
+	Diff string
+}
+
+// StreamReport is the result of CompareEventStreams.
+type StreamReport struct {
+	// Verdict of the whole comparison, including the order of events.
+	Difference Difference
+	// Comparison results of the events in the order of the first stream,
+	// followed by the events present only in the second stream.
+	Events []EventDiff
+	// Identifiers of the events present in both streams which are out of
+	// order in the second stream, in the order of the first stream. The
+	// fewest events are reported, so that moving them makes the order of
+	// the streams consistent.
+	Reordered []string
+}
+
+// CompareEventStreams compares two streams of JSON events, e.g. responses of
+// a streaming API and its rewrite. Both streams are consumed concurrently
+// until the end, then events are paired either by position or by the
+// identifier at StreamOptions.IDPath, and every pair is compared according
+// to StreamOptions.Options. Events without an identifier or with a duplicate
+// one are never paired. Returns an error if reading any of the streams fails
+// or an event of a JSON stream is invalid.
+func CompareEventStreams(a, b io.Reader, opts *StreamOptions) (*StreamReport, error) {
+	type result struct {
+		events []interface{}
+		err    error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		events, err := readEvents(b, opts)
+		ch <- result{events, err}
+	}()
+	av, errA := readEvents(a, opts)
+	res := <-ch
+	if errA != nil {
+		return nil, errA
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	bv := res.events
+
+	report := &StreamReport{}
+	if opts.IDPath == "" {
+		for i := 0; i < len(av) || i < len(bv); i++ {
+			e := EventDiff{A: i, B: i}
+			if i >= len(av) {
+				e.A = -1
+			}
+			if i >= len(bv) {
+				e.B = -1
+			}
+			report.add(e, av, bv, opts.Options)
+		}
+		return report, nil
+	}
+
+	ids := make(map[string]int)
+	aIDs := eventIDs(av, opts.IDPath, ids, 1)
+	bIDs := eventIDs(bv, opts.IDPath, ids, 2)
+	bIndex := make(map[string]int, len(bv))
+	for i, id := range bIDs {
+		if ids[id] == 3 {
+			bIndex[id] = i
+		}
+	}
+	var paired []int
+	for i, id := range aIDs {
+		e := EventDiff{ID: id, A: i, B: -1}
+		if j, ok := bIndex[id]; ok {
+			e.B = j
+			paired = append(paired, len(report.Events))
+		}
+		report.add(e, av, bv, opts.Options)
+	}
+	for j, id := range bIDs {
+		if _, ok := bIndex[id]; !ok {
+			report.add(EventDiff{ID: id, A: -1, B: j}, av, bv, opts.Options)
+		}
+	}
+
+	positions := make([]int, len(paired))
+	for i, k := range paired {
+		positions[i] = report.Events[k].B
+	}
+	inOrder := increasingSubsequence(positions)
+	for i, k := range paired {
+		if !inOrder[i] {
+			report.Reordered = append(report.Reordered, report.Events[k].ID)
+		}
+	}
+	if len(report.Reordered) != 0 {
+		report.Difference = NoMatch
+	}
+	return report, nil
+}
+
+// readEvents reads all events of the stream.
+func readEvents(r io.Reader, opts *StreamOptions) ([]interface{}, error) {
+	var docs []json.RawMessage
+	if opts.Format == EventStreamFormat {
+		var err error
+		if docs, err = readEventStream(r, maxEventLine); err != nil {
+			return nil, err
+		}
+	} else {
+		d := json.NewDecoder(r)
+		for {
+			var doc json.RawMessage
+			err := d.Decode(&doc)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, doc)
+		}
+	}
+	ctx := context{opts: opts.Options}
+	events := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		v, err := ctx.decode(bytes.NewReader(doc))
+		if err != nil {
+			return nil, err
+		}
+		events[i] = v
+	}
+	return events, nil
+}
+
+// eventIDs returns identifiers of the events, empty for events without one.
+// Every identifier is counted in ids by or-ing the side bit, identifiers
+// duplicated within a stream are marked with 4, so that 3 means the events
+// can be paired.
+func eventIDs(events []interface{}, path string, ids map[string]int, side int) []string {
+	out := make([]string, len(events))
+	for i, v := range events {
+		id, ok := "", false
+		if kv, found := lookupPath(v, splitPath(path)); found {
+			id, ok = keyString(kv)
+		}
+		if !ok {
+			continue
+		}
+		out[i] = id
+		if ids[id]&side != 0 {
+			ids[id] |= 4
+		}
+		ids[id] |= side
+	}
+	return out
+}
+
+// add compares the events of e and appends the result to the report.
+func (r *StreamReport) add(e EventDiff, av, bv []interface{}, opts *Options) {
+	var a, b interface{}
+	if e.A >= 0 {
+		a = av[e.A]
+	}
+	if e.B >= 0 {
+		b = bv[e.B]
+	}
+	e.Difference, e.Diff = compareItems(a, e.A >= 0, b, e.B >= 0, opts)
+	r.Events = append(r.Events, e)
+	r.Difference = combineDifferences(r.Difference, e.Difference)
+}
+
+// compareItems compares two items of collections paired by the caller, e.g.
+// events or rows. An item present only in the first collection is a
+// SupersetMatch rendered as removed, an item present only in the second one
+// is a NoMatch rendered as added.
+func compareItems(a interface{}, aOK bool, b interface{}, bOK bool, opts *Options) (Difference, string) {
+	ctx := context{opts: opts}
+	var d *delta
+	switch {
+	case !bOK:
+		ctx.diff = SupersetMatch
+		d = ctx.newDelta(delta{kind: deltaRemoved, a: a, differs: true})
+	case !aOK:
+		ctx.diff = NoMatch
+		d = ctx.newDelta(delta{kind: deltaAdded, b: b, differs: true})
+	default:
+		d = ctx.compareRoots(a, b)
+	}
+	return ctx.diff, ctx.render(d)
+}
+
+// combineDifferences returns the verdict of two comparisons combined.
+func combineDifferences(total, d Difference) Difference {
+	ctx := context{diff: total}
+	ctx.result(d)
+	return ctx.diff
+}
+
+// increasingSubsequence returns which of the values belong to a longest
+// increasing subsequence of them.
+func increasingSubsequence(values []int) []bool {
+	// tails[k] is the index of the smallest tail of an increasing
+	// subsequence of length k+1, prev links the subsequences.
+	var tails []int
+	prev := make([]int, len(values))
+	for i, v := range values {
+		k := sort.Search(len(tails), func(k int) bool { return values[tails[k]] >= v })
+		prev[i] = -1
+		if k > 0 {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	in := make([]bool, len(values))
+	if len(tails) != 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+			in[i] = true
+		}
+	}
+	return in
+}
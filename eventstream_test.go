@@ -0,0 +1,58 @@
+package jsondiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareEventStreams(t *testing.T) {
+	opts := Options{Added: Tag{Begin: "+"}, Removed: Tag{Begin: "-"}, Indent: "    "}
+	report, err := CompareEventStreams(
+		strings.NewReader("data: {\"n\": 1}\n\ndata: {\"n\": 2}\n\n"),
+		strings.NewReader("data: {\"n\": 1}\n\ndata: {\"n\": 3}\n\ndata: [DONE]\n\n"),
+		&StreamOptions{Options: &opts},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Difference != NoMatch || len(report.Events) != 2 ||
+		report.Events[0].Difference != FullMatch || report.Events[1].Difference != NoMatch {
+		t.Errorf("got %+v, expected a mismatch of the second event", report)
+	}
+
+	report, err = CompareEventStreams(
+		strings.NewReader(`{"id": 1, "v": "a"} {"id": 2} {"id": 3} {"id": 4}`),
+		strings.NewReader("{\"id\": 2}\n{\"id\": 1, \"v\": \"a\"}\n{\"id\": 3}\n{\"id\": 5}\n"),
+		&StreamOptions{Options: &opts, Format: JSONStreamFormat, IDPath: "id"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range report.Events {
+		got = append(got, e.ID+":"+e.Difference.String())
+	}
+	expected := []string{"1:FullMatch", "2:FullMatch", "3:FullMatch", "4:SupersetMatch", "5:NoMatch"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got events %v, expected %v", got, expected)
+	}
+	if !reflect.DeepEqual(report.Reordered, []string{"1"}) || report.Difference != NoMatch {
+		t.Errorf("got reordered %v, %s, expected [1], NoMatch", report.Reordered, report.Difference)
+	}
+	if e := report.Events[4]; e.A != -1 || e.B != 3 || e.Diff != "+{\n    +\"id\": 5\n+}" {
+		t.Errorf("got %+v, expected an added event", e)
+	}
+
+	if _, err := CompareEventStreams(strings.NewReader("{"), strings.NewReader("{}"),
+		&StreamOptions{Options: &opts, Format: JSONStreamFormat}); err == nil {
+		t.Errorf("expected an error for an invalid stream")
+	}
+}
+
+func TestIncreasingSubsequence(t *testing.T) {
+	got := increasingSubsequence([]int{1, 0, 2, 3})
+	if expected := []bool{false, true, true, true}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
@@ -0,0 +1,85 @@
+// Package expect builds expected documents for jsondiff.CompareInterfaces
+// with matchers embedded as values, e.g.:
+//
+//	expected := expect.Obj().
+//		Key("id", expect.UUID()).
+//		Key("items", expect.ArrayOfLen(3))
+//	result, diff := jsondiff.CompareInterfaces(actual, expected, &opts)
+//
+// Matchers are jsondiff.Matcher values, so semantics of the expectations
+// don't have to be encoded in strings.
+package expect
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+
+	"github.com/nsf/jsondiff"
+)
+
+// Object is an expected JSON object.
+type Object map[string]interface{}
+
+// Obj returns an empty expected object.
+func Obj() Object {
+	return Object{}
+}
+
+// Key sets the expected value of the property and returns the object, so
+// that calls can be chained. The value may be a matcher, a nested Object or
+// Array, or any value which can be encoded as JSON.
+func (o Object) Key(key string, v interface{}) Object {
+	o[key] = v
+	return o
+}
+
+// Array is an expected JSON array.
+type Array []interface{}
+
+// Arr returns an expected array of the given elements, see Object.Key for
+// the allowed values.
+func Arr(elems ...interface{}) Array {
+	return Array(elems)
+}
+
+// Any matches any value, hence it only checks presence of an object property
+// or an array element.
+func Any() jsondiff.Matcher {
+	return jsondiff.MatchFunc("<any>", func(interface{}) bool { return true })
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// UUID matches strings which are UUIDs in the canonical textual form.
+func UUID() jsondiff.Matcher {
+	return jsondiff.MatchFunc("<uuid>", func(v interface{}) bool {
+		s, ok := v.(string)
+		return ok && uuidRegexp.MatchString(s)
+	})
+}
+
+// String matches strings matched by the regular expression.
+func String(re *regexp.Regexp) jsondiff.Matcher {
+	return jsondiff.MatchFunc("<string matching "+strconv.Quote(re.String())+">", func(v interface{}) bool {
+		s, ok := v.(string)
+		return ok && re.MatchString(s)
+	})
+}
+
+// Number matches any number.
+func Number() jsondiff.Matcher {
+	return jsondiff.MatchFunc("<number>", func(v interface{}) bool {
+		_, ok := v.(json.Number)
+		return ok
+	})
+}
+
+// ArrayOfLen matches arrays of exactly n elements, regardless of their
+// values.
+func ArrayOfLen(n int) jsondiff.Matcher {
+	return jsondiff.MatchFunc("<array of length "+strconv.Itoa(n)+">", func(v interface{}) bool {
+		a, ok := v.([]interface{})
+		return ok && len(a) == n
+	})
+}
@@ -0,0 +1,49 @@
+package expect
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/nsf/jsondiff"
+)
+
+func TestBuilder(t *testing.T) {
+	expected := Obj().
+		Key("id", UUID()).
+		Key("name", String(regexp.MustCompile(`^J`))).
+		Key("items", ArrayOfLen(3)).
+		Key("tags", Arr("a", Any())).
+		Key("owner", Obj().Key("age", Number()))
+
+	opts := jsondiff.DefaultJSONOptions()
+	opts.SkipMatches = true
+	cases := []struct {
+		actual string
+		result jsondiff.Difference
+		diff   string
+	}{
+		{`{"id": "9b2c7d4e-0f1a-4b3c-8d5e-6f7a8b9c0d1e", "name": "Joe", "items": [1, 2, 3], "tags": ["a", {}], "owner": {"age": 42}}`, jsondiff.FullMatch, ``},
+		{`{"id": "9b2c7d4e-0f1a-4b3c-8d5e-6f7a8b9c0d1e", "name": "Joe", "items": [1, 2, 3], "tags": ["a", null, "c"], "owner": {"age": 42}, "x": 1}`, jsondiff.SupersetMatch, ""},
+		{`{"id": 7, "name": "Joe", "items": [1, 2], "tags": ["a"], "owner": {"age": "42"}}`, jsondiff.NoMatch, `{
+    "id": {"changed":[7, <uuid>]},
+    "items": {"changed":[[], <array of length 3>]},
+    "owner": {
+        "age": {"changed":["42", <number>]}
+    },
+    "tags": [
+        "prop-added":{<any>}
+    ]
+}`},
+	}
+	for _, c := range cases {
+		var actual interface{}
+		if err := json.Unmarshal([]byte(c.actual), &actual); err != nil {
+			t.Fatal(err)
+		}
+		result, diff := jsondiff.CompareInterfaces(actual, expected, &opts)
+		if result != c.result || (c.diff != "" && diff != c.diff) {
+			t.Errorf("%s: got %s:\n%s\nexpected %s:\n%s", c.actual, result, diff, c.result, c.diff)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package jsondiff
+
+import (
+	"bytes"
+)
+
+// Expectation is the verdict of CompareExpectedActual, phrased from the
+// perspective of a test checking the actual document against the expected
+// one.
+type Expectation int
+
+const (
+	// Actual document is equal to the expected one.
+	ExpectationMet Expectation = iota
+	// Actual document has object properties or array elements which are
+	// not expected, everything expected is present and equal. It's the
+	// SupersetMatch verdict of Compare.
+	UnexpectedFields
+	// Some of the expected object properties or array elements are missing
+	// in the actual document, and values present in both are equal.
+	MissingFields
+	// Some values are present in both documents but differ. Takes precedence
+	// over missing and unexpected fields.
+	ValueMismatch
+	ExpectedIsInvalidJson
+	ActualIsInvalidJson
+	BothAreInvalidJson
+)
+
+func (e Expectation) String() string {
+	switch e {
+	case ExpectationMet:
+		return "ExpectationMet"
+	case UnexpectedFields:
+		return "UnexpectedFields"
+	case MissingFields:
+		return "MissingFields"
+	case ValueMismatch:
+		return "ValueMismatch"
+	case ExpectedIsInvalidJson:
+		return "ExpectedIsInvalidJson"
+	case ActualIsInvalidJson:
+		return "ActualIsInvalidJson"
+	case BothAreInvalidJson:
+		return "BothAreInvalidJson"
+	}
+	return "Invalid"
+}
+
+// expectation derives the verdict from the reasons of the comparison of the
+// actual document against the expected one.
+func expectation(reasons []Reason) Expectation {
+	e := ExpectationMet
+	for _, r := range reasons {
+		switch r.Kind {
+		case ValueChanged, CollectionNotEmpty:
+			return ValueMismatch
+		case ValueAdded:
+			e = MissingFields
+		case ValueRemoved:
+			if e == ExpectationMet {
+				e = UnexpectedFields
+			}
+		}
+	}
+	return e
+}
+
+// CompareExpectedActual compares the actual document against the expected
+// one, like Compare(actual, expected, opts), but phrases the verdict from the
+// testing perspective. As far as options are concerned, the actual document
+// is the first one and the expected document is the second one, e.g.
+// FirstRoot selects the root of the actual document, while placeholders and
+// optional keys apply to the expected one.
+//
+// The text output starts with two lines naming the sides: "--- actual"
+// highlighted with the Removed tag and "+++ expected" highlighted with the
+// Added tag, so values present only in the actual document are removed ones
+// and values missing in it are added ones, while changed values are printed
+// as actual, then expected. Other formats are rendered as usual. When any of
+// the documents is invalid JSON, the returned string is an error message.
+func CompareExpectedActual(expected, actual []byte, opts *Options) (Expectation, string) {
+	ctx := context{opts: opts, collectReasons: true}
+	av, errA := ctx.decode(bytes.NewReader(actual))
+	ev, errE := ctx.decode(bytes.NewReader(expected))
+	switch {
+	case errA != nil && errE != nil:
+		msg := "expected and actual documents are invalid json"
+		if opts.VerboseErrors {
+			msg += ": expected: " + errE.Error() + "; actual: " + errA.Error()
+		}
+		return BothAreInvalidJson, msg
+	case errE != nil:
+		msg := "expected document is invalid json"
+		if opts.VerboseErrors {
+			msg += ": " + errE.Error()
+		}
+		return ExpectedIsInvalidJson, msg
+	case errA != nil:
+		msg := "actual document is invalid json"
+		if opts.VerboseErrors {
+			msg += ": " + errA.Error()
+		}
+		return ActualIsInvalidJson, msg
+	}
+
+	d := ctx.compareRoots(av, ev)
+	s := ctx.render(d)
+	if opts.Format != TextOutput || s == "" {
+		return expectation(ctx.reasons), s
+	}
+	legend := opts.Removed.Begin + "--- actual" + opts.Removed.End + "\n" + opts.Prefix +
+		opts.Added.Begin + "+++ expected" + opts.Added.End + "\n" + opts.Prefix
+	return expectation(ctx.reasons), legend + s
+}
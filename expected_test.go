@@ -0,0 +1,45 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+func TestCompareExpectedActual(t *testing.T) {
+	cases := []struct {
+		expected string
+		actual   string
+		result   Expectation
+	}{
+		{`{"a": 1}`, `{"a": 1}`, ExpectationMet},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, UnexpectedFields},
+		{`{"a": 1, "b": 2}`, `{"a": 1}`, MissingFields},
+		{`{"a": 1, "b": 2}`, `{"a": 1, "c": 3}`, MissingFields},
+		{`{"a": 1, "b": 2}`, `{"a": 2, "c": 3}`, ValueMismatch},
+		{`[1, 2]`, `[1, 2, 3]`, UnexpectedFields},
+		{`{"a": 1}`, `{`, ActualIsInvalidJson},
+		{`{`, `{"a": 1}`, ExpectedIsInvalidJson},
+		{`{`, `}`, BothAreInvalidJson},
+	}
+	opts := Options{}
+	for i, c := range cases {
+		if result, _ := CompareExpectedActual([]byte(c.expected), []byte(c.actual), &opts); result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s", i, result, c.result)
+		}
+	}
+
+	opts = Options{
+		Indent:           " ",
+		ChangedSeparator: " => ",
+		Added:            Tag{Begin: "+"},
+		Removed:          Tag{Begin: "-"},
+		SkipMatches:      true,
+	}
+	_, diff := CompareExpectedActual([]byte(`{"a": 1, "b": 2}`), []byte(`{"a": 3, "c": 4}`), &opts)
+	expected := "---- actual\n++++ expected\n{\n \"a\": 3 => 1,\n +\"b\": 2,\n -\"c\": 4\n}"
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+	if _, diff := CompareExpectedActual([]byte(`{}`), []byte(`{}`), &opts); diff != "" {
+		t.Errorf("got %q, expected no output", diff)
+	}
+}
@@ -0,0 +1,68 @@
+package jsondiff
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"io"
+)
+
+// exportValues returns old and new values of the change encoded as JSON, or
+// empty strings for values missing in the corresponding documents.
+func exportValues(c *Change) (string, string) {
+	var ov, nv string
+	if c.Kind != ChangeAdded {
+		ov = encodeJSON(c.Old, "", "")
+	}
+	if c.Kind != ChangeRemoved {
+		nv = encodeJSON(c.New, "", "")
+	}
+	return ov, nv
+}
+
+// WriteCSV writes changes as CSV with a header and columns path, op, old and
+// new, see CompareWithChanges. Op is one of "added", "removed", "modified" and
+// "unchanged", values are encoded as JSON, values missing in the corresponding
+// documents are empty.
+func WriteCSV(w io.Writer, changes []Change) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"path", "op", "old", "new"}); err != nil {
+		return err
+	}
+	for i := range changes {
+		c := &changes[i]
+		ov, nv := exportValues(c)
+		if err := cw.Write([]string{c.Path, c.Kind.name(), ov, nv}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Execer executes SQL statements, it's implemented by *sql.DB and *sql.Tx.
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// InsertChanges executes the given query once per change with arguments path,
+// op, old and new, as described in WriteCSV, except that missing values are
+// NULL. The query is usually an INSERT statement with four placeholders in the
+// syntax of the database driver, e.g.:
+//
+//	INSERT INTO changes (path, op, ov, nv) VALUES (?, ?, ?, ?)
+//
+// Use a transaction to insert a batch of changes atomically. Stops at the first
+// error and returns it.
+func InsertChanges(db Execer, query string, changes []Change) error {
+	for i := range changes {
+		c := &changes[i]
+		var ov, nv sql.NullString
+		ov.String, nv.String = exportValues(c)
+		ov.Valid = c.Kind != ChangeAdded
+		nv.Valid = c.Kind != ChangeRemoved
+		if _, err := db.Exec(query, c.Path, c.Kind.name(), ov, nv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package jsondiff
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var exportChanges = []Change{
+	{Path: "/a", Kind: ChangeModified, Old: json.Number("1"), New: "x,y"},
+	{Path: "/b", Kind: ChangeAdded, New: nil},
+	{Path: "/c", Kind: ChangeRemoved, Old: map[string]interface{}{"d": true}},
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, exportChanges); err != nil {
+		t.Fatal(err)
+	}
+	expected := "path,op,old,new\n" +
+		"/a,modified,1,\"\"\"x,y\"\"\"\n" +
+		"/b,added,,null\n" +
+		"/c,removed,\"{\"\"d\"\":true}\",\n"
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+type execRecorder struct {
+	args [][]interface{}
+	err  error
+}
+
+func (r *execRecorder) Exec(query string, args ...interface{}) (sql.Result, error) {
+	r.args = append(r.args, args)
+	return nil, r.err
+}
+
+func TestInsertChanges(t *testing.T) {
+	var r execRecorder
+	if err := InsertChanges(&r, "INSERT", exportChanges); err != nil {
+		t.Fatal(err)
+	}
+	null := sql.NullString{}
+	str := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+	expected := [][]interface{}{
+		{"/a", "modified", str("1"), str(`"x,y"`)},
+		{"/b", "added", null, str("null")},
+		{"/c", "removed", str(`{"d":true}`), null},
+	}
+	if !reflect.DeepEqual(r.args, expected) {
+		t.Errorf("got %v, expected %v", r.args, expected)
+	}
+
+	r = execRecorder{err: errors.New("fail")}
+	if err := InsertChanges(&r, "INSERT", exportChanges); err == nil || len(r.args) != 1 {
+		t.Errorf("expected to stop at the first error, got %v after %d calls", err, len(r.args))
+	}
+}
@@ -0,0 +1,114 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// ExtendedJSON returns a normalizer of MongoDB Extended JSON
+// (https://www.mongodb.com/docs/manual/reference/mongodb-extended-json/), so
+// that documents exported in the canonical or the relaxed mode compare equal
+// to plain JSON documents. Objects which consist of a single type wrapper
+// are replaced with plain values:
+//
+//   - {"$oid": "..."} and {"$symbol": "..."} become strings.
+//   - {"$numberInt": "1"}, {"$numberLong": "1"}, {"$numberDouble": "1.5"} and
+//     {"$numberDecimal": "1.5"} become numbers, non-finite doubles are
+//     represented as by Options.Lenient.
+//   - {"$date": ...} becomes an RFC 3339 string in UTC with as many
+//     fractional digits as needed, e.g. "2024-01-02T03:04:05.5Z", the date
+//     can be a string, milliseconds since the epoch or a $numberLong.
+//   - {"$binary": {"base64": "...", "subType": "00"}} and the legacy
+//     {"$binary": "...", "$type": "00"} become base64 strings.
+//   - {"$undefined": true} becomes null.
+//
+// Other wrappers, e.g. $timestamp or $regularExpression, and wrappers with
+// invalid contents are left intact. Plain strings are not reformatted, so
+// dates of the other document have to use the same format.
+func ExtendedJSON() Normalizer {
+	return func(path string, v interface{}) interface{} {
+		obj, ok := v.(map[string]interface{})
+		if !ok || len(obj) > 2 {
+			return v
+		}
+		if len(obj) == 2 {
+			data, okData := obj["$binary"].(string)
+			_, okType := obj["$type"].(string)
+			if okData && okType {
+				return data
+			}
+			return v
+		}
+		for k, w := range obj {
+			if nv, ok := extendedValue(k, w); ok {
+				return nv
+			}
+		}
+		return v
+	}
+}
+
+// extendedValue returns the plain value of a single key type wrapper.
+func extendedValue(k string, w interface{}) (interface{}, bool) {
+	switch k {
+	case "$oid", "$symbol":
+		s, ok := w.(string)
+		return s, ok
+	case "$numberInt", "$numberLong", "$numberDecimal", "$numberDouble":
+		s, ok := w.(string)
+		if !ok {
+			return nil, false
+		}
+		switch s {
+		case "NaN", "Infinity", "-Infinity":
+			return json.Number(s), k != "$numberInt" && k != "$numberLong"
+		}
+		return json.Number(s), isJSONNumber(s)
+	case "$date":
+		return extendedDate(w)
+	case "$binary":
+		b, ok := w.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		data, ok := b["base64"].(string)
+		return data, ok
+	case "$undefined":
+		return nil, w == true
+	}
+	return nil, false
+}
+
+// isJSONNumber tells whether the string is a number in the JSON syntax.
+func isJSONNumber(s string) bool {
+	return s != "" && (s[0] == '-' || s[0] >= '0' && s[0] <= '9') && json.Valid([]byte(s))
+}
+
+func extendedDate(w interface{}) (interface{}, bool) {
+	var ms int64
+	switch d := w.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, d)
+		if err != nil {
+			return nil, false
+		}
+		return t.UTC().Format(time.RFC3339Nano), true
+	case json.Number:
+		n, err := d.Int64()
+		if err != nil {
+			return nil, false
+		}
+		ms = n
+	case map[string]interface{}:
+		s, ok := d["$numberLong"].(string)
+		n, err := strconv.ParseInt(s, 10, 64)
+		if !ok || len(d) != 1 || err != nil {
+			return nil, false
+		}
+		ms = n
+	default:
+		return nil, false
+	}
+	return time.Unix(ms/1000, ms%1000*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano), true
+}
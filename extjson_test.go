@@ -0,0 +1,51 @@
+package jsondiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtendedJSON(t *testing.T) {
+	a := []byte(`{
+		"_id": {"$oid": "5d505646cf6d4fe581014ab2"},
+		"count": {"$numberLong": "42"},
+		"price": {"$numberDecimal": "9.99"},
+		"ratio": {"$numberDouble": "Infinity"},
+		"created": {"$date": {"$numberLong": "1704164645500"}},
+		"updated": {"$date": "2024-01-02T05:04:05.500+02:00"},
+		"blob": {"$binary": {"base64": "AQI=", "subType": "00"}},
+		"legacy": {"$binary": "AQI=", "$type": "00"},
+		"ts": {"$timestamp": {"t": 1, "i": 2}}
+	}`)
+	b := []byte(`{
+		"_id": "5d505646cf6d4fe581014ab2",
+		"count": 42,
+		"price": 9.99,
+		"ratio": Infinity,
+		"created": "2024-01-02T03:04:05.5Z",
+		"updated": "2024-01-02T03:04:05.5Z",
+		"blob": "AQI=",
+		"legacy": "AQI=",
+		"ts": {"$timestamp": {"t": 1, "i": 2}}
+	}`)
+	opts, _ := PresetOptions("extjson")
+	opts.Lenient = true
+	if result, diff := Compare(a, b, &opts); result != FullMatch {
+		t.Errorf("got %s, expected FullMatch:\n%s", result, diff)
+	}
+
+	normalize := ExtendedJSON()
+	for _, doc := range []string{
+		`{"$numberInt": "NaN"}`,
+		`{"$numberLong": "0x10"}`,
+		`{"$date": "yesterday"}`,
+		`{"$oid": 1}`,
+		`{"$oid": "a", "b": 1}`,
+	} {
+		v, _ := decode(strings.NewReader(doc))
+		if got := normalize("", v); !reflect.DeepEqual(got, v) {
+			t.Errorf("got %v, expected %s intact", got, doc)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package jsondiff
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+)
+
+// isJSONMediaType tells whether the media type is application/json or a
+// structured syntax suffix of it, e.g. application/problem+json.
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// ExtractJSON extracts JSON documents from an HTTP body according to its
+// content type, so that bodies of streaming or multipart responses can be
+// compared:
+//
+//   - For multipart types, parts with JSON content types are extracted.
+//   - For text/event-stream, data of the server-sent events is extracted,
+//     data spanning several "data:" lines is joined with newlines. Events
+//     without data and data which is not JSON, e.g. "[DONE]", are ignored.
+//
+// A single extracted document is returned as is, several ones are returned
+// as an array in the order of the body. Bodies of other content types are
+// returned as is. Returns an error if the body can't be parsed or no JSON
+// documents are found.
+func ExtractJSON(contentType string, body []byte) ([]byte, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body, nil
+	}
+	var docs []json.RawMessage
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		docs, err = multipartJSON(body, params["boundary"])
+	case mediaType == "text/event-stream":
+		docs, err = readEventStream(bytes.NewReader(body), len(body)+1)
+	default:
+		return body, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch len(docs) {
+	case 0:
+		return nil, errors.New("jsondiff: no json documents in " + mediaType + " body")
+	case 1:
+		return docs[0], nil
+	}
+	return json.Marshal(docs)
+}
+
+func multipartJSON(body []byte, boundary string) ([]json.RawMessage, error) {
+	if boundary == "" {
+		return nil, errors.New("jsondiff: multipart boundary is missing")
+	}
+	var docs []json.RawMessage
+	mr := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		mediaType, _, err := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		if err != nil || !isJSONMediaType(mediaType) {
+			continue
+		}
+		data, err := io.ReadAll(p)
+		if err != nil {
+			return nil, err
+		}
+		if !json.Valid(data) {
+			return nil, errors.New("jsondiff: multipart part is invalid json")
+		}
+		docs = append(docs, data)
+	}
+}
+
+// readEventStream returns JSON data of the server-sent events, maxLine limits
+// the length of a line of the stream.
+func readEventStream(r io.Reader, maxLine int) ([]json.RawMessage, error) {
+	var docs []json.RawMessage
+	var data []string
+	dispatch := func() {
+		if doc := []byte(strings.Join(data, "\n")); len(data) != 0 && json.Valid(doc) {
+			docs = append(docs, doc)
+		}
+		data = data[:0]
+	}
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, maxLine)
+	for s.Scan() {
+		line := strings.TrimSuffix(s.Text(), "\r")
+		if line == "" {
+			dispatch()
+			continue
+		}
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		if field == "data" {
+			data = append(data, value)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	dispatch()
+	return docs, nil
+}
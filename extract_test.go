@@ -0,0 +1,38 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+func TestExtractJSON(t *testing.T) {
+	multipartBody := "--xyz\r\n" +
+		"Content-Type: text/plain\r\n\r\n" +
+		"hello\r\n" +
+		"--xyz\r\n" +
+		"Content-Type: application/json; charset=utf-8\r\n\r\n" +
+		"{\"a\": 1}\r\n" +
+		"--xyz--\r\n"
+	cases := []struct {
+		contentType string
+		body        string
+		expected    string
+	}{
+		{"application/json", `{"a": 1}`, `{"a": 1}`},
+		{"multipart/mixed; boundary=xyz", multipartBody, `{"a": 1}`},
+		{"text/event-stream", "event: x\ndata: {\"a\":\ndata: 1}\n\n: comment\ndata: [2]\n\ndata: [DONE]\n\n", `[{"a":1},[2]]`},
+		{"text/event-stream", "data: {\"a\": 1}", `{"a": 1}`},
+	}
+	for i, c := range cases {
+		got, err := ExtractJSON(c.contentType, []byte(c.body))
+		if err != nil || string(got) != c.expected {
+			t.Errorf("case %d failed, got: %s, %v, expected: %s", i, got, err, c.expected)
+		}
+	}
+
+	if _, err := ExtractJSON("text/event-stream", []byte("data: [DONE]\n\n")); err == nil {
+		t.Errorf("expected an error for a stream without json")
+	}
+	if _, err := ExtractJSON("multipart/mixed", []byte(multipartBody)); err == nil {
+		t.Errorf("expected an error for a missing boundary")
+	}
+}
@@ -0,0 +1,79 @@
+package jsondiff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"regexp"
+)
+
+// options which don't affect results of comparisons and aren't fingerprinted
+var fingerprintIgnored = map[string]bool{
+	"Trace": true,
+}
+
+// Fingerprint returns a hash of the options, so that stored results can
+// record which comparison semantics produced them. Options with equal fields
+// have equal fingerprints across runs, and fields with zero values don't
+// affect it, so that it doesn't change when new options are added. Functions
+// can't be compared, so only their presence is fingerprinted, and only dynamic
+// types of interface values, e.g. Labels.
+func (opts Options) Fingerprint() string {
+	fields := make(map[string]interface{})
+	v := reflect.ValueOf(opts)
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		if f := v.Field(i); !f.IsZero() && !fingerprintIgnored[name] {
+			fields[name] = fingerprintValue(f)
+		}
+	}
+	// maps are encoded with sorted keys
+	data, err := json.Marshal(fields)
+	if err != nil {
+		panic(err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// fingerprintValue converts a value to a form which can be encoded as JSON.
+func fingerprintValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Func:
+		return !v.IsNil()
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return v.Elem().Type().String()
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if re, ok := v.Interface().(*regexp.Regexp); ok {
+			return re.String()
+		}
+		return fingerprintValue(v.Elem())
+	case reflect.Struct:
+		m := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			m[v.Type().Field(i).Name] = fingerprintValue(v.Field(i))
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = fingerprintValue(v.Index(i))
+		}
+		return s
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = fingerprintValue(iter.Value())
+		}
+		return m
+	}
+	return v.Interface()
+}
@@ -0,0 +1,35 @@
+package jsondiff
+
+import (
+	"os"
+	"regexp"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	// zero options are encoded as {}
+	if fp := (Options{}).Fingerprint(); fp != "44136fa355b3678a1146ad16f7e8649e" {
+		t.Errorf("got %s for zero options", fp)
+	}
+	a := DefaultConsoleOptions()
+	a.IgnoreValuesMatching = []*regexp.Regexp{regexp.MustCompile(`^id-`)}
+	a.KeyAliases = map[string]string{"x": "y", "a": "b"}
+	b := DefaultConsoleOptions()
+	b.IgnoreValuesMatching = []*regexp.Regexp{regexp.MustCompile(`^id-`)}
+	b.KeyAliases = map[string]string{"a": "b", "x": "y"}
+	b.Trace = os.Stderr
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Error("equal options have different fingerprints")
+	}
+	b.IgnoreValuesMatching[0] = regexp.MustCompile(`^id_`)
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Error("different options have equal fingerprints")
+	}
+	b = a.WithCompareNumbers(epsilonComparator(0.1))
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Error("options with and without a function have equal fingerprints")
+	}
+	if Version() != version {
+		t.Errorf("got version %s", Version())
+	}
+}
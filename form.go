@@ -0,0 +1,72 @@
+package jsondiff
+
+import (
+	"net/url"
+	"strings"
+)
+
+// formValue decodes a query string or an application/x-www-form-urlencoded
+// string to an object, a leading "?" is ignored. Keys with a single value
+// map to the value, keys with several values map to an array of them in
+// order.
+func formValue(s string) (map[string]interface{}, error) {
+	values, err := url.ParseQuery(strings.TrimPrefix(s, "?"))
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{}, len(values))
+	for k, vs := range values {
+		if len(vs) == 1 {
+			m[k] = vs[0]
+			continue
+		}
+		arr := make([]interface{}, len(vs))
+		for i, v := range vs {
+			arr[i] = v
+		}
+		m[k] = arr
+	}
+	return m, nil
+}
+
+// DecodeForms returns a normalizer which replaces strings at paths matching
+// any of the patterns with objects of their decoded query string or
+// application/x-www-form-urlencoded parameters, so that parameters are
+// compared regardless of their order and encoding, e.g. "b=2&a=1" and
+// "?a=1&b=%32" are equal. A parameter with a single value becomes a string
+// property, a repeated one becomes an array of its values in order. Strings
+// which can't be decoded are left intact.
+func DecodeForms(patterns ...string) Normalizer {
+	return func(path string, v interface{}) interface{} {
+		s, ok := v.(string)
+		if !ok || !matchesAny(patterns, path) {
+			return v
+		}
+		m, err := formValue(s)
+		if err != nil {
+			return v
+		}
+		return m
+	}
+}
+
+// CompareForms compares two query strings or application/x-www-form-urlencoded
+// strings as objects of their parameters, see DecodeForms. Returns the same
+// values as Compare, FirstArgIsInvalidJson and SecondArgIsInvalidJson
+// verdicts mean that the corresponding string can't be decoded.
+func CompareForms(a, b string, opts *Options) (Difference, string) {
+	ctx := context{opts: opts}
+	av, errA := formValue(a)
+	bv, errB := formValue(b)
+	if errA != nil && errB != nil {
+		return BothArgsAreInvalidJson, ctx.invalidJsonMessage("both arguments are invalid forms", errA, errB)
+	}
+	if errA != nil {
+		return FirstArgIsInvalidJson, ctx.invalidJsonMessage("first argument is invalid form", errA, nil)
+	}
+	if errB != nil {
+		return SecondArgIsInvalidJson, ctx.invalidJsonMessage("second argument is invalid form", nil, errB)
+	}
+	d := ctx.compareRoots(av, bv)
+	return ctx.diff, ctx.render(d)
+}
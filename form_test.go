@@ -0,0 +1,31 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+func TestCompareForms(t *testing.T) {
+	cases := []struct {
+		a      string
+		b      string
+		result Difference
+	}{
+		{"b=2&a=1", "?a=1&b=%32", FullMatch},
+		{"a=1&a=2", "a=2&a=1", NoMatch},
+		{"a=1&b=2", "a=1", SupersetMatch},
+		{"a=%zz", "a=1", FirstArgIsInvalidJson},
+	}
+	opts := Options{}
+	for i, c := range cases {
+		if result, _ := CompareForms(c.a, c.b, &opts); result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s", i, result, c.result)
+		}
+	}
+
+	opts = Options{Indent: " ", SkipMatches: true, ChangedSeparator: " => ", Transform: Normalize(DecodeForms("body"))}
+	result, diff := Compare([]byte(`{"body": "x=1&y=2"}`), []byte(`{"body": "y=3&x=1"}`), &opts)
+	expected := "{\n \"body\": {\n  \"y\": \"2\" => \"3\"\n }\n}"
+	if result != NoMatch || diff != expected {
+		t.Errorf("got %s:\n%s\nexpected NoMatch:\n%s", result, diff, expected)
+	}
+}
@@ -0,0 +1,104 @@
+package jsondiff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GraphQLResponse returns a normalizer of GraphQL response envelopes
+// (https://spec.graphql.org/October2021/#sec-Response-Format), which is
+// applied to the root value of the documents:
+//
+//   - "errors" becomes an object indexed by the path and the message of the
+//     errors, e.g. "user.0.name: not found", so that errors are compared as
+//     an unordered set. Errors without a message or with a duplicate key are
+//     indexed by "#" followed by their position.
+//   - "extensions.tracing" is removed, along with "extensions" if nothing
+//     else is left in it.
+//
+// "data" is left intact and compared structurally. Values which aren't
+// GraphQL responses are left intact.
+func GraphQLResponse() Normalizer {
+	return func(path string, v interface{}) interface{} {
+		resp, ok := v.(map[string]interface{})
+		if path != "" || !ok {
+			return v
+		}
+		_, hasData := resp["data"]
+		_, hasErrors := resp["errors"]
+		if !hasData && !hasErrors {
+			return v
+		}
+		return normalizeGraphQL(resp)
+	}
+}
+
+func normalizeGraphQL(resp map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(resp))
+	for k, v := range resp {
+		out[k] = v
+	}
+	if errs, ok := resp["errors"].([]interface{}); ok {
+		m := make(map[string]interface{}, len(errs))
+		for i, e := range errs {
+			k, ok := graphqlErrorKey(e)
+			if _, dup := m[k]; !ok || dup {
+				k = "#" + strconv.Itoa(i)
+			}
+			m[k] = e
+		}
+		out["errors"] = m
+	}
+	if ext, ok := resp["extensions"].(map[string]interface{}); ok {
+		if _, ok := ext["tracing"]; ok {
+			outExt := make(map[string]interface{}, len(ext))
+			for k, v := range ext {
+				if k != "tracing" {
+					outExt[k] = v
+				}
+			}
+			if len(outExt) == 0 {
+				delete(out, "extensions")
+			} else {
+				out["extensions"] = outExt
+			}
+		}
+	}
+	return out
+}
+
+// graphqlErrorKey returns the dot separated path of the error followed by its
+// message.
+func graphqlErrorKey(v interface{}) (string, bool) {
+	e, ok := v.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	msg, ok := e["message"].(string)
+	if !ok {
+		return "", false
+	}
+	path, _ := e["path"].([]interface{})
+	elems := make([]string, len(path))
+	for i, p := range path {
+		if s, ok := p.(string); ok {
+			elems[i] = s
+		} else {
+			elems[i] = encodeJSON(p, "", "")
+		}
+	}
+	return strings.Join(elems, ".") + ": " + msg, true
+}
+
+// CompareGraphQL compares two GraphQL responses like Compare, with
+// GraphQLResponse normalization applied before Options.Transform, see
+// GraphQLResponse.
+func CompareGraphQL(a, b []byte, opts *Options) (Difference, string) {
+	o := *opts
+	if o.Transform != nil {
+		o.Transform = Normalize(GraphQLResponse(), o.Transform)
+	} else {
+		o.Transform = GraphQLResponse()
+	}
+	return Compare(a, b, &o)
+}
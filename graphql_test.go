@@ -0,0 +1,50 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+func TestCompareGraphQL(t *testing.T) {
+	a := []byte(`{
+		"data": {"user": null},
+		"errors": [
+			{"message": "not found", "path": ["user"]},
+			{"message": "denied", "path": ["user", 0, "email"]}
+		],
+		"extensions": {"tracing": {"duration": 120}}
+	}`)
+	b := []byte(`{
+		"data": {"user": null},
+		"errors": [
+			{"message": "denied", "path": ["user", 0, "email"]},
+			{"message": "not found", "path": ["user"]}
+		]
+	}`)
+	opts := Options{Indent: " ", SkipMatches: true, ChangedSeparator: " => "}
+	if result, diff := CompareGraphQL(a, b, &opts); result != FullMatch {
+		t.Errorf("got %s, expected FullMatch:\n%s", result, diff)
+	}
+
+	b = []byte(`{"data": {"user": null}, "errors": [{"message": "not found", "path": ["user"]}], "extensions": {"cost": 1}}`)
+	opts.Added = Tag{Begin: "+"}
+	opts.Removed = Tag{Begin: "-"}
+	result, diff := CompareGraphQL(a, b, &opts)
+	expected := "{\n" +
+		" \"errors\": {\n" +
+		"  -\"user.0.email: denied\": {\n" +
+		"   -\"message\": \"denied\",\n" +
+		"   -\"path\": [\n" +
+		"    -\"user\",\n" +
+		"    -0,\n" +
+		"    -\"email\"\n" +
+		"   -]\n" +
+		"  -}\n" +
+		" },\n" +
+		" +\"extensions\": {\n" +
+		"  +\"cost\": 1\n" +
+		" +}\n" +
+		"}"
+	if result != NoMatch || diff != expected {
+		t.Errorf("got %s:\n%s\nexpected NoMatch:\n%s", result, diff, expected)
+	}
+}
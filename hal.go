@@ -0,0 +1,44 @@
+package jsondiff
+
+import (
+	"strings"
+)
+
+// HALLinks returns a normalizer of HAL documents
+// (https://datatracker.ietf.org/doc/html/draft-kelly-json-hal) which strips
+// the scheme and host from "href" values of links, so that responses of
+// different environments compare equal when their links point to the same
+// paths, e.g. "https://staging.example.com/orders/1?x=1" becomes
+// "/orders/1?x=1". Links of embedded resources are normalized as well.
+// Relative hrefs are left intact.
+func HALLinks() Normalizer {
+	return func(path string, v interface{}) interface{} {
+		s, ok := v.(string)
+		if !ok || !matchesAny(halLinkPatterns, path) {
+			return v
+		}
+		return stripOrigin(s)
+	}
+}
+
+var halLinkPatterns = []string{"**._links.**.href"}
+
+// stripOrigin returns the path, query and fragment of an absolute or
+// scheme-relative URL, other strings are returned as is.
+func stripOrigin(s string) string {
+	rest := s
+	if i := strings.Index(s, "://"); i > 0 && !strings.ContainsAny(s[:i], "/?#{") {
+		rest = s[i+3:]
+	} else if strings.HasPrefix(s, "//") {
+		rest = s[2:]
+	} else {
+		return s
+	}
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		if rest[i] != '/' {
+			return "/" + rest[i:]
+		}
+		return rest[i:]
+	}
+	return "/"
+}
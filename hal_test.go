@@ -0,0 +1,47 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+func TestStripOrigin(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected string
+	}{
+		{"https://example.com/orders/1?x=1", "/orders/1?x=1"},
+		{"http://localhost:8080", "/"},
+		{"https://example.com?page=2", "/?page=2"},
+		{"//cdn.example.com/a.png", "/a.png"},
+		{"/orders{?page}", "/orders{?page}"},
+		{"orders/1", "orders/1"},
+		{"/redirect?to=https://example.com/", "/redirect?to=https://example.com/"},
+	}
+	for _, c := range cases {
+		if got := stripOrigin(c.s); got != c.expected {
+			t.Errorf("%s: got %s, expected %s", c.s, got, c.expected)
+		}
+	}
+}
+
+func TestHALLinks(t *testing.T) {
+	a := []byte(`{
+		"_links": {"self": {"href": "https://prod.example.com/orders/1"}, "items": [{"href": "https://prod.example.com/items/1"}]},
+		"_embedded": {"customer": {"_links": {"self": {"href": "https://prod.example.com/customers/7"}}}},
+		"homepage": "https://prod.example.com/"
+	}`)
+	b := []byte(`{
+		"_links": {"self": {"href": "http://localhost:8080/orders/1"}, "items": [{"href": "http://localhost:8080/items/1"}]},
+		"_embedded": {"customer": {"_links": {"self": {"href": "http://localhost:8080/customers/7"}}}},
+		"homepage": "http://localhost:8080/"
+	}`)
+	opts, _ := PresetOptions("hal")
+	result, diff := Compare(a, b, &opts)
+	if result != NoMatch {
+		t.Errorf("got %s, expected NoMatch", result)
+	}
+	opts.Ignore = []string{"homepage"}
+	if result, diff = Compare(a, b, &opts); result != FullMatch {
+		t.Errorf("got %s, expected FullMatch:\n%s", result, diff)
+	}
+}
@@ -0,0 +1,140 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HTTPOptions configures CompareHTTPResponses.
+type HTTPOptions struct {
+	// Options of the comparison of the combined documents, see
+	// CompareHTTPResponses.
+	Options *Options
+	// Names of the compared headers, all headers are compared if empty.
+	Headers []string
+	// Names of the headers which are not compared, e.g. "Date".
+	IgnoreHeaders []string
+}
+
+// HTTPReport is the result of CompareHTTPResponses.
+type HTTPReport struct {
+	// Verdict of the whole comparison.
+	Difference Difference
+	// Verdicts of the status codes, the headers and the bodies alone.
+	Status  Difference
+	Headers Difference
+	Body    Difference
+	// Rendering of the difference of the combined documents.
+	Diff string
+}
+
+// CompareHTTPResponses compares status codes, headers and bodies of two HTTP
+// responses, e.g. of a service and its shadow. Every response is converted
+// to a combined document which is compared according to opts.Options:
+//
+//	{"status": 200, "headers": {"Content-Type": "application/json"}, "body": ...}
+//
+// Header names are canonicalized, a header with several values becomes an
+// array of them. JSON documents of multipart and event stream bodies are
+// extracted, see ExtractJSON. The body is decoded as JSON if possible,
+// otherwise it's compared as a string, an empty body is null. Path patterns of the options
+// are relative to the combined document, e.g. "body.items" or
+// "headers.Etag". Bodies are read and replaced with readers of the same
+// contents, so that they can be read again. Returns an error if reading any
+// of the bodies fails.
+func CompareHTTPResponses(a, b *http.Response, opts *HTTPOptions) (*HTTPReport, error) {
+	ctx := context{opts: opts.Options, collectReasons: true}
+	av, err := ctx.httpDocument(a, opts)
+	if err != nil {
+		return nil, err
+	}
+	bv, err := ctx.httpDocument(b, opts)
+	if err != nil {
+		return nil, err
+	}
+	d := ctx.compareRoots(av, bv)
+	report := &HTTPReport{Difference: ctx.diff, Diff: ctx.render(d)}
+	for _, r := range ctx.reasons {
+		part := r.Path
+		if i := strings.IndexByte(part, '.'); i >= 0 {
+			part = part[:i]
+		}
+		var diff *Difference
+		switch part {
+		case "status":
+			diff = &report.Status
+		case "headers":
+			diff = &report.Headers
+		case "body":
+			diff = &report.Body
+		default:
+			continue
+		}
+		if *diff != NoMatch {
+			*diff = r.Kind.Difference()
+		}
+	}
+	return report, nil
+}
+
+// httpDocument converts the response to the combined document compared by
+// CompareHTTPResponses.
+func (ctx *context) httpDocument(r *http.Response, opts *HTTPOptions) (interface{}, error) {
+	var body interface{}
+	if r.Body != nil {
+		data, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+		if extracted, err := ExtractJSON(r.Header.Get("Content-Type"), data); err == nil {
+			data = extracted
+		}
+		if len(data) != 0 {
+			// text which starts with a JSON value is not JSON
+			strict := *ctx.opts
+			strict.Strict = true
+			bodyCtx := context{opts: &strict}
+			if v, err := bodyCtx.decode(bytes.NewReader(data)); err == nil {
+				body = v
+			} else {
+				body = string(data)
+			}
+		}
+	}
+
+	selected := make(map[string]bool, len(opts.Headers))
+	for _, h := range opts.Headers {
+		selected[http.CanonicalHeaderKey(h)] = true
+	}
+	ignored := make(map[string]bool, len(opts.IgnoreHeaders))
+	for _, h := range opts.IgnoreHeaders {
+		ignored[http.CanonicalHeaderKey(h)] = true
+	}
+	headers := make(map[string]interface{}, len(r.Header))
+	for k, vs := range r.Header {
+		k = http.CanonicalHeaderKey(k)
+		if ignored[k] || (len(selected) != 0 && !selected[k]) {
+			continue
+		}
+		if len(vs) == 1 {
+			headers[k] = vs[0]
+			continue
+		}
+		arr := make([]interface{}, len(vs))
+		for i, v := range vs {
+			arr[i] = v
+		}
+		headers[k] = arr
+	}
+	return map[string]interface{}{
+		"status":  json.Number(strconv.Itoa(r.StatusCode)),
+		"headers": headers,
+		"body":    body,
+	}, nil
+}
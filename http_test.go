@@ -0,0 +1,46 @@
+package jsondiff
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func testResponse(status int, headers map[string]string, body string) *http.Response {
+	r := &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	return r
+}
+
+func TestCompareHTTPResponses(t *testing.T) {
+	a := testResponse(200, map[string]string{"Content-Type": "application/json", "Date": "Mon", "X-Extra": "1"}, `{"id": 1, "name": "a"}`)
+	b := testResponse(200, map[string]string{"content-type": "application/json", "Date": "Tue"}, `{"id": 1, "name": "b"}`)
+	opts := &HTTPOptions{
+		Options:       &Options{Indent: " ", SkipMatches: true, ChangedSeparator: " => "},
+		IgnoreHeaders: []string{"date"},
+	}
+	report, err := CompareHTTPResponses(a, b, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Difference != NoMatch || report.Status != FullMatch || report.Headers != SupersetMatch || report.Body != NoMatch {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	expected := "{\n \"body\": {\n  \"name\": \"a\" => \"b\"\n },\n \"headers\": {\n  \"X-Extra\": \"1\"\n }\n}"
+	if report.Diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", report.Diff, expected)
+	}
+	if body, _ := io.ReadAll(a.Body); string(body) != `{"id": 1, "name": "a"}` {
+		t.Errorf("body is not restored: %q", body)
+	}
+
+	a = testResponse(404, nil, "not found")
+	b = testResponse(500, nil, "not found")
+	opts.Headers = []string{"Content-Type"}
+	if report, _ = CompareHTTPResponses(a, b, opts); report.Status != NoMatch || report.Body != FullMatch {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
@@ -0,0 +1,151 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// encodeJSON encodes decoded JSON value v without HTML escaping.
+func encodeJSON(v interface{}, prefix, indent string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, indent)
+	if err := enc.Encode(v); err != nil {
+		// decoded documents contain only types known to encoding/json, but
+		// lenient parser may produce non-finite numbers
+		buf.Reset()
+		if err := enc.Encode(quoteInvalidNumbers(v)); err != nil {
+			panic(err)
+		}
+	}
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// quoteInvalidNumbers returns a copy of v where numbers which are not valid
+// JSON are replaced with strings.
+func quoteInvalidNumbers(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case json.Number:
+		if !json.Valid([]byte(vv)) {
+			return string(vv)
+		}
+	case []interface{}:
+		s := make([]interface{}, len(vv))
+		for i, e := range vv {
+			s[i] = quoteInvalidNumbers(e)
+		}
+		return s
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(vv))
+		for k, e := range vv {
+			m[k] = quoteInvalidNumbers(e)
+		}
+		return m
+	}
+	return v
+}
+
+type jdHunk struct {
+	path    []interface{}
+	removed []interface{}
+	added   []interface{}
+}
+
+func (h *jdHunk) write(buf *bytes.Buffer) {
+	buf.WriteString("@ ")
+	buf.WriteString(encodeJSON(h.path, "", ""))
+	buf.WriteString("\n")
+	for _, v := range h.removed {
+		buf.WriteString("- ")
+		buf.WriteString(encodeJSON(v, "", ""))
+		buf.WriteString("\n")
+	}
+	for _, v := range h.added {
+		buf.WriteString("+ ")
+		buf.WriteString(encodeJSON(v, "", ""))
+		buf.WriteString("\n")
+	}
+}
+
+func jdPath(path []interface{}, elem interface{}) []interface{} {
+	p := make([]interface{}, len(path), len(path)+1)
+	copy(p, path)
+	return append(p, elem)
+}
+
+func (ctx *context) jdHunks(buf *bytes.Buffer, d *delta, path []interface{}) {
+	if orig, ok := ctx.originals[d]; ok {
+		// paths inside of the collection don't exist in the document
+		if d.differs {
+			h := jdHunk{path: path, removed: []interface{}{orig.a}, added: []interface{}{orig.b}}
+			h.write(buf)
+		}
+		return
+	}
+	switch d.kind {
+	case deltaChanged:
+		h := jdHunk{path: path, removed: []interface{}{d.a}, added: []interface{}{d.b}}
+		h.write(buf)
+	case deltaAdded:
+		h := jdHunk{path: path, added: []interface{}{d.b}}
+		h.write(buf)
+	case deltaRemoved:
+		h := jdHunk{path: path, removed: []interface{}{d.a}}
+		h.write(buf)
+	case deltaCollection:
+		if d.isObject() {
+			for i, e := range d.elems {
+				p := jdPath(path, d.keys[i])
+				switch e.kind {
+				case deltaAdded:
+					h := jdHunk{path: p, added: []interface{}{e.b}}
+					h.write(buf)
+				case deltaRemoved:
+					h := jdHunk{path: p, removed: []interface{}{e.a}}
+					h.write(buf)
+				default:
+					ctx.jdHunks(buf, e, p)
+				}
+			}
+			return
+		}
+
+		// consecutive element changes in arrays are merged into a single hunk
+		var h *jdHunk
+		flush := func() {
+			if h != nil {
+				h.write(buf)
+				h = nil
+			}
+		}
+		for i, e := range d.elems {
+			if _, rewritten := ctx.originals[e]; rewritten ||
+				e.kind == deltaMatch || e.kind == deltaCollection || e.kind == deltaSkipped {
+				flush()
+				ctx.jdHunks(buf, e, jdPath(path, i))
+				continue
+			}
+			if h == nil {
+				h = &jdHunk{path: jdPath(path, i)}
+			}
+			if e.kind != deltaAdded {
+				h.removed = append(h.removed, e.a)
+			}
+			if e.kind != deltaRemoved {
+				h.added = append(h.added, e.b)
+			}
+		}
+		flush()
+	}
+}
+
+func (ctx *context) renderJD(d *delta) string {
+	var buf bytes.Buffer
+	path := ctx.rootPath
+	if path == nil {
+		path = []interface{}{}
+	}
+	ctx.jdHunks(&buf, d, path)
+	return buf.String()
+}
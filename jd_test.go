@@ -0,0 +1,119 @@
+package jsondiff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var jdCases = []struct {
+	a        string
+	b        string
+	expected string
+}{
+	{`{"b":"foo","a":[1,2,3],"c":"zoo","d":"Joe"}`, `{"a":[1,2,4,5],"b":"baz","c":"zoo"}`, `
+@ ["a",2]
+- 3
++ 4
++ 5
+@ ["b"]
+- "foo"
++ "baz"
+@ ["d"]
+- "Joe"
+	`},
+	{`{"a":[{"foo":"bar"},{"b": "c"}, 1, 2]}`, `{"a":[{"foo":"bar"},{"b": "d"}], "e": {"x": "<y>"}}`, `
+@ ["a",1,"b"]
+- "c"
++ "d"
+@ ["a",2]
+- 1
+- 2
+@ ["e"]
++ {"x":"<y>"}
+	`},
+	{`[1]`, `{}`, `
+@ []
+- [1]
++ {}
+	`},
+	{`{"a":1}`, `{"a":1}`, ``},
+}
+
+func TestJDOutput(t *testing.T) {
+	opts := Options{Format: JDOutput}
+	for i, c := range jdCases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			expected := strings.TrimLeft(c.expected, "\n")
+			expected = strings.TrimRight(expected, "\t")
+			_, diff := Compare([]byte(c.a), []byte(c.b), &opts)
+			if diff != expected {
+				t.Errorf("got:\n---\n%s\n---\nexpected:\n---\n%s\n---\n", diff, expected)
+			}
+		})
+	}
+}
+
+// jdRewritingCases are documents compared with options which change the shape
+// of collections or the compared values, see rewritingCases.
+var jdRewritingCases = []struct {
+	opts     Options
+	a        string
+	b        string
+	expected string
+}{
+	{Options{KeyedArrays: map[string]string{"u": "id"}}, `{"u":[{"id":1,"n":"a"},{"id":2,"n":"b"}]}`, `{"u":[{"id":2,"n":"c"},{"id":1,"n":"a"}]}`, `
+@ ["u"]
+- [{"id":1,"n":"a"},{"id":2,"n":"b"}]
++ [{"id":2,"n":"c"},{"id":1,"n":"a"}]
+	`},
+	{Options{KeyedArrays: map[string]string{"u": "id"}}, `{"u":[{"id":1}],"v":1}`, `{"u":[{"id":1}],"v":2}`, `
+@ ["v"]
+- 1
++ 2
+	`},
+	{Options{UnorderedArrays: []string{"x"}}, `{"x":[1,2,3]}`, `{"x":[3,1,4]}`, `
+@ ["x"]
+- [1,2,3]
++ [3,1,4]
+	`},
+	{Options{UnorderedArrays: []string{"x"}}, `{"x":[1,2,3]}`, `{"x":[3,2,1]}`, ``},
+	{Options{SortArraysAt: map[string]func(a, b interface{}) bool{"x": func(a, b interface{}) bool {
+		return a.(string) < b.(string)
+	}}}, `{"x":["c","a"]}`, `{"x":["b","a"]}`, `
+@ ["x"]
+- ["c","a"]
++ ["b","a"]
+	`},
+	{Options{NumericKeysAsArrays: true}, `{"x":{"0":"a","1":"b"}}`, `{"x":{"0":"a","2":"c"}}`, `
+@ ["x"]
+- {"0":"a","1":"b"}
++ {"0":"a","2":"c"}
+	`},
+	{Options{NumericKeysAsArrays: true}, `[{"0":"a"},1]`, `[["b"],1]`, `
+@ [0]
+- {"0":"a"}
++ ["b"]
+	`},
+	{Options{FirstRoot: "data", SecondRoot: ""}, `{"data":{"a":1},"meta":1}`, `{"a":2}`, `
+@ ["data","a"]
+- 1
++ 2
+	`},
+	{Options{FirstRoot: "data.1", SecondRoot: "data.0"}, `{"data":[1,[2]]}`, `{"data":[[3]]}`, `
+@ ["data",1,0]
+- 2
++ 3
+	`},
+}
+
+func TestJDOutputRewritingOptions(t *testing.T) {
+	for i, c := range jdRewritingCases {
+		opts := c.opts
+		opts.Format = JDOutput
+		expected := strings.TrimRight(strings.TrimLeft(c.expected, "\n"), "\t")
+		if _, diff := Compare([]byte(c.a), []byte(c.b), &opts); diff != expected {
+			t.Errorf("%d: got:\n---\n%s\n---\nexpected:\n---\n%s\n---\n", i, diff, expected)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package jsondiff
+
+import (
+	"strconv"
+)
+
+// JSONAPI returns a normalizer of JSON:API documents (https://jsonapi.org),
+// which makes resources compared by identity rather than position. It's
+// applied to the root value of the documents:
+//
+//   - Arrays of resources in "data" and "included" become objects indexed by
+//     "type:id" of the resources.
+//   - Relationship linkage of the primary resources ("relationships.*.data")
+//     is replaced with the included resources it refers to, to-many linkage
+//     is indexed by "type:id" as well. Relationships of the included
+//     resources are left as is, so that cycles are not followed.
+//   - Included resources referenced by the primary resources are removed
+//     from "included", which is removed altogether if nothing is left.
+//
+// Resources without a string type and id, or with a duplicate one, are
+// indexed by "#" followed by their position. Values which aren't JSON:API
+// documents are left intact.
+func JSONAPI() Normalizer {
+	return func(path string, v interface{}) interface{} {
+		doc, ok := v.(map[string]interface{})
+		if path != "" || !ok {
+			return v
+		}
+		if _, ok := doc["data"]; !ok {
+			return v
+		}
+		return normalizeJSONAPI(doc)
+	}
+}
+
+// resourceKey returns the "type:id" identity of a resource or a resource
+// identifier.
+func resourceKey(v interface{}) (string, bool) {
+	r, ok := v.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	typ, okType := r["type"].(string)
+	id, okID := r["id"].(string)
+	return typ + ":" + id, okType && okID
+}
+
+// jsonapiResolver replaces relationship linkage with included resources and
+// tracks which of them are referenced.
+type jsonapiResolver struct {
+	included   map[string]interface{}
+	referenced map[string]bool
+}
+
+func normalizeJSONAPI(doc map[string]interface{}) map[string]interface{} {
+	r := jsonapiResolver{
+		included:   make(map[string]interface{}),
+		referenced: make(map[string]bool),
+	}
+	inc, _ := doc["included"].([]interface{})
+	for _, v := range inc {
+		if k, ok := resourceKey(v); ok {
+			r.included[k] = v
+		}
+	}
+
+	out := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		out[k] = v
+	}
+	switch data := doc["data"].(type) {
+	case []interface{}:
+		out["data"] = indexResources(data, func(v interface{}) interface{} {
+			return r.resource(v, true)
+		})
+	case map[string]interface{}:
+		out["data"] = r.resource(data, true)
+	}
+	if inc == nil {
+		return out
+	}
+	rest := make([]interface{}, 0, len(inc))
+	for _, v := range inc {
+		if k, ok := resourceKey(v); !ok || !r.referenced[k] {
+			rest = append(rest, v)
+		}
+	}
+	if len(rest) == 0 {
+		delete(out, "included")
+	} else {
+		out["included"] = indexResources(rest, func(v interface{}) interface{} {
+			return r.resource(v, false)
+		})
+	}
+	return out
+}
+
+// indexResources converts an array of resources to an object indexed by their
+// identities, applying f to every resource.
+func indexResources(arr []interface{}, f func(v interface{}) interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(arr))
+	for i, v := range arr {
+		k, ok := resourceKey(v)
+		if _, dup := m[k]; !ok || dup {
+			k = "#" + strconv.Itoa(i)
+		}
+		m[k] = f(v)
+	}
+	return m
+}
+
+// resource returns a copy of the resource with to-many linkage indexed by
+// identity and, when resolve is true, linkage replaced with the included
+// resources.
+func (r *jsonapiResolver) resource(v interface{}, resolve bool) interface{} {
+	res, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	rels, ok := res["relationships"].(map[string]interface{})
+	if !ok {
+		return v
+	}
+	out := make(map[string]interface{}, len(res))
+	for k, v := range res {
+		out[k] = v
+	}
+	outRels := make(map[string]interface{}, len(rels))
+	for name, rel := range rels {
+		relObj, ok := rel.(map[string]interface{})
+		if !ok {
+			outRels[name] = rel
+			continue
+		}
+		outRel := make(map[string]interface{}, len(relObj))
+		for k, v := range relObj {
+			outRel[k] = v
+		}
+		switch linkage := relObj["data"].(type) {
+		case []interface{}:
+			outRel["data"] = indexResources(linkage, func(v interface{}) interface{} {
+				return r.linkage(v, resolve)
+			})
+		case map[string]interface{}:
+			outRel["data"] = r.linkage(linkage, resolve)
+		}
+		outRels[name] = outRel
+	}
+	out["relationships"] = outRels
+	return out
+}
+
+// linkage returns the included resource the identifier refers to, or the
+// identifier itself if it's not included or resolve is false.
+func (r *jsonapiResolver) linkage(v interface{}, resolve bool) interface{} {
+	k, ok := resourceKey(v)
+	if !ok || !resolve {
+		return v
+	}
+	inc, ok := r.included[k]
+	if !ok {
+		return v
+	}
+	r.referenced[k] = true
+	return r.resource(inc, false)
+}
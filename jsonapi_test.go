@@ -0,0 +1,59 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+func TestJSONAPI(t *testing.T) {
+	a := []byte(`{
+		"data": [
+			{"type": "articles", "id": "1", "attributes": {"title": "A"},
+			 "relationships": {"author": {"data": {"type": "people", "id": "9"}},
+			                   "tags": {"data": [{"type": "tags", "id": "1"}, {"type": "tags", "id": "2"}]}}},
+			{"type": "articles", "id": "2", "attributes": {"title": "B"}}
+		],
+		"included": [
+			{"type": "people", "id": "9", "attributes": {"name": "Ann"}},
+			{"type": "tags", "id": "1"},
+			{"type": "tags", "id": "2"}
+		]
+	}`)
+	b := []byte(`{
+		"data": [
+			{"type": "articles", "id": "2", "attributes": {"title": "B"}},
+			{"type": "articles", "id": "1", "attributes": {"title": "A"},
+			 "relationships": {"author": {"data": {"type": "people", "id": "9"}},
+			                   "tags": {"data": [{"type": "tags", "id": "2"}, {"type": "tags", "id": "1"}]}}}
+		],
+		"included": [
+			{"type": "tags", "id": "2"},
+			{"type": "people", "id": "9", "attributes": {"name": "Bob"}},
+			{"type": "tags", "id": "1"}
+		]
+	}`)
+	opts := Options{Indent: " ", SkipMatches: true, ChangedSeparator: " => ", Transform: Normalize(JSONAPI())}
+	result, diff := Compare(a, b, &opts)
+	expected := "{\n" +
+		" \"data\": {\n" +
+		"  \"articles:1\": {\n" +
+		"   \"relationships\": {\n" +
+		"    \"author\": {\n" +
+		"     \"data\": {\n" +
+		"      \"attributes\": {\n" +
+		"       \"name\": \"Ann\" => \"Bob\"\n" +
+		"      }\n" +
+		"     }\n" +
+		"    }\n" +
+		"   }\n" +
+		"  }\n" +
+		" }\n" +
+		"}"
+	if result != NoMatch || diff != expected {
+		t.Errorf("got %s:\n%s\nexpected NoMatch:\n%s", result, diff, expected)
+	}
+
+	// values which aren't JSON:API documents are left intact
+	if result, _ := Compare([]byte(`[1, 2]`), []byte(`[2, 1]`), &opts); result != NoMatch {
+		t.Errorf("got %s, expected NoMatch", result)
+	}
+}
@@ -5,12 +5,21 @@ import (
 	"encoding/json"
 	"io"
 	"reflect"
-	"sort"
+	"regexp"
 	"strconv"
+	"unicode/utf8"
 )
 
+// Difference is a verdict of the comparison.
+//
+// Numeric values of the existing constants are stable and will never change,
+// new verdicts are only ever added at the end of the list.
 type Difference int
 
+// UnknownDifference is never returned by the comparison functions, it's a
+// placeholder for an undetermined verdict in user code.
+const UnknownDifference Difference = -1
+
 const (
 	FullMatch Difference = iota
 	SupersetMatch
@@ -34,15 +43,158 @@ func (d Difference) String() string {
 		return "SecondArgIsInvalidJson"
 	case BothArgsAreInvalidJson:
 		return "BothArgsAreInvalidJson"
+	case UnknownDifference:
+		return "UnknownDifference"
 	}
 	return "Invalid"
 }
 
+// IsMatch returns true if the verdict is FullMatch or SupersetMatch.
+func (d Difference) IsMatch() bool {
+	return d == FullMatch || d == SupersetMatch
+}
+
+// IsError returns true if the verdict means comparison wasn't performed,
+// because one or both of the arguments are invalid JSON.
+func (d Difference) IsError() bool {
+	return d == FirstArgIsInvalidJson || d == SecondArgIsInvalidJson || d == BothArgsAreInvalidJson
+}
+
+// ExitCode returns the exit status of a command reporting the verdict, as
+// used by the jsondiff command, so that tools built on the package agree
+// with it. The mapping is stable:
+//
+//	0  FullMatch or SupersetMatch
+//	1  NoMatch
+//	2  invalid input, UnknownDifference or any other value
+//
+// Commands are expected to exit with 2 on their own errors as well.
+func ExitCode(d Difference) int {
+	switch {
+	case d.IsMatch():
+		return 0
+	case d == NoMatch:
+		return 1
+	}
+	return 2
+}
+
+// OutputFormat selects the renderer used by Compare.
+type OutputFormat int
+
+const (
+	// Human-readable format similar to pretty printed JSON, uses tags to
+	// highlight changes.
+	TextOutput OutputFormat = iota
+	// Valid JSON document which mirrors the structure of compared documents.
+	// See DocumentOutput section in the Compare documentation.
+	DocumentOutput
+	// Diff format of the jd tool (https://github.com/josephburnett/jd),
+	// which can be applied as a structural patch.
+	JDOutput
+	// JSON Patch (RFC 6902), see Options.CompactPatch.
+	JSONPatchOutput
+)
+
+// EmptyCollectionMode controls how an empty object or array in the second
+// document is compared against a non-empty one in the first document.
+type EmptyCollectionMode int
+
+const (
+	// Empty collection is compared as any other one, which means first
+	// document is a superset of it.
+	EmptyCollectionDefault EmptyCollectionMode = iota
+	// Empty collection matches any collection of the same type.
+	EmptyCollectionMatchesAny
+	// Empty collection matches only an empty collection.
+	EmptyCollectionMatchesEmpty
+)
+
+// ChangedLayout controls how changed values are printed in the text output.
+type ChangedLayout int
+
+const (
+	// Old and new values are printed on a single line, separated by
+	// Options.ChangedSeparator and highlighted with Options.Changed tag.
+	ChangedInline ChangedLayout = iota
+	// Old and new values are printed on separate lines, as if the old value
+	// was removed and the new one was added.
+	ChangedSeparateLines
+)
+
+// SkippedPlacement controls where messages about matching collection elements
+// omitted from the text output are printed, see Options.SkippedPlacement.
+type SkippedPlacement int
+
+const (
+	// Every run of consecutive omitted elements is replaced with a message.
+	SkippedInPlace SkippedPlacement = iota
+	// A single message with the total number of omitted elements is printed
+	// before the rest of the elements.
+	SkippedBefore
+	// A single message with the total number of omitted elements is printed
+	// after the rest of the elements.
+	SkippedAfter
+	// Elements omitted before the first printed element are replaced with a
+	// message before it, a single message with the number of the rest of them
+	// is printed after the last printed element.
+	SkippedAround
+)
+
+// QuoteMode controls how strings are quoted in the text output, see
+// Options.QuoteMode.
+type QuoteMode int
+
+const (
+	// Strings are quoted as Go string literals by strconv.Quote, e.g.
+	// non-printable characters are escaped as \x00 or \u200b.
+	QuoteGo QuoteMode = iota
+	// Strings are quoted as JSON strings, so that they can be copied into
+	// documents as is. Non-ASCII characters are escaped as \u sequences.
+	QuoteJSON
+	// Strings are written as UTF-8 text, only double quotes, backslashes and
+	// control characters are escaped. Invalid UTF-8 is written as is.
+	QuoteRaw
+)
+
+// InputFormat is the encoding of the compared documents, see
+// Options.InputFormat.
+type InputFormat int
+
+const (
+	// Documents are JSON text.
+	JSONInput InputFormat = iota
+	// Documents are CBOR (RFC 8949) data items.
+	CBORInput
+	// Documents are MessagePack values.
+	MessagePackInput
+)
+
 type Tag struct {
 	Begin string
 	End   string
 }
 
+// Glyphs are symbols printed at the beginning of lines of the text output
+// depending on their contents, see Options.Glyphs.
+type Glyphs struct {
+	Normal  string
+	Added   string
+	Removed string
+	Changed string
+	Skipped string
+}
+
+// EmojiGlyphs mark lines with emoji, which is useful where colors are not
+// available, e.g. in chat messages.
+var EmojiGlyphs = Glyphs{
+	Normal:  "✅ ",
+	Added:   "➕ ",
+	Removed: "➖ ",
+	Changed: "✏️ ",
+	Skipped: "⏭️ ",
+}
+
 type Options struct {
 	Normal                Tag
 	Added                 Tag
@@ -58,8 +210,267 @@ type Options struct {
 	// When provided, this function will be used to compare two numbers. By default numbers are compared using their
 	// literal representation byte by byte.
 	CompareNumbers func(a, b json.Number) bool
+	// When positive and CompareNumbers is not provided, numbers are compared
+	// as exact decimals rounded half away from zero to this number of decimal
+	// places, regardless of their representation, e.g. 10.5, 10.499 and
+	// 1.05e1 are equal with 2 places. Numbers with exponents beyond 1000 are
+	// compared literally.
+	DecimalPlaces int
+	// When true, numbers are written in a canonical form in the text output,
+	// e.g. 1.50, 15e-1 and 1.5E0 are all written as 1.5, so that equal numbers
+	// look the same regardless of how they are compared. Plain decimal
+	// notation is used unless the number is very large or very small.
+	NormalizeNumbers bool
 	// When true, only differences will be printed. By default, it will print the full json.
 	SkipMatches bool
+	// When true, documents which are identical after removing insignificant
+	// whitespace are reported as FullMatch without comparing them value by
+	// value. Input streams are read into memory fully in this mode. Ignored
+	// when KeyAliases are set, since identical documents may differ then.
+	QuickFullMatch bool
+	// Control how an empty object or array in the second document is compared.
+	EmptyObject EmptyCollectionMode
+	EmptyArray  EmptyCollectionMode
+	// Path patterns of object properties which are allowed to be missing in
+	// either of the documents without affecting the verdict. Missing properties
+	// are still rendered as added or removed. See Compare documentation for
+	// the path pattern syntax.
+	OptionalKeys []string
+	// When true, messages returned for invalid JSON documents include the
+	// decoding error and its byte offset.
+	VerboseErrors bool
+	// When true, documents may use common non-strict JSON extensions: NaN and
+	// Infinity numbers, unquoted object keys, single quoted strings and
+	// trailing commas. Non-finite numbers are rendered as strings by the JSON
+	// based output formats.
+	Lenient bool
+	// When true, documents must consist of a single value surrounded by
+	// optional whitespace as RFC 8259 requires, otherwise they are invalid
+	// JSON. By default decoding stops after the first value and the rest of
+	// the document is ignored.
+	Strict bool
+	// Encoding of the compared documents, JSON by default. Binary documents
+	// are decoded to the values JSON documents are decoded to and compared
+	// as usual: byte strings become base64 strings, as encoding/json
+	// marshals byte slices, map keys which aren't strings become their JSON
+	// text, e.g. "1", and non-finite floats are represented as by Lenient.
+	// CBOR tags are ignored, except bignums which become numbers.
+	// MessagePack timestamps become RFC 3339 strings, other extensions
+	// become {"type": type, "data": base64} objects. Lenient doesn't affect
+	// binary documents.
+	InputFormat InputFormat
+	// When true, object keys which are non-negative integers are ordered
+	// numerically in the text output, e.g. "2" goes before "10". Numeric keys
+	// go before all the other keys.
+	NumericKeyOrder bool
+	// When provided, this function orders object keys in the output instead
+	// of the lexical order and NumericKeyOrder. Order of keys only affects
+	// the output, objects are compared key by key regardless of it.
+	KeyOrder func(a, b string) bool
+	// Path patterns of arrays whose elements are compared regardless of their
+	// order. Elements of the array in the first document are rearranged to
+	// line up with equal elements of the array in the second document, the
+	// rest of them keep their relative order. Elements are equal when their
+	// JSON encodings are identical. Output describes the rearranged array, so
+	// indices of the first array's elements may differ from the document,
+	// while changes, JSON Patch and jd output replace such arrays as a whole.
+	// Arrays at the other paths are compared element by element.
+	UnorderedArrays []string
+	// Maps path patterns of arrays of objects to the name of a property which
+	// identifies their elements, e.g. {"users": "id"}. Such arrays are
+	// compared as objects indexed by values of the property, so that
+	// elements are matched by identity rather than position, and are
+	// rendered as objects. Elements which aren't objects, don't have the
+	// property or have a duplicate value of it are indexed by "#" followed
+	// by their position, key values starting with "#" are prefixed with
+	// another one, see ComparePages. Changes, JSON Patch and jd output
+	// replace such arrays as a whole, since keys are not valid paths in the
+	// documents. When several patterns match, the first one in lexicographic
+	// order is used.
+	KeyedArrays map[string]string
+	// Comparators of elements of arrays at paths matching the patterns. Such
+	// arrays in both documents are sorted with the comparator before they
+	// are compared, e.g. sets serialized in random order, while arrays at
+	// the other paths keep their order. Sorting is stable, elements are the
+	// decoded JSON values. Changes, JSON Patch and jd output replace such
+	// arrays as a whole. When several patterns match, the first one in
+	// lexicographic order is used.
+	SortArraysAt map[string]func(a, b interface{}) bool
+	// When enabled, only some elements of large arrays are compared: the
+	// first and the last ones along with a random sample, for quick checks
+	// of huge documents. Elements which aren't sampled are skipped silently
+	// and the result is approximate, see CompareWithSampling.
+	ArraySampling ArraySampling
+	// When true, objects with only non-negative integer keys are compared as
+	// arrays of their values ordered by key. Keys themselves are not compared.
+	// Changes, JSON Patch and jd output replace such objects as a whole.
+	NumericKeysAsArrays bool
+	// Controls how changed values are printed in the text output.
+	ChangedLayout ChangedLayout
+	// When true, DocumentOutput wraps the document into a header object with
+	// the verdict, its numeric code, counts of changes, library version and
+	// fingerprint of the options: {"verdict": "NoMatch", "code": 2, "counts":
+	// {"changed": 1, "added": 0, "removed": 0}, "version": "x.y.z",
+	// "fingerprint": "...", "diff": ...}. The "diff" is null when there is
+	// nothing to render. Approximate results of sampled arrays have
+	// "approximate": true, and "left" and "right" are the labels of the
+	// documents if they are set, see LeftLabel. See Version and
+	// Options.Fingerprint.
+	DocumentHeader bool
+	// When provided, this function is called for every object property and
+	// array element with the path of the value (see Compare documentation
+	// for the path syntax). Skipped values are not compared and don't affect
+	// the verdict. They are either rendered as matching values, or replaced
+	// with a placeholder highlighted with the Skipped tag, see SkipResult.
+	Skip func(path string) SkipResult
+	// Path patterns of values which are skipped silently, see Skip.
+	Ignore []string
+	// Values which are skipped silently regardless of their path, see Skip.
+	// A value is skipped when it's a string, number, boolean or null whose
+	// string form matches any of the expressions in every document where it's
+	// present, e.g. timestamps or generated identifiers.
+	IgnoreValuesMatching []*regexp.Regexp
+	// Paths of the values to compare instead of the whole documents, e.g.
+	// FirstRoot "data" and SecondRoot "" compare {"data": X} against X, while
+	// FirstRoot "result" and SecondRoot "data" compare {"result": X} against
+	// {"data": Y}. Paths are dot separated lists of object keys and array
+	// indices without wildcards. When the path doesn't exist in a document,
+	// the whole document is compared. Paths of changes, JSON Patch and jd
+	// output refer to the value at FirstRoot in the first document.
+	FirstRoot  string
+	SecondRoot string
+	// Maps object keys of the first document to the keys of the second one,
+	// so that renamed properties are compared to each other. Aliases apply at
+	// any depth. When the first document already has both the key and its
+	// alias, the key is not renamed. When several keys of an object have the
+	// same alias, only the first one in lexicographic order is renamed.
+	KeyAliases map[string]string
+	// When provided, object keys which are equal after applying this function
+	// are compared to each other, see KeyNormalizeCaseStyle. Keys are printed
+	// as they appear in the second document, or in the first one if there is
+	// no such key in the second document.
+	NormalizeKeys func(key string) string
+	// When provided, this function is applied to values of both documents
+	// before they are compared, starting from the root value and going down
+	// to the values it returns. Path uses the syntax of path patterns. Returned
+	// values must consist of the types produced by decoding JSON with
+	// json.Decoder.UseNumber: nil, bool, json.Number, string,
+	// []interface{} and map[string]interface{}.
+	Transform func(path string, v interface{}) interface{}
+	// When true, structured changes also include values which match as
+	// ChangeUnchanged entries, so that the whole documents can be
+	// reconstructed from them, see CompareWithChanges. Matching collections
+	// are reported as a single entry, skipped values are not reported.
+	IncludeUnchanged bool
+	// When true, JSONPatchOutput produces smaller patches: changes of
+	// collection elements are replaced with a single replace operation of
+	// the whole collection, added values are replaced with move operations of
+	// removed object properties and copy operations of unchanged values,
+	// whenever it makes the patch shorter.
+	CompactPatch bool
+	// When true, objects and arrays in the text output are annotated with the
+	// size of their compact JSON encoding, e.g. "(14.2KB)", or "(object,
+	// 14.2KB)" along with PrintTypes. When the size of a collection differs
+	// in the documents, both sizes are printed separated by
+	// ChangedSeparator.
+	PrintSizes bool
+	// Controls where messages about matching elements omitted because of
+	// SkipMatches are printed, see SkippedArrayElement and
+	// SkippedObjectProperty.
+	SkippedPlacement SkippedPlacement
+	// When true, every omitted element gets its own message instead of
+	// merging consecutive ones, or all of them depending on SkippedPlacement,
+	// into a single message with their number.
+	SeparateSkipped bool
+	// When positive, lines of the text output longer than this number of
+	// characters are wrapped and continued on the next line with an extra
+	// indentation. Tags are closed at the end of a wrapped line and reopened
+	// on the next one. Prefix, indentation and text count towards the width,
+	// while tags and glyphs don't.
+	MaxLineWidth int
+	// When positive, collections nested this deep or deeper which differ
+	// are summarized in the text output by counts of the changes inside
+	// them instead of their contents, e.g. "items": 37 changed, 2 added,
+	// for overviews of large differences. Depth 1 is of the properties and
+	// elements of the root value. Matching collections are printed as
+	// usual, other formats and CompareTree keep the full detail.
+	RollupDepth int
+	// Describes the changes of a summarized collection, see RollupDepth.
+	// RollupSummary is used if nil.
+	RollupSummary func(changed, added, removed int) string
+	// When true, values of object properties in the text output are aligned
+	// in a column after the longest key of the object, e.g.:
+	//
+	//	"name":     "api",
+	//	"replicas": 3
+	//
+	// which reads as a table for flat objects. Only keys printed on their
+	// own lines count, e.g. not the ones omitted because of SkipMatches.
+	AlignKeys bool
+	// Names of the first and the second documents, e.g. "expected" and
+	// "actual (staging)", so that readers of reports can tell which side is
+	// which. When any of them is set, the text output starts with a header:
+	//
+	//	--- expected
+	//	+++ actual (staging)
+	//
+	// unless there is nothing else to print, and changed values printed on
+	// a single line are followed by the label of their document, e.g.
+	// 1 (expected) => 2 (actual (staging)).
+	LeftLabel  string
+	RightLabel string
+	// Symbols printed at the beginning of every line of the text output,
+	// before Prefix. The glyph is selected by the first highlighted value on
+	// the line: added, removed, changed or skipped, otherwise the Normal
+	// glyph is used. Glyphs are not printed by default.
+	Glyphs Glyphs
+	// Wording of the fixed strings of the text output, EnglishLabels if nil.
+	Labels Labels
+	// Controls how strings and object keys are quoted in the text output.
+	// Other formats produce JSON with UTF-8 text.
+	QuoteMode QuoteMode
+	// When true, object keys which are identifiers, i.e. consist of letters,
+	// digits and underscores and don't start with a digit, are written
+	// without quotes in the text output, e.g. name: "api". Other keys are
+	// quoted according to QuoteMode. Only the output is affected.
+	UnquotedKeys bool
+	// When true, string values of the second document which are placeholder
+	// tokens, e.g. "<<PRESENCE>>" or "<<TYPE:number>>", match values of the
+	// first document they describe instead of being compared literally, see
+	// Placeholder. Matching values are rendered as they are in the first
+	// document.
+	Placeholders bool
+	// When provided, a line is written for every compared value describing
+	// the decision made for it: the quoted path, types of the value in the
+	// first and the second documents ("-" if it's missing) and the decision:
+	// match, changed, added, removed, skipped, or collection followed by
+	// "matches" or "differs". Collections are written after their elements,
+	// e.g.:
+	//
+	//	"a.0" number number changed
+	//	"a" array array collection differs
+	//
+	// Tracing disables QuickFullMatch and the early bailout of Verdict.
+	Trace io.Writer
+	// Custom comparison rules of values selected by path or by shape, see
+	// Comparator. The first applicable comparator is used.
+	Comparators []Comparator
+	// Rules of differences of arrays and numbers which don't affect the
+	// verdict, see Tolerance. The first applicable rule of each kind is used.
+	Tolerance []Tolerance
+	// Equality functions of Go types, which are used by CompareInterfaces
+	// instead of encoding values of the types as JSON, e.g. to compare
+	// time.Time values regardless of their locations. Such values are equal
+	// if both documents have values of the same type at the path and the
+	// function returns true for them. They are rendered as their JSON
+	// encoding, or as formatted by fmt if they can't be encoded.
+	TypeComparators map[reflect.Type]func(a, b interface{}) bool
+	// When true, CompareInterfaces encodes Go values it doesn't convert
+	// itself, e.g. structs, using encoding/json instead of failing.
+	MarshalUnknownValues bool
+	// Selects how the difference is rendered. By default it's a human-readable
+	// text similar to pretty printed JSON.
+	Format OutputFormat
 }
 
 func SkippedArrayElement(n int) string {
@@ -122,153 +533,264 @@ func DefaultHTMLOptions() Options {
 }
 
 type context struct {
-	opts    *Options
-	level   int
-	lastTag *Tag
-	diff    Difference
+	opts  *Options
+	level int
+	w     DiffWriter
+	diff  Difference
+	// path of the value being compared
+	path []string
+	// when true, reasons of verdict downgrades are collected
+	collectReasons bool
+	reasons        []Reason
+	// when not nil, internal structures are allocated in the arena
+	arena *Arena
+	// cached sizes of the encoded collections, see encodedSize
+	sizes map[collectionID]int
+	// true if any array was sampled, see Options.ArraySampling
+	sampled bool
+	// widths of the keys of the objects being printed, see Options.AlignKeys
+	keyWidths []int
+	// values of the documents before collections were rewritten for the
+	// comparison, see rewritten
+	originals map[*delta]originalValues
+	// JSON Pointer of the compared value in the first document, paths of
+	// changes and patches start with it, see Options.FirstRoot
+	root string
+	// the same path in the jd format, see renderJD
+	rootPath []interface{}
 }
 
-func (ctx *context) compareNumbers(a, b json.Number) bool {
-	if ctx.opts.CompareNumbers != nil {
-		return ctx.opts.CompareNumbers(a, b)
-	} else {
-		return a == b
-	}
+type originalValues struct {
+	a, b interface{}
 }
 
-func (ctx *context) terminateTag(buf *bytes.Buffer) {
-	if ctx.lastTag != nil {
-		buf.WriteString(ctx.lastTag.End)
-		ctx.lastTag = nil
+// rewritten records the original values of a collection which is compared in
+// a different shape, e.g. a keyed array compared as an object. Paths inside of
+// such collections don't exist in the documents, so changes and patches
+// replace them as a whole.
+func (ctx *context) rewritten(d *delta, a, b interface{}) {
+	if ctx.originals == nil {
+		ctx.originals = make(map[*delta]originalValues)
 	}
+	ctx.originals[d] = originalValues{a: a, b: b}
 }
 
-func (ctx *context) newline(buf *bytes.Buffer, s string) {
-	buf.WriteString(s)
-	if ctx.lastTag != nil {
-		buf.WriteString(ctx.lastTag.End)
-	}
-	buf.WriteString("\n")
-	buf.WriteString(ctx.opts.Prefix)
-	for i := 0; i < ctx.level; i++ {
-		buf.WriteString(ctx.opts.Indent)
-	}
-	if ctx.lastTag != nil {
-		buf.WriteString(ctx.lastTag.Begin)
+func (ctx *context) compareNumbers(a, b json.Number) bool {
+	if ctx.opts.CompareNumbers != nil {
+		return ctx.opts.CompareNumbers(a, b)
+	} else if ctx.opts.DecimalPlaces > 0 {
+		return decimalEqual(a, b, ctx.opts.DecimalPlaces)
+	} else {
+		return a == b
 	}
 }
 
-func (ctx *context) key(buf *bytes.Buffer, k string) {
-	buf.WriteString(strconv.Quote(k))
-	buf.WriteString(": ")
+func (ctx *context) newline(s string) {
+	ctx.w.Text(s)
+	ctx.w.Newline(ctx.level)
 }
 
-func (ctx *context) writeValue(buf *bytes.Buffer, v interface{}, full bool) {
+func (ctx *context) writeValue(v interface{}, full bool) {
 	switch vv := v.(type) {
-	case bool:
-		buf.WriteString(strconv.FormatBool(vv))
-	case json.Number:
-		buf.WriteString(string(vv))
-	case string:
-		buf.WriteString(strconv.Quote(vv))
 	case []interface{}:
 		if full {
 			if len(vv) == 0 {
-				buf.WriteString("[")
+				ctx.w.Text("[")
 			} else {
 				ctx.level++
-				ctx.newline(buf, "[")
+				ctx.newline("[")
 			}
 			for i, v := range vv {
-				ctx.writeValue(buf, v, true)
+				ctx.writeValue(v, true)
 				if i != len(vv)-1 {
-					ctx.newline(buf, ",")
+					ctx.newline(",")
 				} else {
 					ctx.level--
-					ctx.newline(buf, "")
+					ctx.newline("")
 				}
 			}
-			buf.WriteString("]")
+			ctx.w.Text("]")
 		} else {
-			buf.WriteString("[]")
+			ctx.w.Text("[]")
 		}
 	case map[string]interface{}:
 		if full {
 			if len(vv) == 0 {
-				buf.WriteString("{")
+				ctx.w.Text("{")
 			} else {
 				ctx.level++
-				ctx.newline(buf, "{")
+				ctx.newline("{")
 			}
 
 			keys := make([]string, 0, len(vv))
 			for key := range vv {
 				keys = append(keys, key)
 			}
-			sort.Strings(keys)
+			ctx.sortKeys(keys)
+			ctx.pushKeyWidth(keys, func(int) bool { return true })
+			defer ctx.popKeyWidth()
 
 			i := 0
 			for _, k := range keys {
 				v := vv[k]
-				ctx.key(buf, k)
-				ctx.writeValue(buf, v, true)
+				ctx.writeKey(k)
+				ctx.writeValue(v, true)
 				if i != len(vv)-1 {
-					ctx.newline(buf, ",")
+					ctx.newline(",")
 				} else {
 					ctx.level--
-					ctx.newline(buf, "")
+					ctx.newline("")
 				}
 				i++
 			}
-			buf.WriteString("}")
+			ctx.w.Text("}")
 		} else {
-			buf.WriteString("{}")
+			ctx.w.Text("{}")
 		}
 	default:
-		buf.WriteString("null")
+		ctx.w.Scalar(v)
 	}
 
-	ctx.writeTypeMaybe(buf, v)
+	ctx.writeTypeMaybe(v)
 }
 
-func (ctx *context) writeTypeMaybe(buf *bytes.Buffer, v interface{}) {
+func (ctx *context) writeTypeMaybe(v interface{}) {
+	ctx.writeAnnotations(v, v)
+}
+
+// writeAnnotations writes the type and the size of a value, see PrintTypes
+// and PrintSizes. When the value differs in the documents, a is the value in
+// the first document and b is the value in the second one.
+func (ctx *context) writeAnnotations(a, b interface{}) {
+	sizes := ctx.opts.PrintSizes && isCollection(a)
+	if !ctx.opts.PrintTypes && !sizes {
+		return
+	}
+	ctx.w.Text(" (")
 	if ctx.opts.PrintTypes {
-		buf.WriteString(" ")
-		ctx.writeType(buf, v)
+		ctx.w.Text(ctx.labels().TypeName(typeName(a)))
+	}
+	if sizes {
+		if ctx.opts.PrintTypes {
+			ctx.w.Text(", ")
+		}
+		sa := ctx.encodedSize(a)
+		ctx.w.Text(formatSize(sa))
+		if isCollection(b) {
+			if sb := ctx.encodedSize(b); sb != sa {
+				ctx.w.Text(ctx.opts.ChangedSeparator)
+				ctx.w.Text(formatSize(sb))
+			}
+		}
 	}
+	ctx.w.Text(")")
 }
 
-func (ctx *context) writeType(buf *bytes.Buffer, v interface{}) {
+func isCollection(v interface{}) bool {
+	switch v.(type) {
+	case []interface{}, map[string]interface{}:
+		return true
+	}
+	return false
+}
+
+func typeName(v interface{}) string {
 	switch v.(type) {
 	case bool:
-		buf.WriteString("(boolean)")
+		return "boolean"
 	case json.Number:
-		buf.WriteString("(number)")
+		return "number"
 	case string:
-		buf.WriteString("(string)")
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "null"
+}
+
+// collectionID identifies a decoded collection, see encodedSize.
+type collectionID struct {
+	ptr uintptr
+	len int
+}
+
+// encodedSize returns the size of the compact JSON encoding of v. Sizes of
+// collections are cached, so that annotating all the nested collections
+// takes linear time.
+func (ctx *context) encodedSize(v interface{}) int {
+	var id collectionID
+	switch vv := v.(type) {
 	case []interface{}:
-		buf.WriteString("(array)")
+		id = collectionID{reflect.ValueOf(vv).Pointer(), len(vv)}
 	case map[string]interface{}:
-		buf.WriteString("(object)")
+		id = collectionID{reflect.ValueOf(vv).Pointer(), len(vv)}
 	default:
-		buf.WriteString("(null)")
+		return len(encodeJSON(v, "", ""))
+	}
+	if n, ok := ctx.sizes[id]; ok {
+		return n
+	}
+	// brackets and separators
+	n := 2
+	switch vv := v.(type) {
+	case []interface{}:
+		for _, e := range vv {
+			n += ctx.encodedSize(e)
+		}
+		if len(vv) > 1 {
+			n += len(vv) - 1
+		}
+	case map[string]interface{}:
+		for k, e := range vv {
+			n += len(encodeJSON(k, "", "")) + 1 + ctx.encodedSize(e)
+		}
+		if len(vv) > 1 {
+			n += len(vv) - 1
+		}
+	}
+	if ctx.sizes == nil {
+		ctx.sizes = make(map[collectionID]int)
 	}
+	ctx.sizes[id] = n
+	return n
 }
 
-func (ctx *context) writeMismatch(buf *bytes.Buffer, a, b interface{}) {
-	ctx.writeValue(buf, a, false)
-	buf.WriteString(ctx.opts.ChangedSeparator)
-	ctx.writeValue(buf, b, false)
+// formatSize formats a number of bytes, e.g. 14.2KB.
+func formatSize(n int) string {
+	switch {
+	case n < 1024:
+		return strconv.Itoa(n) + "B"
+	case n < 1024*1024:
+		return strconv.FormatFloat(float64(n)/1024, 'f', 1, 64) + "KB"
+	}
+	return strconv.FormatFloat(float64(n)/(1024*1024), 'f', 1, 64) + "MB"
 }
 
-func (ctx *context) tag(buf *bytes.Buffer, tag *Tag) {
-	if ctx.lastTag == tag {
-		return
-	} else if ctx.lastTag != nil {
-		buf.WriteString(ctx.lastTag.End)
+func (ctx *context) writeMismatch(a, b interface{}) {
+	ctx.writeValue(a, false)
+	ctx.writeSideLabel(ctx.opts.LeftLabel)
+	ctx.w.Text(ctx.opts.ChangedSeparator)
+	ctx.writeValue(b, false)
+	ctx.writeSideLabel(ctx.opts.RightLabel)
+}
+
+// headerLine returns a line of the header of the text output, see
+// Options.LeftLabel.
+func headerLine(marker, label string) string {
+	if label == "" {
+		return marker
+	}
+	return marker + " " + label
+}
+
+// writeSideLabel writes the label of the document after its value, see
+// Options.LeftLabel.
+func (ctx *context) writeSideLabel(label string) {
+	if label != "" {
+		ctx.w.Text(" (" + label + ")")
 	}
-	buf.WriteString(tag.Begin)
-	ctx.lastTag = tag
 }
 
 func (ctx *context) result(d Difference) {
@@ -281,117 +803,322 @@ func (ctx *context) result(d Difference) {
 	}
 }
 
-func (ctx *context) printMismatch(buf *bytes.Buffer, a, b interface{}) {
-	ctx.tag(buf, &ctx.opts.Changed)
-	ctx.writeMismatch(buf, a, b)
+func (ctx *context) printMismatch(a, b interface{}) {
+	ctx.w.Tag(ChangedTag)
+	ctx.writeMismatch(a, b)
+}
+
+func (ctx *context) separateLines(d *delta) bool {
+	return d.kind == deltaChanged && ctx.opts.ChangedLayout == ChangedSeparateLines
 }
 
-func (ctx *context) printSkipped(buf *bytes.Buffer, n *int, strfunc func(n int) string, last bool) {
+// printSeparateLines prints changed value e as a removal followed by an
+// addition. When e is an i-th element of the parent collection, its key is
+// repeated on both lines.
+func (ctx *context) printSeparateLines(e, parent *delta, i int) {
+	ctx.w.Tag(RemovedTag)
+	if parent != nil {
+		ctx.elemKey(parent, i)
+	}
+	ctx.writeValue(e.a, true)
+	ctx.w.Tag(NoTag)
+	ctx.newline("")
+	ctx.w.Tag(AddedTag)
+	if parent != nil {
+		ctx.elemKey(parent, i)
+	}
+	ctx.writeValue(e.b, true)
+	ctx.w.Tag(NoTag)
+}
+
+func (ctx *context) printSkipped(n *int, strfunc func(n int) string, last bool) {
 	if *n == 0 || strfunc == nil {
 		return
 	}
-	ctx.tag(buf, &ctx.opts.Skipped)
-	buf.WriteString(strfunc(*n))
-	if !last {
-		ctx.tag(buf, &ctx.opts.Normal)
-		ctx.newline(buf, ",")
+	messages, count := 1, *n
+	if ctx.opts.SeparateSkipped {
+		messages, count = *n, 1
+	}
+	for i := 0; i < messages; i++ {
+		ctx.w.Tag(SkippedTag)
+		ctx.w.Text(strfunc(count))
+		if !last || i < messages-1 {
+			ctx.w.Tag(NormalTag)
+			ctx.newline(",")
+		}
 	}
 	*n = 0
 }
 
-func (ctx *context) finalize(buf *bytes.Buffer) string {
-	ctx.terminateTag(buf)
-	return buf.String()
-}
+type deltaKind int
 
-type collectionConfig struct {
-	open    string
-	close   string
-	skipped func(n int) string
-	value   interface{}
+const (
+	// values are equal
+	deltaMatch deltaKind = iota
+	// values are present on both sides, but differ
+	deltaChanged
+	// value is present only in the second document
+	deltaAdded
+	// value is present only in the first document
+	deltaRemoved
+	// both values are arrays or both are objects, see elems
+	deltaCollection
+	// value is skipped by Options.Skip, a is the value of the first document
+	// if it's present and the value of the second document otherwise
+	deltaSkipped
+)
+
+// delta is a result of comparing two decoded JSON values. Comparison is done
+// once and then the tree is handed over to one of the renderers.
+type delta struct {
+	kind deltaKind
+	a    interface{}
+	b    interface{}
+	// object keys, nil for arrays
+	keys  []string
+	elems []*delta
+	// true if there is a difference anywhere in this subtree
+	differs bool
+	// for skipped values, empty if value is skipped silently
+	placeholder string
+	// for skipped values, true if the value is missing in the corresponding
+	// document
+	aMissing bool
+	bMissing bool
+	// true if the value produces any output in the text format, see
+	// markOutput
+	output bool
 }
 
-type dualIterator interface {
-	clone() dualIterator
-	count() int
-	next() (a interface{}, aOK bool, b interface{}, bOK bool, i int)
-	key(buf *bytes.Buffer)
+func (d *delta) isObject() bool {
+	return d.keys != nil
 }
 
-type dualSliceIterator struct {
-	a       []interface{}
-	b       []interface{}
-	max     int
-	current int
+func (ctx *context) equalLeaves(a, b interface{}) bool {
+	switch aa := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		bb, ok := b.(bool)
+		return ok && aa == bb
+	case json.Number:
+		bb, ok := b.(json.Number)
+		return ok && ctx.compareNumbers(aa, bb)
+	case string:
+		bb, ok := b.(string)
+		return ok && aa == bb
+	}
+	return false
 }
 
-func (it *dualSliceIterator) clone() dualIterator {
-	copy := *it
-	return &copy
+func (ctx *context) compare(a, b interface{}) *delta {
+	oa, ob := a, b
+	rewritten := false
+	if ctx.opts.NumericKeysAsArrays {
+		a, b = numericKeysToArray(a), numericKeysToArray(b)
+		rewritten = isArray(a) && !isArray(oa) || isArray(b) && !isArray(ob)
+	}
+	if len(ctx.opts.KeyedArrays) != 0 {
+		var keyed bool
+		a, b, keyed = ctx.keyArrays(a, b)
+		rewritten = rewritten || keyed
+	}
+	d := ctx.compareValues(a, b)
+	if rewritten {
+		ctx.rewritten(d, oa, ob)
+	}
+	return d
 }
 
-func (it *dualSliceIterator) count() int {
-	return it.max
+func (ctx *context) compareValues(a, b interface{}) *delta {
+	if p, ok := ctx.placeholder(b); ok && p.Match(a) {
+		ctx.result(FullMatch)
+		return ctx.newDelta(delta{kind: deltaMatch, a: a, b: b})
+	}
+	if m, ok := b.(Matcher); ok {
+		if m.Match(a) {
+			ctx.result(FullMatch)
+			return ctx.newDelta(delta{kind: deltaMatch, a: a, b: b})
+		}
+		ctx.downgrade(ValueChanged)
+		return ctx.newDelta(delta{kind: deltaChanged, a: a, b: b, differs: true})
+	}
+	if c := ctx.comparator(a, b); c != nil {
+		return ctx.compareWith(c, a, b)
+	}
+	return ctx.compareStructure(a, b)
 }
 
-func (it *dualSliceIterator) next() (a interface{}, aOK bool, b interface{}, bOK bool, i int) {
-	it.current++
-	i = it.current
-	if i <= it.max {
-		if i < len(it.a) {
-			a = it.a[i]
-			aOK = true
+// compareStructure compares values by their types, collections are compared
+// element by element.
+func (ctx *context) compareStructure(a, b interface{}) *delta {
+	switch aa := a.(type) {
+	case []interface{}:
+		if bb, ok := b.([]interface{}); ok {
+			return ctx.compareSlices(aa, bb)
 		}
-		if i < len(it.b) {
-			b = it.b[i]
-			bOK = true
+	case map[string]interface{}:
+		if bb, ok := b.(map[string]interface{}); ok {
+			return ctx.compareMaps(aa, bb)
 		}
-	} else {
-		i = -1
+	default:
+		if ctx.equalLeaves(a, b) {
+			ctx.result(FullMatch)
+			return ctx.newDelta(delta{kind: deltaMatch, a: a, b: b})
+		}
+	}
+	if ctx.withinDrift(a, b) {
+		// rendered as changed, but tolerated by the verdict
+		ctx.result(FullMatch)
+		return ctx.newDelta(delta{kind: deltaChanged, a: a, b: b, differs: true})
 	}
-	return
+	// either leaf values are different or Go types do not match, this is
+	// definitely a mismatch since we parse JSON into interface{}
+	ctx.downgrade(ValueChanged)
+	return ctx.newDelta(delta{kind: deltaChanged, a: a, b: b, differs: true})
 }
 
-func (it *dualSliceIterator) key(buf *bytes.Buffer) {
-	// noop
+// compareElem compares collection elements, when the element is missing on one
+// of the sides and it's optional, verdict is not affected.
+func (ctx *context) compareElem(a interface{}, aOK bool, b interface{}, bOK bool, optional bool) *delta {
+	if r := ctx.skip(a, aOK, b, bOK); r.skip {
+		return ctx.skippedDelta(a, aOK, b, bOK, r.placeholder)
+	}
+	if aOK {
+		a = ctx.transform(a)
+	}
+	if bOK {
+		b = ctx.transform(b)
+	}
+	if aOK && bOK {
+		return ctx.compare(a, b)
+	} else if aOK {
+		if !optional {
+			ctx.downgrade(ValueRemoved)
+		}
+		return ctx.newDelta(delta{kind: deltaRemoved, a: a, differs: true})
+	} else {
+		if !optional {
+			ctx.downgrade(ValueAdded)
+		}
+		return ctx.newDelta(delta{kind: deltaAdded, b: b, differs: true})
+	}
 }
 
-type dualMapIterator struct {
-	a       map[string]interface{}
-	b       map[string]interface{}
-	keys    []string
-	current int
+func (ctx *context) skippedDelta(a interface{}, aOK bool, b interface{}, bOK bool, placeholder string) *delta {
+	d := ctx.newDelta(delta{kind: deltaSkipped, a: a, b: b, placeholder: placeholder, aMissing: !aOK, bMissing: !bOK})
+	if !aOK {
+		d.a = b
+	}
+	return d
 }
 
-func (it *dualMapIterator) clone() dualIterator {
-	copy := *it
-	return &copy
+// compareEmpty applies EmptyCollectionMode to a non-empty first collection and
+// an empty second one. Returns nil if collections have to be compared as
+// usual.
+func (ctx *context) compareEmpty(mode EmptyCollectionMode, a, b interface{}) *delta {
+	switch mode {
+	case EmptyCollectionMatchesAny:
+		ctx.result(FullMatch)
+		return ctx.newDelta(delta{kind: deltaMatch, a: a, b: b})
+	case EmptyCollectionMatchesEmpty:
+		// all the elements are reported as removed, but it's not a
+		// superset match anymore
+		ctx.downgrade(CollectionNotEmpty)
+	}
+	return nil
 }
 
-func (it *dualMapIterator) count() int {
-	return len(it.keys)
+func (ctx *context) compareSlices(a, b []interface{}) *delta {
+	verdict := ctx.saveArrayVerdict()
+	if len(a) != 0 && len(b) == 0 {
+		if d := ctx.compareEmpty(ctx.opts.EmptyArray, a, b); d != nil {
+			return d
+		}
+	}
+	oa, ob := a, b
+	rewritten := false
+	if len(ctx.opts.SortArraysAt) != 0 {
+		if less := ctx.arrayOrder(); less != nil {
+			a, b = sortElems(a, less), sortElems(b, less)
+			rewritten = true
+		}
+	}
+	if len(ctx.opts.UnorderedArrays) != 0 && ctx.pathMatches(ctx.opts.UnorderedArrays) {
+		a = alignElems(a, b)
+		rewritten = true
+	}
+	max := len(a)
+	if len(b) > max {
+		max = len(b)
+	}
+	sample := ctx.opts.ArraySampling.sample(len(a), len(b))
+	if sample != nil {
+		ctx.sampled = true
+	}
+	d := ctx.newDelta(delta{kind: deltaCollection, a: a, b: b, elems: ctx.newElems(max)})
+	for i := 0; i < max; i++ {
+		var va, vb interface{}
+		if i < len(a) {
+			va = a[i]
+		}
+		if i < len(b) {
+			vb = b[i]
+		}
+		if sample != nil && !sample[i] {
+			// elements which aren't sampled are skipped silently
+			d.elems = append(d.elems, ctx.skippedDelta(va, i < len(a), vb, i < len(b), ""))
+			continue
+		}
+		ctx.pushPath(strconv.Itoa(i))
+		e := ctx.compareElem(va, i < len(a), vb, i < len(b), false)
+		ctx.trace(e)
+		ctx.popPath()
+		d.differs = d.differs || e.differs
+		d.elems = append(d.elems, e)
+	}
+	ctx.tolerateElements(verdict, d)
+	if rewritten {
+		ctx.rewritten(d, oa, ob)
+	}
+	return d
 }
 
-func (it *dualMapIterator) next() (a interface{}, aOK bool, b interface{}, bOK bool, i int) {
-	it.current++
-	i = it.current
-	if i < len(it.keys) {
-		key := it.keys[i]
-		a, aOK = it.a[key]
-		b, bOK = it.b[key]
-	} else {
-		i = -1
+func (ctx *context) compareMaps(a, b map[string]interface{}) *delta {
+	a = aliasKeys(a, ctx.opts.KeyAliases)
+	if ctx.opts.NormalizeKeys != nil {
+		a, b = normalizeKeys(a, b, ctx.opts.NormalizeKeys)
+	}
+	if len(a) != 0 && len(b) == 0 {
+		if d := ctx.compareEmpty(ctx.opts.EmptyObject, a, b); d != nil {
+			return d
+		}
+	}
+	keys := unionKeys(a, b)
+	ctx.sortKeys(keys)
+	d := ctx.newDelta(delta{kind: deltaCollection, a: a, b: b, keys: keys, elems: ctx.newElems(len(keys))})
+	for _, k := range keys {
+		e := ctx.compareProperty(a, b, k)
+		d.differs = d.differs || e.differs
+		d.elems = append(d.elems, e)
 	}
-	return
+	return d
 }
 
-func (it *dualMapIterator) key(buf *bytes.Buffer) {
-	key := it.keys[it.current]
-	buf.WriteString(strconv.Quote(key))
-	buf.WriteString(": ")
+// compareProperty compares values of the key, it may be missing in one of the
+// objects.
+func (ctx *context) compareProperty(a, b map[string]interface{}, k string) *delta {
+	va, aOK := a[k]
+	vb, bOK := b[k]
+	ctx.pushPath(k)
+	optional := aOK != bOK && ctx.pathMatches(ctx.opts.OptionalKeys)
+	e := ctx.compareElem(va, aOK, vb, bOK, optional)
+	ctx.trace(e)
+	ctx.popPath()
+	return e
 }
 
-func makeDualMapIterator(a, b map[string]interface{}) dualIterator {
+func unionKeys(a, b map[string]interface{}) []string {
 	keysMap := make(map[string]struct{})
 	for k := range a {
 		keysMap[k] = struct{}{}
@@ -403,208 +1130,223 @@ func makeDualMapIterator(a, b map[string]interface{}) dualIterator {
 	for k := range keysMap {
 		keys = append(keys, k)
 	}
-	sort.Strings(keys)
-	return &dualMapIterator{
-		a:       a,
-		b:       b,
-		keys:    keys,
-		current: -1,
-	}
+	return keys
 }
 
-func makeDualSliceIterator(a, b []interface{}) dualIterator {
-	max := len(a)
-	if len(b) > max {
-		max = len(b)
-	}
-	return &dualSliceIterator{
-		a:       a,
-		b:       b,
-		max:     max,
-		current: -1,
+type collectionConfig struct {
+	open    string
+	close   string
+	skipped func(n int) string
+	a       interface{}
+	b       interface{}
+}
+
+func (ctx *context) elemKey(d *delta, i int) {
+	if d.isObject() {
+		ctx.writeKey(d.keys[i])
 	}
 }
 
-func (ctx *context) collectDiffs(it dualIterator) (diffs []string, last int) {
-	ctx.level++
-	last = -1
-	for {
-		a, aok, b, bok, i := it.next()
-		if i == -1 {
-			break
-		}
-		var diff string
-		if aok && bok {
-			diff = ctx.printDiff(a, b)
-		}
-		if len(diff) > 0 || aok != bok {
-			last = i
+// markOutput marks values which produce any output in the text format.
+func (ctx *context) markOutput(d *delta) bool {
+	switch d.kind {
+	case deltaMatch:
+		d.output = !ctx.opts.SkipMatches
+	case deltaSkipped:
+		d.output = d.placeholder != "" || !ctx.opts.SkipMatches
+	case deltaCollection:
+		d.output = !ctx.opts.SkipMatches
+		for _, e := range d.elems {
+			if ctx.markOutput(e) {
+				d.output = true
+			}
 		}
-		diffs = append(diffs, diff)
+	default:
+		d.output = true
 	}
-	ctx.level--
-	return
+	return d.output
 }
 
-func (ctx *context) printCollectionDiff(cfg *collectionConfig, it dualIterator) string {
-	var buf bytes.Buffer
-	diffs, lastDiff := ctx.collectDiffs(it.clone())
-	if ctx.opts.SkipMatches && lastDiff == -1 {
+func (ctx *context) printCollectionDiff(cfg *collectionConfig, d *delta) {
+	if !d.output {
 		// no diffs
-		return ""
+		return
+	}
+	firstDiff, lastDiff := -1, -1
+	for i, e := range d.elems {
+		if e.output {
+			if firstDiff < 0 {
+				firstDiff = i
+			}
+			lastDiff = i
+		}
 	}
 
 	// some diffs or empty collection
-	ctx.tag(&buf, &ctx.opts.Normal)
-	if it.count() == 0 {
-		buf.WriteString(cfg.open)
-		buf.WriteString(cfg.close)
-		ctx.writeTypeMaybe(&buf, cfg.value)
-		return ctx.finalize(&buf)
+	ctx.w.Tag(NormalTag)
+	ctx.pushKeyWidth(d.keys, func(i int) bool { return d.elems[i].output })
+	defer ctx.popKeyWidth()
+	count := len(d.elems)
+	if count == 0 {
+		ctx.w.Text(cfg.open)
+		ctx.w.Text(cfg.close)
+		ctx.writeAnnotations(cfg.a, cfg.b)
+		return
 	} else {
 		ctx.level++
-		ctx.newline(&buf, cfg.open)
+		ctx.newline(cfg.open)
+	}
+
+	// with a merged message, its number of elements is known in advance
+	inPlace := ctx.opts.SkippedPlacement == SkippedInPlace
+	merged, leading := 0, 0
+	if !inPlace && ctx.opts.SkipMatches && cfg.skipped != nil {
+		for i, e := range d.elems {
+			switch {
+			case e.output:
+			case ctx.opts.SkippedPlacement == SkippedAround && i < firstDiff:
+				leading++
+			default:
+				merged++
+			}
+		}
+	}
+	switch ctx.opts.SkippedPlacement {
+	case SkippedBefore:
+		ctx.printSkipped(&merged, cfg.skipped, lastDiff < 0)
+	case SkippedAround:
+		ctx.printSkipped(&leading, cfg.skipped, false)
 	}
 
 	noDiffSpan := 0
-	for {
-		va, aok, vb, bok, i := it.next()
+	for i, e := range d.elems {
 		equals := true
-		if aok && bok {
-			diff := diffs[i]
-			if len(diff) > 0 {
-				equals = false
-				ctx.printSkipped(&buf, &noDiffSpan, cfg.skipped, false)
-				it.key(&buf)
-				buf.WriteString(diff)
-			}
-		} else if aok {
+		switch e.kind {
+		case deltaRemoved:
 			equals = false
-			ctx.printSkipped(&buf, &noDiffSpan, cfg.skipped, false)
-			ctx.tag(&buf, &ctx.opts.Removed)
-			it.key(&buf)
-			ctx.writeValue(&buf, va, true)
-			ctx.result(SupersetMatch)
-		} else if bok {
+			ctx.printSkipped(&noDiffSpan, cfg.skipped, false)
+			ctx.w.Tag(RemovedTag)
+			ctx.elemKey(d, i)
+			ctx.writeValue(e.a, true)
+		case deltaAdded:
 			equals = false
-			ctx.printSkipped(&buf, &noDiffSpan, cfg.skipped, false)
-			ctx.tag(&buf, &ctx.opts.Added)
-			it.key(&buf)
-			ctx.writeValue(&buf, vb, true)
-			ctx.result(NoMatch)
+			ctx.printSkipped(&noDiffSpan, cfg.skipped, false)
+			ctx.w.Tag(AddedTag)
+			ctx.elemKey(d, i)
+			ctx.writeValue(e.b, true)
+		default:
+			if e.output {
+				equals = false
+				ctx.printSkipped(&noDiffSpan, cfg.skipped, false)
+				if ctx.separateLines(e) {
+					ctx.printSeparateLines(e, d, i)
+				} else {
+					ctx.elemKey(d, i)
+					ctx.printDelta(e)
+				}
+			}
 		}
-		if ctx.opts.SkipMatches && equals {
+		if ctx.opts.SkipMatches && equals && inPlace {
 			noDiffSpan++
 		}
 
 		wroteItem := !ctx.opts.SkipMatches || !equals
 		willWriteMoreItems :=
 			(ctx.opts.SkipMatches && i < lastDiff) ||
-				(ctx.opts.SkipMatches && cfg.skipped != nil && lastDiff < it.count()-1) ||
-				(!ctx.opts.SkipMatches && i < it.count()-1)
+				(ctx.opts.SkipMatches && cfg.skipped != nil && inPlace && lastDiff < count-1) ||
+				(ctx.opts.SkipMatches && merged != 0) ||
+				(!ctx.opts.SkipMatches && i < count-1)
 
 		if wroteItem && willWriteMoreItems {
-			ctx.tag(&buf, &ctx.opts.Normal)
-			ctx.newline(&buf, ",")
-		}
-		if i == it.count()-1 {
-			// we're done
-			ctx.printSkipped(&buf, &noDiffSpan, cfg.skipped, true)
-			ctx.level--
-			ctx.tag(&buf, &ctx.opts.Normal)
-			ctx.newline(&buf, "")
-			break
+			ctx.w.Tag(NormalTag)
+			ctx.newline(",")
 		}
 	}
 
-	buf.WriteString(cfg.close)
-	ctx.writeTypeMaybe(&buf, cfg.value)
-	return ctx.finalize(&buf)
-}
+	// we're done
+	ctx.printSkipped(&noDiffSpan, cfg.skipped, true)
+	ctx.printSkipped(&merged, cfg.skipped, true)
+	ctx.level--
+	ctx.w.Tag(NormalTag)
+	ctx.newline("")
 
-func (ctx *context) printDiff(a, b interface{}) string {
-	var buf bytes.Buffer
+	ctx.w.Text(cfg.close)
+	ctx.writeAnnotations(cfg.a, cfg.b)
+}
 
-	if a == nil || b == nil {
-		// either is nil, means there are just two cases:
-		// 1. both are nil => match
-		// 2. one of them is nil => mismatch
-		if a == nil && b == nil {
-			// match
-			if !ctx.opts.SkipMatches {
-				ctx.tag(&buf, &ctx.opts.Normal)
-				ctx.writeValue(&buf, a, false)
-				ctx.result(FullMatch)
-			}
-		} else {
-			// mismatch
-			ctx.printMismatch(&buf, a, b)
-			ctx.result(NoMatch)
-		}
-		return ctx.finalize(&buf)
-	}
-
-	ka := reflect.TypeOf(a).Kind()
-	kb := reflect.TypeOf(b).Kind()
-	if ka != kb {
-		// Go type does not match, this is definitely a mismatch since
-		// we parse JSON into interface{}
-		ctx.printMismatch(&buf, a, b)
-		ctx.result(NoMatch)
-		return ctx.finalize(&buf)
-	}
-
-	// big switch here handles type-specific mismatches and returns if that's the case
-	// buf if control flow goes past through this switch, it's a match
-	// NOTE: ka == kb at this point
-	switch ka {
-	case reflect.Bool:
-		if a.(bool) != b.(bool) {
-			ctx.printMismatch(&buf, a, b)
-			ctx.result(NoMatch)
-			return ctx.finalize(&buf)
-		}
-	case reflect.String:
-		// string can be a json.Number here too (because it's a string type)
-		switch aa := a.(type) {
-		case json.Number:
-			bb, ok := b.(json.Number)
-			if !ok || !ctx.compareNumbers(aa, bb) {
-				ctx.printMismatch(&buf, a, b)
-				ctx.result(NoMatch)
-				return ctx.finalize(&buf)
-			}
-		case string:
-			bb, ok := b.(string)
-			if !ok || aa != bb {
-				ctx.printMismatch(&buf, a, b)
-				ctx.result(NoMatch)
-				return ctx.finalize(&buf)
-			}
+func (ctx *context) printDelta(d *delta) {
+	switch d.kind {
+	case deltaChanged:
+		if ctx.separateLines(d) {
+			ctx.printSeparateLines(d, nil, 0)
+			return
+		}
+		ctx.printMismatch(d.a, d.b)
+	case deltaCollection:
+		if ctx.opts.RollupDepth > 0 && ctx.level >= ctx.opts.RollupDepth && d.differs {
+			ctx.printRollup(d)
+			return
+		}
+		if d.isObject() {
+			ctx.printCollectionDiff(&collectionConfig{
+				open:    "{",
+				close:   "}",
+				skipped: ctx.opts.SkippedObjectProperty,
+				a:       d.a,
+				b:       d.b,
+			}, d)
+			return
 		}
-	case reflect.Slice:
-		sa, sb := a.([]interface{}), b.([]interface{})
-		return ctx.printCollectionDiff(&collectionConfig{
+		ctx.printCollectionDiff(&collectionConfig{
 			open:    "[",
 			close:   "]",
 			skipped: ctx.opts.SkippedArrayElement,
-			value:   a,
-		}, makeDualSliceIterator(sa, sb))
-	case reflect.Map:
-		ma, mb := a.(map[string]interface{}), b.(map[string]interface{})
-		return ctx.printCollectionDiff(&collectionConfig{
-			open:    "{",
-			close:   "}",
-			skipped: ctx.opts.SkippedObjectProperty,
-			value:   a,
-		}, makeDualMapIterator(ma, mb))
-	}
-	if !ctx.opts.SkipMatches {
-		ctx.tag(&buf, &ctx.opts.Normal)
-		ctx.writeValue(&buf, a, true)
-		ctx.result(FullMatch)
+			a:       d.a,
+			b:       d.b,
+		}, d)
+	case deltaSkipped:
+		if d.placeholder != "" {
+			ctx.w.Tag(SkippedTag)
+			ctx.w.Text(d.placeholder)
+		} else if !ctx.opts.SkipMatches {
+			ctx.w.Tag(NormalTag)
+			ctx.writeValue(d.a, true)
+		}
+	case deltaRemoved:
+		// only the root value, e.g. an unpaired event of CompareEventStreams
+		ctx.w.Tag(RemovedTag)
+		ctx.writeValue(d.a, true)
+	case deltaAdded:
+		ctx.w.Tag(AddedTag)
+		ctx.writeValue(d.b, true)
+	default:
+		if !ctx.opts.SkipMatches {
+			ctx.w.Tag(NormalTag)
+			ctx.writeValue(d.a, true)
+		}
 	}
-	return ctx.finalize(&buf)
+}
+
+// printText renders the delta using the text format.
+func (ctx *context) printText(d *delta) string {
+	tw := newTextWriter(ctx.opts)
+	if ctx.arena != nil {
+		ctx.arena.buf.Reset()
+		tw.buf = &ctx.arena.buf
+	}
+	ctx.w = tw
+	if ctx.markOutput(d) && (ctx.opts.LeftLabel != "" || ctx.opts.RightLabel != "") {
+		ctx.w.Tag(RemovedTag)
+		ctx.w.Text(headerLine("---", ctx.opts.LeftLabel))
+		ctx.w.Tag(NoTag)
+		ctx.newline("")
+		ctx.w.Tag(AddedTag)
+		ctx.w.Text(headerLine("+++", ctx.opts.RightLabel))
+		ctx.w.Tag(NoTag)
+		ctx.newline("")
+	}
+	ctx.printDelta(d)
+	return tw.String()
 }
 
 // Compare compares two JSON documents using given options. Returns difference type and
@@ -628,10 +1370,36 @@ func (ctx *context) printDiff(a, b interface{}) string {
 // The rest of the difference types mean that one of or both JSON documents are
 // invalid JSON.
 //
+// Some of the options refer to values using path patterns. A path pattern is
+// a dot separated list of object keys and array indices, for example
+// "items.0.id". A "*" element matches any single key or index and a "**"
+// element matches any number of elements, including none.
+//
 // Returned string uses a format similar to pretty printed JSON to show the
 // human-readable difference between provided JSON documents. It is important
 // to understand that returned format is not a valid JSON and is not meant
 // to be machine readable.
+//
+// When Options.Format is DocumentOutput, returned string is a valid JSON
+// document structured like the compared documents (the format of the
+// json-diff npm package). Changed values are replaced with
+// {"__old": a, "__new": b}, added and removed object properties get
+// "__added" and "__deleted" key suffixes, array elements are represented as
+// [op, value] pairs where op is one of " ", "+", "-" or "~". Tags and
+// PrintTypes are ignored. When SkipMatches is true, only the differing
+// subtree is included and matching array elements are shown as [" "].
+//
+// When Options.Format is JDOutput, returned string is a diff in the native
+// format of the jd tool: a sequence of hunks, each starting with an
+// "@ [path]" line followed by "- value" and "+ value" lines. Changes of
+// consecutive array elements are merged into a single hunk. Only
+// differences are included regardless of SkipMatches, tags and other
+// rendering options are ignored.
+//
+// When Options.Format is JSONPatchOutput, returned string is a JSON Patch
+// (RFC 6902) which transforms the first document into the second one,
+// indented according to Prefix and Indent. Only differences are included
+// regardless of SkipMatches, skipped values are ignored.
 func Compare(a, b []byte, opts *Options) (Difference, string) {
 	return CompareStreams(bytes.NewReader(a), bytes.NewReader(b), opts)
 }
@@ -639,26 +1407,148 @@ func Compare(a, b []byte, opts *Options) (Difference, string) {
 // CompareStreams compares two JSON documents streamed by the specified readers.
 // See the documentation for `Compare` for a description of the input options and return values.
 func CompareStreams(a, b io.Reader, opts *Options) (Difference, string) {
-	var av, bv interface{}
-	da := json.NewDecoder(a)
-	da.UseNumber()
-	db := json.NewDecoder(b)
-	db.UseNumber()
-	errA := da.Decode(&av)
-	errB := db.Decode(&bv)
+	ctx := context{opts: opts}
+	return ctx.compareStreams(a, b)
+}
+
+func (ctx *context) compareStreams(a, b io.Reader) (Difference, string) {
+	if ctx.quickFullMatchAllowed() {
+		ab, errA := io.ReadAll(a)
+		bb, errB := io.ReadAll(b)
+		if errA == nil && errB == nil {
+			if identicalJSON(ab, bb) {
+				return ctx.quickFullMatch(ab)
+			}
+		}
+		a, b = bytes.NewReader(ab), bytes.NewReader(bb)
+	}
+
+	d, diff, msg := ctx.decodeAndCompare(a, b)
+	if d == nil {
+		return diff, msg
+	}
+	return ctx.diff, ctx.render(d)
+}
+
+// quickFullMatchAllowed returns true if identical documents may be reported
+// as FullMatch without comparing them. Documents can't be compared quickly if
+// different parts of them are compared, or if keys of the first one are
+// renamed.
+func (ctx *context) quickFullMatchAllowed() bool {
+	return ctx.opts.QuickFullMatch && ctx.opts.FirstRoot == ctx.opts.SecondRoot && ctx.opts.Trace == nil &&
+		ctx.opts.InputFormat == JSONInput && len(ctx.opts.KeyAliases) == 0
+}
+
+// decodeAndCompare decodes and compares two JSON documents. If any of the
+// documents is invalid, returns nil delta along with the verdict and message.
+func (ctx *context) decodeAndCompare(a, b io.Reader) (*delta, Difference, string) {
+	av, bv, diff, msg := ctx.decodeBoth(a, b)
+	if diff.IsError() {
+		return nil, diff, msg
+	}
+	return ctx.compareRoots(av, bv), ctx.diff, ""
+}
+
+// decodeBoth decodes both documents, when any of them is invalid JSON it
+// returns one of the invalid JSON verdicts along with the message.
+func (ctx *context) decodeBoth(a, b io.Reader) (interface{}, interface{}, Difference, string) {
+	av, errA := ctx.decode(a)
+	bv, errB := ctx.decode(b)
 	if errA != nil && errB != nil {
-		return BothArgsAreInvalidJson, "both arguments are invalid json"
+		return nil, nil, BothArgsAreInvalidJson, ctx.invalidJsonMessage("both arguments are invalid json", errA, errB)
 	}
 	if errA != nil {
-		return FirstArgIsInvalidJson, "first argument is invalid json"
+		return nil, nil, FirstArgIsInvalidJson, ctx.invalidJsonMessage("first argument is invalid json", errA, nil)
 	}
 	if errB != nil {
-		return SecondArgIsInvalidJson, "second argument is invalid json"
+		return nil, nil, SecondArgIsInvalidJson, ctx.invalidJsonMessage("second argument is invalid json", nil, errB)
 	}
+	return av, bv, FullMatch, ""
+}
 
-	var buf bytes.Buffer
+// compareRoots compares decoded documents starting from their roots, see
+// Options.FirstRoot and Options.SecondRoot.
+func (ctx *context) compareRoots(a, b interface{}) *delta {
+	ctx.root, ctx.rootPath = rootPointer(a, ctx.opts.FirstRoot)
+	a = selectRoot(a, ctx.opts.FirstRoot)
+	b = selectRoot(b, ctx.opts.SecondRoot)
+	d := ctx.compare(ctx.transform(a), ctx.transform(b))
+	ctx.trace(d)
+	return d
+}
 
-	ctx := context{opts: opts}
-	buf.WriteString(ctx.printDiff(av, bv))
-	return ctx.diff, buf.String()
+func (ctx *context) render(d *delta) string {
+	switch ctx.opts.Format {
+	case DocumentOutput:
+		return ctx.renderDocument(d)
+	case JDOutput:
+		return ctx.renderJD(d)
+	case JSONPatchOutput:
+		return ctx.renderPatch(d)
+	}
+	return ctx.printText(d)
+}
+
+// identicalJSON returns true if both arguments are valid UTF-8 encoded JSON
+// documents identical after removing insignificant whitespace. It doesn't
+// allocate and checks byte-identical documents first.
+func identicalJSON(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return json.Valid(a) && utf8.Valid(a)
+	}
+	i, j := 0, 0
+	inString, escaped := false, false
+	for {
+		if !inString {
+			for i < len(a) && isSpace(a[i]) {
+				i++
+			}
+			for j < len(b) && isSpace(b[j]) {
+				j++
+			}
+		}
+		if i == len(a) || j == len(b) {
+			break
+		}
+		c := a[i]
+		if c != b[j] {
+			return false
+		}
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && inString:
+			escaped = true
+		case c == '"':
+			inString = !inString
+		}
+		i++
+		j++
+	}
+	// whitespace could split a token in one of the documents, e.g. "1 2", so
+	// both of them have to be validated
+	return i == len(a) && j == len(b) && json.Valid(a) && json.Valid(b) && utf8.Valid(a)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// quickFullMatch produces a FullMatch result for two identical documents. The
+// document is decoded only when matches have to be printed.
+func (ctx *context) quickFullMatch(doc []byte) (Difference, string) {
+	header := ctx.opts.Format == DocumentOutput && ctx.opts.DocumentHeader
+	if ctx.opts.Format == JSONPatchOutput {
+		return FullMatch, "[]"
+	}
+	if (ctx.opts.SkipMatches && !header) || ctx.opts.Format == JDOutput {
+		return FullMatch, ""
+	}
+	v, err := decode(bytes.NewReader(doc))
+	if err != nil {
+		// document is known to be valid
+		panic(err)
+	}
+	v = ctx.transform(selectRoot(v, ctx.opts.FirstRoot))
+	return FullMatch, ctx.render(ctx.compare(v, v))
 }
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 var compareCases = []struct {
@@ -238,3 +240,333 @@ func TestCompareFloatsWithEpsilon(t *testing.T) {
 		}
 	}
 }
+
+func TestDifferencePredicates(t *testing.T) {
+	cases := []struct {
+		d        Difference
+		isMatch  bool
+		isError  bool
+		exitCode int
+	}{
+		{FullMatch, true, false, 0},
+		{SupersetMatch, true, false, 0},
+		{NoMatch, false, false, 1},
+		{FirstArgIsInvalidJson, false, true, 2},
+		{SecondArgIsInvalidJson, false, true, 2},
+		{BothArgsAreInvalidJson, false, true, 2},
+		{UnknownDifference, false, false, 2},
+	}
+	for _, c := range cases {
+		if c.d.IsMatch() != c.isMatch || c.d.IsError() != c.isError {
+			t.Errorf("%s: got IsMatch=%v IsError=%v", c.d, c.d.IsMatch(), c.d.IsError())
+		}
+		if code := ExitCode(c.d); code != c.exitCode {
+			t.Errorf("%s: got ExitCode %d, expected %d", c.d, code, c.exitCode)
+		}
+	}
+}
+
+func TestQuickFullMatch(t *testing.T) {
+	a := `{"a": [1, 2, {"b": "c d"}], "e": null}`
+	b := "{\"a\":[1,2,{\"b\":\"c d\"}],\n\t\"e\":null}"
+	opts := DefaultConsoleOptions()
+	_, expected := Compare([]byte(a), []byte(b), &opts)
+	opts.QuickFullMatch = true
+	result, diff := Compare([]byte(a), []byte(b), &opts)
+	if result != FullMatch || diff != expected {
+		t.Errorf("got %s:\n%s\nexpected FullMatch:\n%s", result, diff, expected)
+	}
+
+	for i, c := range compareCases {
+		result, _ := Compare([]byte(c.a), []byte(c.b), &opts)
+		if result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s", i, result, c.result)
+		}
+	}
+
+	result, _ = Compare([]byte(`{"a":`), []byte(`{"a":`), &opts)
+	if result != BothArgsAreInvalidJson {
+		t.Errorf("got %s, expected BothArgsAreInvalidJson", result)
+	}
+}
+
+func TestIdenticalJSON(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected bool
+	}{
+		{`{"a": 1}`, `{"a": 1}`, true},
+		{`{"a": [1, 2]}`, "{\"a\":[1,2]}\n", true},
+		{`"a b"`, `"ab"`, false},
+		{`"a\" b"`, `"a\"b"`, false},
+		{`12`, `1 2`, false},
+		{`[true]`, `[tr ue]`, false},
+		{`{"a":`, `{"a":`, false},
+		{`[1]`, `[1]]`, false},
+	}
+	for _, c := range cases {
+		if got := identicalJSON([]byte(c.a), []byte(c.b)); got != c.expected {
+			t.Errorf("%q %q: got %v, expected %v", c.a, c.b, got, c.expected)
+		}
+	}
+}
+
+func BenchmarkQuickFullMatch(b *testing.B) {
+	doc := []byte(`{"a": [1, 2, {"b": "c d"}], "e": null, "f": "` + strings.Repeat("x", 1000) + `"}`)
+	other := bytes.Replace(doc, []byte(", "), []byte(","), -1)
+	opts := Options{QuickFullMatch: true, SkipMatches: true}
+	for i := 0; i < b.N; i++ {
+		Compare(doc, other, &opts)
+	}
+}
+
+func TestEmptyCollectionModes(t *testing.T) {
+	cases := []struct {
+		a      string
+		b      string
+		mode   EmptyCollectionMode
+		result Difference
+	}{
+		{`{"a": {"b": 1}}`, `{"a": {}}`, EmptyCollectionDefault, SupersetMatch},
+		{`{"a": {"b": 1}}`, `{"a": {}}`, EmptyCollectionMatchesAny, FullMatch},
+		{`{"a": {"b": 1}}`, `{"a": {}}`, EmptyCollectionMatchesEmpty, NoMatch},
+		{`{"a": {}}`, `{"a": {}}`, EmptyCollectionMatchesEmpty, FullMatch},
+		{`{"a": []}`, `{"a": {}}`, EmptyCollectionMatchesAny, NoMatch},
+		{`[1, 2]`, `[]`, EmptyCollectionDefault, SupersetMatch},
+		{`[1, 2]`, `[]`, EmptyCollectionMatchesAny, FullMatch},
+		{`[1, 2]`, `[]`, EmptyCollectionMatchesEmpty, NoMatch},
+		{`[]`, `[1]`, EmptyCollectionMatchesAny, NoMatch},
+	}
+	for i, c := range cases {
+		opts := DefaultConsoleOptions()
+		opts.EmptyObject = c.mode
+		opts.EmptyArray = c.mode
+		result, _ := Compare([]byte(c.a), []byte(c.b), &opts)
+		if result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s", i, result, c.result)
+		}
+	}
+}
+
+func TestVerboseErrors(t *testing.T) {
+	opts := DefaultConsoleOptions()
+	opts.VerboseErrors = true
+	result, msg := Compare([]byte(`{"a": 1}`), []byte(`{"a": 1,}`), &opts)
+	expected := "second argument is invalid json: invalid character '}' looking for beginning of object key string (offset 9)"
+	if result != SecondArgIsInvalidJson || msg != expected {
+		t.Errorf("got %s: %q, expected SecondArgIsInvalidJson: %q", result, msg, expected)
+	}
+	result, msg = Compare([]byte(`[1, 2`), []byte(``), &opts)
+	expected = "both arguments are invalid json: first: unexpected EOF (offset 5); second: EOF (offset 0)"
+	if result != BothArgsAreInvalidJson || msg != expected {
+		t.Errorf("got %s: %q, expected BothArgsAreInvalidJson: %q", result, msg, expected)
+	}
+}
+
+func TestChangedSeparateLines(t *testing.T) {
+	opts := Options{
+		Added:         Tag{Begin: "(A:", End: ":A)"},
+		Removed:       Tag{Begin: "(R:", End: ":R)"},
+		Indent:        "  ",
+		ChangedLayout: ChangedSeparateLines,
+	}
+	cases := []struct {
+		a        string
+		b        string
+		expected string
+	}{
+		{`{"a":[1,2],"b":"foo"}`, `{"a":[1,3],"b":{"c":1}}`, `
+{
+  "a": [
+    1,
+    (R:2:R)
+    (A:3:A)
+  ],
+  (R:"b": "foo":R)
+  (A:"b": {:A)
+    (A:"c": 1:A)
+  (A:}:A)
+}
+		`},
+		{`1`, `2`, "(R:1:R)\n(A:2:A)"},
+	}
+	for i, c := range cases {
+		_, diff := Compare([]byte(c.a), []byte(c.b), &opts)
+		expected := strings.TrimSpace(c.expected)
+		if diff != expected {
+			t.Errorf("case %d, got:\n---\n%s\n---\nexpected:\n---\n%s\n---\n", i, diff, expected)
+		}
+	}
+}
+
+func TestPrintSizes(t *testing.T) {
+	opts := Options{
+		Indent:           " ",
+		ChangedSeparator: " => ",
+		PrintSizes:       true,
+		SkipMatches:      true,
+	}
+	_, diff := Compare([]byte(`{"a": [1, 2], "b": {"c": 1}}`), []byte(`{"a": [1, 2, 3], "b": {"c": 2}}`), &opts)
+	expected := "{\n \"a\": [\n  3\n ] (5B => 7B),\n \"b\": {\n  \"c\": 1 => 2\n } (7B)\n} (23B => 25B)"
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+
+	opts.PrintTypes = true
+	_, diff = Compare([]byte(`[1]`), []byte(`{}`), &opts)
+	if expected := "[] (array, 3B) => {} (object, 2B)"; diff != expected {
+		t.Errorf("got %q, expected %q", diff, expected)
+	}
+
+	for n, expected := range map[int]string{1023: "1023B", 14541: "14.2KB", 3 << 20: "3.0MB"} {
+		if s := formatSize(n); s != expected {
+			t.Errorf("got %s for %d, expected %s", s, n, expected)
+		}
+	}
+}
+
+func TestEncodedSize(t *testing.T) {
+	v, err := decode(strings.NewReader(`{"a": [1, "x\"<", {}], "b": {"c": [[]], "é": null}, "d": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context{opts: &Options{}}
+	for i := 0; i < 2; i++ {
+		if n, expected := ctx.encodedSize(v), len(encodeJSON(v, "", "")); n != expected {
+			t.Errorf("got %d, expected %d", n, expected)
+		}
+	}
+}
+
+// keysDocument returns an object with n long keys which differ only in the
+// last byte, values of the documents differ when the last byte does. A large
+// shared value prevents replacing the whole document in compact patches.
+func keysDocument(n int, last byte) []byte {
+	var buf strings.Builder
+	buf.WriteString(`{"shared": "` + strings.Repeat("s", 300*n) + `"`)
+	prefix := strings.Repeat("k", 100)
+	for i := 0; i < n; i++ {
+		buf.WriteString(", ")
+		fmt.Fprintf(&buf, `"%s%08d%c": {"v": "%d%c"}`, prefix, i, last, i, last)
+	}
+	buf.WriteString("}")
+	return []byte(buf.String())
+}
+
+func longStringDocument(n int) []byte {
+	return []byte(`{"s": "` + strings.Repeat("é", n) + `"}`)
+}
+
+var complexityCases = []struct {
+	name string
+	n    int
+	doc  func(n int) ([]byte, []byte)
+	opts Options
+}{
+	{"keys text", 2000, func(n int) ([]byte, []byte) { return keysDocument(n, 'a'), keysDocument(n, 'b') }, Options{SkipMatches: true}},
+	{"keys patch", 2000, func(n int) ([]byte, []byte) { return keysDocument(n, 'a'), keysDocument(n, 'b') }, Options{Format: JSONPatchOutput, CompactPatch: true}},
+	{"keys document", 2000, func(n int) ([]byte, []byte) { return keysDocument(n, 'a'), keysDocument(n, 'b') }, Options{Format: DocumentOutput}},
+	{"keys jd", 2000, func(n int) ([]byte, []byte) { return keysDocument(n, 'a'), keysDocument(n, 'b') }, Options{Format: JDOutput}},
+	{"keys sizes", 2000, func(n int) ([]byte, []byte) { return keysDocument(n, 'a'), keysDocument(n, 'b') }, Options{PrintSizes: true}},
+	{"long line", 20000, func(n int) ([]byte, []byte) { return longStringDocument(n), []byte(`{"s": ""}`) }, Options{MaxLineWidth: 80}},
+}
+
+// TestComplexity guards against quadratic behavior, comparison of 8 times
+// larger documents has to take less than 24 times longer. Wall-clock timing is
+// unreliable on loaded machines and under the race detector, so the test only
+// runs when JSONDIFF_TIMING_TESTS is set, see BenchmarkComplexity otherwise.
+func TestComplexity(t *testing.T) {
+	if os.Getenv("JSONDIFF_TIMING_TESTS") == "" {
+		t.Skip("timing test, set JSONDIFF_TIMING_TESTS to run it")
+	}
+	for _, c := range complexityCases {
+		measure := func(n int) time.Duration {
+			a, b := c.doc(n)
+			best := time.Duration(math.MaxInt64)
+			for i := 0; i < 3; i++ {
+				start := time.Now()
+				Compare(a, b, &c.opts)
+				if d := time.Since(start); d < best {
+					best = d
+				}
+			}
+			return best
+		}
+		small, large := measure(c.n), measure(8*c.n)
+		if large > 24*small {
+			t.Errorf("%s: %v for %d, %v for %d", c.name, small, c.n, large, 8*c.n)
+		}
+	}
+}
+
+func BenchmarkComplexity(b *testing.B) {
+	for _, c := range complexityCases {
+		c := c
+		x, y := c.doc(8 * c.n)
+		b.Run(c.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Compare(x, y, &c.opts)
+			}
+		})
+	}
+}
+
+func TestStrict(t *testing.T) {
+	cases := []struct {
+		a      string
+		b      string
+		opts   Options
+		result Difference
+	}{
+		{`{"a": 1} garbage`, `{"a": 1}`, Options{}, FullMatch},
+		{`{"a": 1} garbage`, `{"a": 1}`, Options{Strict: true}, FirstArgIsInvalidJson},
+		{`{"a": 1}`, `{"a": 1} 2`, Options{Strict: true}, SecondArgIsInvalidJson},
+		{"{\"a\": 1}\r\n\t ", `{"a": 1}`, Options{Strict: true}, FullMatch},
+		{`{a: 1,} x`, `{a: 1}`, Options{Strict: true, Lenient: true}, FirstArgIsInvalidJson},
+		{`{a: 1,} `, `{a: 1}`, Options{Strict: true, Lenient: true}, FullMatch},
+	}
+	for i, c := range cases {
+		if result, _ := Compare([]byte(c.a), []byte(c.b), &c.opts); result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s", i, result, c.result)
+		}
+		if result := Verdict([]byte(c.a), []byte(c.b), &c.opts); result != c.result {
+			t.Errorf("case %d failed, got verdict: %s, expected: %s", i, result, c.result)
+		}
+	}
+
+	opts := Options{Strict: true}
+	errA, _ := InputErrors([]byte(`{"a": 1} x`), []byte(`{}`), &opts)
+	if errA == nil || errA.Column != 10 || errA.Err.Error() != "invalid character 'x' after top-level value" {
+		t.Errorf("got %v, expected error at column 10", errA)
+	}
+}
+
+func TestSideLabels(t *testing.T) {
+	opts := DefaultMarkerOptions()
+	opts.LeftLabel = "expected"
+	opts.RightLabel = "actual (staging)"
+	_, diff := Compare([]byte(`{"a": 1, "b": 2}`), []byte(`{"a": 2, "b": 2}`), &opts)
+	expected := `[---- expected-]
+{++++ actual (staging)+}
+{
+    "a": {~1 (expected) => 2 (actual (staging))~},
+    "b": 2
+}`
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+
+	// no header without output
+	opts.SkipMatches = true
+	if _, diff := Compare([]byte(`{"a": 1}`), []byte(`{"a": 1}`), &opts); diff != "" {
+		t.Errorf("got:\n%s", diff)
+	}
+
+	opts = Options{Format: DocumentOutput, DocumentHeader: true, LeftLabel: "expected"}
+	_, diff = Compare([]byte(`1`), []byte(`1`), &opts)
+	expected = `{"code":0,"counts":{"added":0,"changed":0,"removed":0},"diff":1,"fingerprint":"` + opts.Fingerprint() + `","left":"expected","verdict":"FullMatch","version":"` + version + `"}`
+	if diff != expected {
+		t.Errorf("got %s, expected %s", diff, expected)
+	}
+}
@@ -0,0 +1,66 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+)
+
+// DecodeJWTs returns a normalizer which replaces strings that are JSON Web
+// Tokens (RFC 7519) in the compact serialization with objects of their
+// decoded header and payload: {"header": {...}, "payload": {...}}, so that
+// tokens are compared claim by claim rather than as opaque strings.
+// Signatures are ignored, as well as the given claims of the payload, e.g.
+// "iat" and "exp" which differ between any two tokens. Strings which don't
+// decode to a JSON object header with an "alg" and a JSON object payload are
+// left intact.
+func DecodeJWTs(ignoreClaims ...string) Normalizer {
+	return func(path string, v interface{}) interface{} {
+		s, ok := v.(string)
+		if !ok {
+			return v
+		}
+		token, ok := decodeJWT(s)
+		if !ok {
+			return v
+		}
+		payload := token["payload"].(map[string]interface{})
+		for _, c := range ignoreClaims {
+			delete(payload, c)
+		}
+		return token
+	}
+}
+
+// decodeJWT decodes the header and the payload of a token.
+func decodeJWT(s string) (map[string]interface{}, bool) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	header, ok := decodeJWTPart(parts[0])
+	if !ok {
+		return nil, false
+	}
+	if _, ok := header["alg"].(string); !ok {
+		return nil, false
+	}
+	payload, ok := decodeJWTPart(parts[1])
+	if !ok {
+		return nil, false
+	}
+	return map[string]interface{}{"header": header, "payload": payload}, true
+}
+
+func decodeJWTPart(s string) (map[string]interface{}, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	v, err := decodeValue(bytes.NewReader(data), true)
+	if err != nil {
+		return nil, false
+	}
+	m, ok := v.(map[string]interface{})
+	return m, ok
+}
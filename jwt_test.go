@@ -0,0 +1,32 @@
+package jsondiff
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func testJWT(header, payload string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(header)) + "." + enc.EncodeToString([]byte(payload)) + ".c2lnbmF0dXJl"
+}
+
+func TestDecodeJWTs(t *testing.T) {
+	header := `{"alg": "HS256", "typ": "JWT"}`
+	a := []byte(`{"token": "` + testJWT(header, `{"sub": "1", "role": "user", "iat": 1}`) + `", "other": "a.b.c"}`)
+	b := []byte(`{"token": "` + testJWT(header, `{"sub": "1", "role": "admin", "iat": 2}`) + `", "other": "a.b.c"}`)
+	opts := Options{Indent: " ", SkipMatches: true, ChangedSeparator: " => ", Transform: Normalize(DecodeJWTs("iat"))}
+	result, diff := Compare(a, b, &opts)
+	expected := "{\n \"token\": {\n  \"payload\": {\n   \"role\": \"user\" => \"admin\"\n  }\n }\n}"
+	if result != NoMatch || diff != expected {
+		t.Errorf("got %s:\n%s\nexpected NoMatch:\n%s", result, diff, expected)
+	}
+
+	b = []byte(`{"token": "` + testJWT(header, `{"sub": "1", "role": "user", "iat": 3}`) + `", "other": "a.b.c"}`)
+	if result, diff := Compare(a, b, &opts); result != FullMatch {
+		t.Errorf("got %s, expected FullMatch:\n%s", result, diff)
+	}
+
+	if _, ok := decodeJWT(testJWT(`{"typ": "JWT"}`, `{}`)); ok {
+		t.Errorf("token without alg is decoded")
+	}
+}
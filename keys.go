@@ -0,0 +1,176 @@
+package jsondiff
+
+import (
+	"sort"
+)
+
+// aliasKeys returns a copy of the object with keys renamed according to the
+// aliases. Object is returned as is if there is nothing to rename. Keys are
+// renamed in sorted order, so that of several keys with the same alias the
+// first one wins.
+func aliasKeys(m map[string]interface{}, aliases map[string]string) map[string]interface{} {
+	if len(aliases) == 0 {
+		return m
+	}
+	var out map[string]interface{}
+	for _, k := range sortedKeys(m) {
+		v := m[k]
+		alias, ok := aliases[k]
+		if !ok {
+			continue
+		}
+		if _, exists := m[alias]; exists {
+			continue
+		}
+		if _, exists := out[alias]; exists {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]interface{}, len(m))
+			for k, v := range m {
+				out[k] = v
+			}
+		}
+		delete(out, k)
+		out[alias] = v
+	}
+	if out == nil {
+		return m
+	}
+	return out
+}
+
+// KeyNormalizeCaseStyle is a key normalizer for Options.NormalizeKeys which
+// makes keys in snake_case, kebab-case, camelCase and PascalCase equivalent,
+// e.g. "created_at" matches "createdAt". It removes underscores and dashes and
+// converts ASCII letters to lower case.
+func KeyNormalizeCaseStyle(key string) string {
+	buf := make([]byte, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c == '_' || c == '-':
+			continue
+		case c >= 'A' && c <= 'Z':
+			c += 'a' - 'A'
+		}
+		buf = append(buf, c)
+	}
+	return string(buf)
+}
+
+// normalizeKeys returns copies of both objects where keys which are equal after
+// normalization are renamed to the same key. Key of the second object is
+// preferred. When multiple keys of one object have the same normalized form,
+// the one which goes last in sorted order wins.
+func normalizeKeys(a, b map[string]interface{}, normalize func(string) string) (map[string]interface{}, map[string]interface{}) {
+	names := make(map[string]string, len(a)+len(b))
+	for _, k := range sortedKeys(a) {
+		names[normalize(k)] = k
+	}
+	for _, k := range sortedKeys(b) {
+		names[normalize(k)] = k
+	}
+	rename := func(m map[string]interface{}) map[string]interface{} {
+		out := make(map[string]interface{}, len(m))
+		for _, k := range sortedKeys(m) {
+			out[names[normalize(k)]] = m[k]
+		}
+		return out
+	}
+	return rename(a), rename(b)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// alignElems returns a copy of array a where elements equal to elements of
+// array b are moved to the same indices, see Options.UnorderedArrays.
+func alignElems(a, b []interface{}) []interface{} {
+	byKey := make(map[string][]int, len(a))
+	for i, e := range a {
+		k := encodeJSON(e, "", "")
+		byKey[k] = append(byKey[k], i)
+	}
+	used := make([]bool, len(a))
+	// index in a of the element which goes to the i-th position, elements of
+	// b which go beyond the end of a are added regardless of the order
+	match := make([]int, len(a))
+	for i := range match {
+		match[i] = -1
+		if i >= len(b) {
+			continue
+		}
+		k := encodeJSON(b[i], "", "")
+		if idx := byKey[k]; len(idx) != 0 {
+			match[i] = idx[0]
+			used[idx[0]] = true
+			byKey[k] = idx[1:]
+		}
+	}
+	out := make([]interface{}, len(a))
+	next := 0
+	for i := range out {
+		if match[i] >= 0 {
+			out[i] = a[match[i]]
+			continue
+		}
+		for used[next] {
+			next++
+		}
+		out[i] = a[next]
+		next++
+	}
+	return out
+}
+
+// arrayOrder returns the comparator of arrays at the path being compared, nil
+// if they are not sorted, see Options.SortArraysAt. When several patterns
+// match, the first one in lexicographic order wins.
+func (ctx *context) arrayOrder() func(a, b interface{}) bool {
+	var pattern string
+	var less func(a, b interface{}) bool
+	for p, f := range ctx.opts.SortArraysAt {
+		if (less == nil || p < pattern) && matchPath(splitPath(p), ctx.path) {
+			pattern, less = p, f
+		}
+	}
+	return less
+}
+
+// sortElems returns a sorted copy of the array, elements which are equal
+// according to less keep their relative order.
+func sortElems(s []interface{}, less func(a, b interface{}) bool) []interface{} {
+	out := append([]interface{}(nil), s...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return less(out[i], out[j])
+	})
+	return out
+}
+
+// keyArrays converts arrays at the path being compared to objects indexed by
+// the key property of their elements, see Options.KeyedArrays. Returns false
+// if the values are not converted.
+func (ctx *context) keyArrays(a, b interface{}) (interface{}, interface{}, bool) {
+	aa, okA := a.([]interface{})
+	bb, okB := b.([]interface{})
+	if !okA || !okB {
+		return a, b, false
+	}
+	var pattern, key string
+	for p, k := range ctx.opts.KeyedArrays {
+		if (key == "" || p < pattern) && matchPath(splitPath(p), ctx.path) {
+			pattern, key = p, k
+		}
+	}
+	if key == "" {
+		return a, b, false
+	}
+	return indexByKey(aa, key), indexByKey(bb, key), true
+}
@@ -0,0 +1,151 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+func TestKeyAliases(t *testing.T) {
+	cases := []struct {
+		a      string
+		b      string
+		result Difference
+	}{
+		{`{"created_at": 1, "user": {"first_name": "a"}}`, `{"createdAt": 1, "user": {"firstName": "a"}}`, FullMatch},
+		{`{"created_at": 1}`, `{"createdAt": 2}`, NoMatch},
+		{`{"created_at": 1, "createdAt": 2}`, `{"createdAt": 2}`, SupersetMatch},
+		{`{"createdAt": 1}`, `{"created_at": 1}`, NoMatch},
+	}
+	opts := DefaultConsoleOptions()
+	opts.KeyAliases = map[string]string{
+		"created_at": "createdAt",
+		"first_name": "firstName",
+	}
+	for i, c := range cases {
+		result, _ := Compare([]byte(c.a), []byte(c.b), &opts)
+		if result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s", i, result, c.result)
+		}
+	}
+
+	// identical documents are compared since keys of the first one are renamed
+	opts.QuickFullMatch = true
+	doc := []byte(`{"created_at": 1}`)
+	if result, _ := Compare(doc, doc, &opts); result != NoMatch {
+		t.Errorf("got %s for identical documents, expected NoMatch", result)
+	}
+	if result := Verdict(doc, doc, &opts); result != NoMatch {
+		t.Errorf("got verdict %s for identical documents, expected NoMatch", result)
+	}
+
+	// of several keys with the same alias the first one is renamed
+	aliases := map[string]string{"a": "x", "b": "x"}
+	for i := 0; i < 10; i++ {
+		m := aliasKeys(map[string]interface{}{"a": 1, "b": 2}, aliases)
+		if m["x"] != 1 || m["b"] != 2 || len(m) != 2 {
+			t.Fatalf("got %v, expected a renamed to x", m)
+		}
+	}
+}
+
+func TestNormalizeKeys(t *testing.T) {
+	opts := Options{Indent: " ", NormalizeKeys: KeyNormalizeCaseStyle}
+	result, diff := Compare(
+		[]byte(`{"created_at": 1, "user_id": 2, "Extra-Field": 3}`),
+		[]byte(`{"createdAt": 1, "UserID": 2, "other": 4}`),
+		&opts,
+	)
+	expected := "{\n \"Extra-Field\": 3,\n \"UserID\": 2,\n \"createdAt\": 1,\n \"other\": 4\n}"
+	if result != NoMatch || diff != expected {
+		t.Errorf("got %s:\n%s\nexpected NoMatch:\n%s", result, diff, expected)
+	}
+}
+
+func TestKeyOrder(t *testing.T) {
+	opts := Options{Indent: " "}
+	opts.KeyOrder = func(a, b string) bool { return a > b }
+	result, diff := Compare([]byte(`{"a": 1, "b": 2, "c": 3}`), []byte(`{"c": 3, "b": 2, "a": 1}`), &opts)
+	if result != FullMatch || diff != "{\n \"c\": 3,\n \"b\": 2,\n \"a\": 1\n}" {
+		t.Errorf("got %s:\n%s", result, diff)
+	}
+}
+
+func TestUnorderedArrays(t *testing.T) {
+	cases := []struct {
+		a      string
+		b      string
+		result Difference
+	}{
+		{`{"tags": [1, 2, 3], "list": [1, 2]}`, `{"tags": [3, 1, 2], "list": [1, 2]}`, FullMatch},
+		{`{"tags": [3, {"x": 1}, 1], "list": [1, 2]}`, `{"tags": [1, {"x": 1}], "list": [1, 2]}`, SupersetMatch},
+		{`{"tags": [1, 2, 2], "list": [1, 2]}`, `{"tags": [2, 1, 1], "list": [1, 2]}`, NoMatch},
+		{`{"tags": [1, 2], "list": [1, 2]}`, `{"tags": [2, 1], "list": [2, 1]}`, NoMatch},
+	}
+	opts := Options{UnorderedArrays: []string{"tags"}}
+	for i, c := range cases {
+		if result, diff := Compare([]byte(c.a), []byte(c.b), &opts); result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s\n%s", i, result, c.result, diff)
+		}
+		if result := Verdict([]byte(c.a), []byte(c.b), &opts); result != c.result {
+			t.Errorf("case %d failed, got verdict: %s, expected: %s", i, result, c.result)
+		}
+	}
+
+	// unmatched elements keep their relative order
+	opts = Options{Indent: " ", Removed: Tag{Begin: "-"}, ChangedSeparator: " => ", UnorderedArrays: []string{""}}
+	_, diff := Compare([]byte(`[4, 1, 2, 3]`), []byte(`[3, 5, 1]`), &opts)
+	if diff != "[\n 3,\n 4 => 5,\n 1,\n -2\n]" {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+}
+
+func TestSortArraysAt(t *testing.T) {
+	byID := func(a, b interface{}) bool {
+		return a.(map[string]interface{})["id"].(string) < b.(map[string]interface{})["id"].(string)
+	}
+	opts := Options{Indent: " ", SkipMatches: true, ChangedSeparator: " => "}
+	opts.SortArraysAt = map[string]func(a, b interface{}) bool{"users": byID}
+	a := []byte(`{"users": [{"id": "2", "x": 1}, {"id": "1", "x": 1}], "list": [2, 1]}`)
+	b := []byte(`{"users": [{"id": "1", "x": 1}, {"id": "2", "x": 2}], "list": [2, 1]}`)
+	result, diff := Compare(a, b, &opts)
+	expected := "{\n \"users\": [\n  {\n   \"x\": 1 => 2\n  }\n ]\n}"
+	if result != NoMatch || diff != expected {
+		t.Errorf("got %s:\n%s\nexpected NoMatch:\n%s", result, diff, expected)
+	}
+	if result := Verdict(a, b, &opts); result != NoMatch {
+		t.Errorf("got verdict %s, expected NoMatch", result)
+	}
+}
+
+func TestKeyedArrays(t *testing.T) {
+	opts := Options{Indent: " ", SkipMatches: true, ChangedSeparator: " => ", Added: Tag{Begin: "+"}, Removed: Tag{Begin: "-"}}
+	opts.KeyedArrays = map[string]string{"users": "id"}
+	a := []byte(`{"users": [{"id": 1, "x": 1}, {"id": 2, "x": 1}, {"id": 3}]}`)
+	b := []byte(`{"users": [{"id": 2, "x": 2}, {"id": 1, "x": 1}, {"id": 4}]}`)
+	result, diff := Compare(a, b, &opts)
+	expected := "{\n \"users\": {\n  \"2\": {\n   \"x\": 1 => 2\n  },\n  -\"3\": {\n   -\"id\": 3\n  -},\n  +\"4\": {\n   +\"id\": 4\n  +}\n }\n}"
+	if result != NoMatch || diff != expected {
+		t.Errorf("got %s:\n%s\nexpected NoMatch:\n%s", result, diff, expected)
+	}
+	if result := Verdict(a, b, &opts); result != NoMatch {
+		t.Errorf("got verdict %s, expected NoMatch", result)
+	}
+
+	// keys are not valid paths, so the array is replaced as a whole
+	_, _, changes := CompareWithChanges(a, b, &opts)
+	if len(changes) != 1 || changes[0].Path != "/users" || changes[0].Kind != ChangeModified {
+		t.Fatalf("got changes %+v, expected /users modified", changes)
+	}
+	patched, err := Apply(a, changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff, _ := Compare(patched, b, &Options{}); diff != FullMatch {
+		t.Errorf("got %s after applying changes, expected %s", patched, b)
+	}
+	opts.Format, opts.Indent = JSONPatchOutput, ""
+	_, diff = Compare(a, b, &opts)
+	expected = `[{"op":"replace","path":"/users","value":[{"id":2,"x":2},{"id":1,"x":1},{"id":4}]}]`
+	if diff != expected {
+		t.Errorf("got patch %s, expected %s", diff, expected)
+	}
+}
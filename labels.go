@@ -0,0 +1,104 @@
+package jsondiff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SummaryItem describes a change, or a group of added or removed values, to be
+// described by a sentence of the summary, see Labels.
+type SummaryItem struct {
+	Kind ChangeKind
+	// Dot separated path of the changed value, or of the collection for
+	// groups. Empty for the root value.
+	Path string
+	// Number of values in the group, 1 for a single value.
+	Count int
+	// True if the group consists of array elements rather than object
+	// properties.
+	Element bool
+	// Keys of the object properties in the group.
+	Keys []string
+	// Old and New values of ChangeModified.
+	Old interface{}
+	New interface{}
+}
+
+// Labels provides the wording of fixed strings of the text output and
+// summaries, so that reports in other languages can be produced, see
+// Options.Labels and SummarizeWith. Messages about skipped values are
+// controlled by Options.SkippedArrayElement and
+// Options.SkippedObjectProperty.
+type Labels interface {
+	// Name of the JSON type printed when Options.PrintTypes is true, t is
+	// one of "boolean", "number", "string", "array", "object" and "null".
+	TypeName(t string) string
+	// Text printed for null values.
+	Null() string
+	// Sentence of the summary.
+	Summary(item SummaryItem) string
+	// Summary of an empty list of changes.
+	NoChanges() string
+}
+
+type englishLabels struct{}
+
+// EnglishLabels is the default wording of the text output and summaries.
+var EnglishLabels Labels = englishLabels{}
+
+func (englishLabels) TypeName(t string) string {
+	return t
+}
+
+func (englishLabels) Null() string {
+	return "null"
+}
+
+func englishValue(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	}
+	return encodeJSON(v, "", "")
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return strconv.Itoa(n) + " " + many
+}
+
+func (englishLabels) Summary(item SummaryItem) string {
+	path := "`" + item.Path + "`"
+	if item.Path == "" {
+		path = "the document"
+	}
+	verb, prep := "added", "to"
+	if item.Kind == ChangeRemoved {
+		verb, prep = "removed", "from"
+	}
+	switch {
+	case item.Kind == ChangeModified:
+		return path + " changed from " + englishValue(item.Old) + " to " + englishValue(item.New)
+	case item.Count > 1 && item.Element:
+		return plural(item.Count, "element", "elements") + " " + verb + " " + prep + " " + path
+	case item.Count > 1:
+		return plural(item.Count, "property", "properties") + " " + verb + " " + prep + " " + path +
+			": `" + strings.Join(item.Keys, "`, `") + "`"
+	}
+	return path + " " + verb
+}
+
+func (englishLabels) NoChanges() string {
+	return "no changes"
+}
+
+func (ctx *context) labels() Labels {
+	if ctx.opts.Labels == nil {
+		return EnglishLabels
+	}
+	return ctx.opts.Labels
+}
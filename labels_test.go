@@ -0,0 +1,51 @@
+package jsondiff
+
+import (
+	"strconv"
+	"testing"
+)
+
+type germanLabels struct{}
+
+func (germanLabels) TypeName(t string) string {
+	return map[string]string{"number": "Zahl", "null": "Null", "object": "Objekt"}[t]
+}
+
+func (germanLabels) Null() string {
+	return "nichts"
+}
+
+func (germanLabels) Summary(item SummaryItem) string {
+	switch item.Kind {
+	case ChangeModified:
+		return item.Path + " geändert"
+	case ChangeAdded:
+		return strconv.Itoa(item.Count) + " hinzugefügt zu " + item.Path
+	}
+	return item.Path + " entfernt"
+}
+
+func (germanLabels) NoChanges() string {
+	return "keine Änderungen"
+}
+
+func TestLabels(t *testing.T) {
+	opts := Options{
+		Indent:           " ",
+		ChangedSeparator: " => ",
+		PrintTypes:       true,
+		Labels:           germanLabels{},
+	}
+	_, diff := Compare([]byte(`{"a": 1}`), []byte(`{"a": null}`), &opts)
+	if expected := "{\n \"a\": 1 (Zahl) => nichts (Null)\n} (Objekt)"; diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+
+	_, _, changes := CompareWithChanges([]byte(`{"a": [1], "b": 1}`), []byte(`{"a": [1, 2, 3], "b": 2}`), &opts)
+	if got := SummarizeWith(changes, germanLabels{}); got != "2 hinzugefügt zu a; b geändert" {
+		t.Errorf("got %q", got)
+	}
+	if got := SummarizeWith(nil, germanLabels{}); got != "keine Änderungen" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,251 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// lenientParser parses common non-strict JSON extensions: NaN and Infinity
+// numbers, unquoted object keys, single quoted strings and trailing commas.
+// Non-finite numbers are represented as json.Number("NaN"),
+// json.Number("Infinity") and json.Number("-Infinity").
+type lenientParser struct {
+	data []byte
+	pos  int
+	// nesting of the collection being parsed, limited by maxBinaryDepth
+	depth int
+}
+
+func (p *lenientParser) errorf(msg string) error {
+	return &decodeError{err: errors.New(msg), offset: int64(p.pos)}
+}
+
+func (p *lenientParser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *lenientParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return 0
+	}
+	return p.data[p.pos]
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isNumberByte(c byte) bool {
+	return c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
+
+func (p *lenientParser) value() (interface{}, error) {
+	switch c := p.peek(); {
+	case p.pos >= len(p.data):
+		return nil, p.errorf("unexpected EOF")
+	case c == '{' || c == '[':
+		if p.depth++; p.depth > maxBinaryDepth {
+			return nil, p.errorf(errBinaryDepth.Error())
+		}
+		defer func() { p.depth-- }()
+		if c == '{' {
+			return p.object()
+		}
+		return p.array()
+	case c == '"' || c == '\'':
+		return p.string()
+	case c == '-' || c == '+' || c >= '0' && c <= '9':
+		return p.number()
+	case isIdentByte(c):
+		start := p.pos
+		word := p.ident()
+		switch word {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		case "NaN", "Infinity":
+			return json.Number(word), nil
+		}
+		p.pos = start
+		return nil, p.errorf("invalid literal " + strconv.Quote(word))
+	default:
+		return nil, p.errorf("invalid character " + strconv.QuoteRune(rune(c)) + " looking for beginning of value")
+	}
+}
+
+func (p *lenientParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.data) && isIdentByte(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+func (p *lenientParser) number() (interface{}, error) {
+	start := p.pos
+	neg := false
+	if c := p.data[p.pos]; c == '-' || c == '+' {
+		neg = c == '-'
+		p.pos++
+	}
+	sign := ""
+	if neg {
+		sign = "-"
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == 'I' {
+		if p.ident() == "Infinity" {
+			return json.Number(sign + "Infinity"), nil
+		}
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	digits := p.pos
+	for p.pos < len(p.data) && isNumberByte(p.data[p.pos]) {
+		p.pos++
+	}
+	lit := sign + string(p.data[digits:p.pos])
+	if !json.Valid([]byte(lit)) {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	return json.Number(lit), nil
+}
+
+func (p *lenientParser) string() (interface{}, error) {
+	start := p.pos
+	quote := p.data[p.pos]
+	p.pos++
+	// rewrite the string as a double quoted JSON string and let
+	// encoding/json handle the escape sequences
+	buf := []byte{'"'}
+	for {
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("unexpected EOF")
+		}
+		c := p.data[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			buf = append(buf, '"')
+			var s string
+			if err := json.Unmarshal(buf, &s); err != nil {
+				p.pos = start
+				return nil, p.errorf("invalid string literal")
+			}
+			return s, nil
+		case c == '\\' && p.pos < len(p.data) && p.data[p.pos] == '\'':
+			buf = append(buf, '\'')
+			p.pos++
+		case c == '\\' && p.pos < len(p.data):
+			buf = append(buf, c, p.data[p.pos])
+			p.pos++
+		case c == '"':
+			buf = append(buf, '\\', '"')
+		default:
+			buf = append(buf, c)
+		}
+	}
+}
+
+func (p *lenientParser) key() (string, error) {
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		s, err := p.string()
+		if err != nil {
+			return "", err
+		}
+		return s.(string), nil
+	case isIdentByte(c):
+		return p.ident(), nil
+	}
+	return "", p.errorf("invalid character looking for beginning of object key")
+}
+
+func (p *lenientParser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf("expected " + strconv.QuoteRune(rune(c)))
+	}
+	p.pos++
+	return nil
+}
+
+func (p *lenientParser) object() (interface{}, error) {
+	p.pos++
+	m := make(map[string]interface{})
+	for {
+		if p.peek() == '}' {
+			p.pos++
+			return m, nil
+		}
+		k, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v
+		if p.peek() == ',' {
+			p.pos++
+		} else if err := p.expect('}'); err != nil {
+			return nil, err
+		} else {
+			return m, nil
+		}
+	}
+}
+
+func (p *lenientParser) array() (interface{}, error) {
+	p.pos++
+	s := make([]interface{}, 0)
+	for {
+		if p.peek() == ']' {
+			p.pos++
+			return s, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+		if p.peek() == ',' {
+			p.pos++
+		} else if err := p.expect(']'); err != nil {
+			return nil, err
+		} else {
+			return s, nil
+		}
+	}
+}
+
+func decodeLenient(r io.Reader, strict bool) (interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, newDecodeError(err, int64(len(data)))
+	}
+	p := lenientParser{data: data}
+	v, err := p.value()
+	if err == nil && strict {
+		if c := p.peek(); p.pos < len(p.data) {
+			return nil, trailingDataError(c, int64(p.pos))
+		}
+	}
+	return v, err
+}
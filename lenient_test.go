@@ -0,0 +1,56 @@
+package jsondiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLenient(t *testing.T) {
+	cases := []struct {
+		a      string
+		b      string
+		result Difference
+	}{
+		{`{a: 'foo', "b": [1, 2,],}`, `{"a": "foo", "b": [1, 2]}`, FullMatch},
+		{`{'it\'s': "\"q\""}`, `{"it's": '"q"'}`, FullMatch},
+		{`[NaN, Infinity, -Infinity, +Infinity, +1]`, `[NaN, Infinity, -Infinity, Infinity, 1]`, FullMatch},
+		{`[NaN]`, `[1]`, NoMatch},
+		{`{a: 1, b: 2}`, `{a: 1}`, SupersetMatch},
+		{`{a: 1`, `{a: 1}`, FirstArgIsInvalidJson},
+		{`{a: 1}`, `[01]`, SecondArgIsInvalidJson},
+		{`{a: 1}`, `[foo]`, SecondArgIsInvalidJson},
+		{"[\x00]", `[]`, FirstArgIsInvalidJson},
+	}
+	opts := DefaultConsoleOptions()
+	opts.Lenient = true
+	for i, c := range cases {
+		result, _ := Compare([]byte(c.a), []byte(c.b), &opts)
+		if result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s", i, result, c.result)
+		}
+	}
+
+	opts = Options{Lenient: true, Format: JDOutput}
+	_, diff := Compare([]byte(`[NaN]`), []byte(`[1]`), &opts)
+	expected := "@ [0]\n- \"NaN\"\n+ 1\n"
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+
+	// a NUL byte is not the end of the input
+	opts = Options{Lenient: true, Strict: true}
+	if result, _ := Compare([]byte("{\"a\":1}\x00garbage"), []byte(`{"a":1}`), &opts); result != FirstArgIsInvalidJson {
+		t.Errorf("got %s for trailing NUL byte, expected FirstArgIsInvalidJson", result)
+	}
+
+	// deep nesting is an error rather than a stack overflow
+	deep := []byte(strings.Repeat("[", 20000000))
+	opts = Options{Lenient: true}
+	if result, _ := Compare(deep, []byte(`[]`), &opts); result != FirstArgIsInvalidJson {
+		t.Errorf("got %s for deeply nested array, expected FirstArgIsInvalidJson", result)
+	}
+	nested := strings.Repeat("{a: [", maxBinaryDepth/2) + "1" + strings.Repeat("]}", maxBinaryDepth/2)
+	if result, _ := Compare([]byte(nested), []byte(nested), &opts); result != FullMatch {
+		t.Errorf("got %s for nesting at the limit, expected FullMatch", result)
+	}
+}
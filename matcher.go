@@ -0,0 +1,219 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Matcher is a value of the expected (second) document which decides itself
+// whether the corresponding value of the first document matches, see
+// CompareInterfaces. Match receives the value decoded from JSON: nil, bool,
+// json.Number, string, []interface{} or map[string]interface{}, and it's
+// called only for values present in both documents. String describes the
+// expected value in the output, e.g. "<uuid>".
+//
+// Matchers only exist in Go values, Compare and the other functions comparing
+// encoded documents never see them.
+type Matcher interface {
+	Match(actual interface{}) bool
+	String() string
+}
+
+// MatchFunc returns a Matcher which calls match and is described by desc,
+// e.g.:
+//
+//	positive := jsondiff.MatchFunc("<positive>", func(v interface{}) bool {
+//		n, ok := v.(json.Number)
+//		f, _ := n.Float64()
+//		return ok && f > 0
+//	})
+func MatchFunc(desc string, match func(actual interface{}) bool) Matcher {
+	return &funcMatcher{desc, match}
+}
+
+type funcMatcher struct {
+	desc  string
+	match func(actual interface{}) bool
+}
+
+func (m *funcMatcher) Match(actual interface{}) bool { return m.match(actual) }
+func (m *funcMatcher) String() string                { return m.desc }
+
+// matcherValue is a Matcher encoded as the JSON string of its description by
+// the JSON based output formats.
+type matcherValue struct {
+	Matcher
+}
+
+func (m matcherValue) MarshalJSON() ([]byte, error) {
+	return []byte(encodeJSON(m.String(), "", "")), nil
+}
+
+// typedValue is a Go value of a type with an equality function, see
+// Options.TypeComparators. It's a Matcher of values of the same type, so
+// that it's compared and rendered like one.
+type typedValue struct {
+	v     interface{}
+	equal func(a, b interface{}) bool
+}
+
+func (t typedValue) Match(actual interface{}) bool {
+	a, ok := actual.(typedValue)
+	return ok && reflect.TypeOf(a.v) == reflect.TypeOf(t.v) && t.equal(a.v, t.v)
+}
+
+func (t typedValue) String() string {
+	data, err := json.Marshal(t.v)
+	if err != nil {
+		return fmt.Sprint(t.v)
+	}
+	return string(data)
+}
+
+func (t typedValue) MarshalJSON() ([]byte, error) {
+	if data, err := json.Marshal(t.v); err == nil {
+		return data, nil
+	}
+	return json.Marshal(fmt.Sprint(t.v))
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// interfaceValue converts a Go value at the path to the types produced by
+// decoding JSON with json.Decoder.UseNumber, keeping matchers and values of
+// the types with equality functions intact. Pointers are dereferenced, maps
+// with string keys, slices and arrays are converted element by element,
+// scalars and values implementing json.Marshaler or encoding.TextMarshaler
+// are encoded as JSON and decoded back. Values of other kinds, e.g. structs,
+// are encoded the same way only if Options.MarshalUnknownValues is set.
+func (ctx *context) interfaceValue(v interface{}, path string) (interface{}, error) {
+	if equal, ok := ctx.opts.TypeComparators[reflect.TypeOf(v)]; ok && v != nil {
+		return typedValue{v, equal}, nil
+	}
+	switch vv := v.(type) {
+	case nil, bool, json.Number, string:
+		return v, nil
+	case matcherValue:
+		return vv, nil
+	case Matcher:
+		return matcherValue{vv}, nil
+	case json.RawMessage:
+		return decode(bytes.NewReader(vv))
+	}
+	rv := reflect.ValueOf(v)
+	t := rv.Type()
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return ctx.marshalValue(v, path)
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return ctx.interfaceValue(rv.Elem().Interface(), path)
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			break
+		}
+		if rv.IsNil() {
+			return nil, nil
+		}
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			k := iter.Key().String()
+			e, err := ctx.interfaceValue(iter.Value().Interface(), childPath(path, k))
+			if err != nil {
+				return nil, err
+			}
+			m[k] = e
+		}
+		return m, nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// encoded as base64 strings
+			return ctx.marshalValue(v, path)
+		}
+		if t.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		s := make([]interface{}, rv.Len())
+		for i := range s {
+			e, err := ctx.interfaceValue(rv.Index(i).Interface(), childPath(path, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			s[i] = e
+		}
+		return s, nil
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return ctx.marshalValue(v, path)
+	}
+	if ctx.opts.MarshalUnknownValues {
+		return ctx.marshalValue(v, path)
+	}
+	return nil, fmt.Errorf("jsondiff: unsupported %s value of type %s at %s", t.Kind(), t, valuePath(path))
+}
+
+// marshalValue converts a Go value at the path by encoding it as JSON and
+// decoding it back.
+func (ctx *context) marshalValue(v interface{}, path string) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("jsondiff: encoding value at %s: %v", valuePath(path), err)
+	}
+	return decode(bytes.NewReader(data))
+}
+
+// valuePath describes the path of a value in error messages.
+func valuePath(path string) string {
+	if path == "" {
+		return "the root"
+	}
+	return strconv.Quote(path)
+}
+
+// CompareInterfaces compares two documents given as Go values like Compare.
+// Values are treated as if they were encoded as JSON, except for the Matcher
+// values of the second document, which match the corresponding values of the
+// first document by calling Match. Matchers may be nested in maps with string
+// keys and slices, matchers inside structs are not recognized. In the text
+// output matchers are rendered using their String method, the JSON based
+// formats render them as strings. Values of the types of
+// Options.TypeComparators are compared by their equality functions instead
+// of being encoded.
+//
+// Supported values are the ones produced by decoding JSON, Go scalars,
+// pointers, maps with string keys, slices, arrays and values implementing
+// json.Marshaler or encoding.TextMarshaler. Structs and other values, e.g.
+// channels and functions, are unsupported unless
+// Options.MarshalUnknownValues is set.
+//
+// FirstArgIsInvalidJson and SecondArgIsInvalidJson verdicts mean that the
+// corresponding value is unsupported or can't be encoded as JSON, the
+// message names its path and type if Options.VerboseErrors is set.
+func CompareInterfaces(a, b interface{}, opts *Options) (Difference, string) {
+	ctx := context{opts: opts}
+	av, errA := ctx.interfaceValue(a, "")
+	bv, errB := ctx.interfaceValue(b, "")
+	if errA != nil && errB != nil {
+		return BothArgsAreInvalidJson, ctx.invalidJsonMessage("both arguments are invalid json", errA, errB)
+	}
+	if errA != nil {
+		return FirstArgIsInvalidJson, ctx.invalidJsonMessage("first argument is invalid json", errA, nil)
+	}
+	if errB != nil {
+		return SecondArgIsInvalidJson, ctx.invalidJsonMessage("second argument is invalid json", nil, errB)
+	}
+	d := ctx.compareRoots(av, bv)
+	return ctx.diff, ctx.render(d)
+}
@@ -0,0 +1,113 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type evenMatcher struct{}
+
+func (evenMatcher) Match(v interface{}) bool {
+	n, ok := v.(json.Number)
+	if !ok {
+		return false
+	}
+	i, err := n.Int64()
+	return err == nil && i%2 == 0
+}
+
+func (evenMatcher) String() string { return "<even>" }
+
+func TestCompareInterfaces(t *testing.T) {
+	opts := DefaultJSONOptions()
+	opts.MarshalUnknownValues = true
+	type point struct {
+		X int `json:"x"`
+	}
+	a := map[string]interface{}{"n": 4, "p": point{1}, "s": []int{1, 2}}
+	result, _ := CompareInterfaces(a, map[string]interface{}{"n": evenMatcher{}, "p": map[string]int{"x": 1}, "s": []interface{}{1, evenMatcher{}}}, &opts)
+	if result != FullMatch {
+		t.Errorf("got %s, expected FullMatch", result)
+	}
+
+	opts.Format = JDOutput
+	result, diff := CompareInterfaces(a, Expect{"n": evenMatcher{}, "s": []interface{}{evenMatcher{}}}, &opts)
+	if result != NoMatch || diff != "@ [\"p\"]\n- {\"x\":1}\n@ [\"s\",0]\n- 1\n- 2\n+ \"<even>\"\n" {
+		t.Errorf("got %s:\n%s", result, diff)
+	}
+
+	// placeholders are matchers, while matcher descriptions in encoded
+	// documents are plain strings
+	opts.Format = TextOutput
+	even := MatchFunc("<even>", evenMatcher{}.Match)
+	if result, _ := CompareInterfaces(a, Expect{"n": even, "p": Present, "s": TypeArray}, &opts); result != FullMatch {
+		t.Errorf("got %s, expected FullMatch", result)
+	}
+	if result, _ := Compare([]byte(`{"n": 4}`), []byte(`{"n": "<even>"}`), &opts); result != NoMatch {
+		t.Errorf("got %s, expected NoMatch", result)
+	}
+
+	if result, _ := CompareInterfaces(math.NaN(), nil, &opts); result != FirstArgIsInvalidJson {
+		t.Errorf("got %s, expected FirstArgIsInvalidJson", result)
+	}
+}
+
+func TestCompareInterfacesUnsupportedValues(t *testing.T) {
+	opts := DefaultJSONOptions()
+	opts.VerboseErrors = true
+	type point struct {
+		X int `json:"x"`
+	}
+	x := 1
+	cases := []struct {
+		a, b    interface{}
+		marshal bool
+		result  Difference
+		message string
+	}{
+		{map[string]interface{}{"p": &x, "a": [2]int{1, 2}, "t": time.Unix(0, 0).UTC()},
+			map[string]interface{}{"p": 1, "a": []int{1, 2}, "t": "1970-01-01T00:00:00Z"}, false, FullMatch, ""},
+		{nil, map[string]interface{}{"list": []interface{}{1, point{1}}}, false, SecondArgIsInvalidJson,
+			`second argument is invalid json: jsondiff: unsupported struct value of type jsondiff.point at "list.1"`},
+		{[]interface{}{point{1}}, []interface{}{map[string]int{"x": 1}}, true, FullMatch, ""},
+		{make(chan int), func() {}, false, BothArgsAreInvalidJson,
+			"both arguments are invalid json: first: jsondiff: unsupported chan value of type chan int at the root; " +
+				"second: jsondiff: unsupported func value of type func() at the root"},
+		{map[string]interface{}{"c": make(chan int)}, nil, true, FirstArgIsInvalidJson,
+			`first argument is invalid json: jsondiff: encoding value at "c": json: unsupported type: chan int`},
+	}
+	for i, c := range cases {
+		opts.MarshalUnknownValues = c.marshal
+		result, message := CompareInterfaces(c.a, c.b, &opts)
+		if result != c.result || (c.message != "" && message != c.message) {
+			t.Errorf("case %d: got %s: %s, expected %s: %s", i, result, message, c.result, c.message)
+		}
+	}
+}
+
+func TestCompareInterfacesTypeComparators(t *testing.T) {
+	opts := DefaultJSONOptions()
+	opts.TypeComparators = map[reflect.Type]func(a, b interface{}) bool{
+		reflect.TypeOf(time.Time{}): func(a, b interface{}) bool {
+			return a.(time.Time).Equal(b.(time.Time))
+		},
+	}
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	a := map[string]interface{}{"at": at, "list": []time.Time{at}}
+	b := map[string]interface{}{"at": at.In(time.FixedZone("CEST", 2*60*60)), "list": []time.Time{at.Local()}}
+	if result, diff := CompareInterfaces(a, b, &opts); result != FullMatch {
+		t.Errorf("got %s:\n%s", result, diff)
+	}
+
+	opts.Format = JDOutput
+	b = map[string]interface{}{"at": at.Add(time.Second), "list": []interface{}{at.Format(time.RFC3339)}}
+	result, diff := CompareInterfaces(a, b, &opts)
+	expected := "@ [\"at\"]\n- \"2024-05-01T12:00:00Z\"\n+ \"2024-05-01T12:00:01Z\"\n" +
+		"@ [\"list\",0]\n- \"2024-05-01T12:00:00Z\"\n+ \"2024-05-01T12:00:00Z\"\n"
+	if result != NoMatch || diff != expected {
+		t.Errorf("got %s:\n%s", result, diff)
+	}
+}
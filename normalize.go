@@ -0,0 +1,127 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Normalizer is a value transformation step, see Options.Transform and
+// Normalize.
+type Normalizer func(path string, v interface{}) interface{}
+
+// Normalize composes normalization steps into a single function suitable for
+// Options.Transform. Steps are applied in order.
+func Normalize(steps ...Normalizer) func(path string, v interface{}) interface{} {
+	return func(path string, v interface{}) interface{} {
+		for _, step := range steps {
+			v = step(path, v)
+		}
+		return v
+	}
+}
+
+func matchesAny(patterns []string, path string) bool {
+	p := splitPath(path)
+	for _, pattern := range patterns {
+		if matchPath(splitPath(pattern), p) {
+			return true
+		}
+	}
+	return false
+}
+
+func childPath(path, elem string) string {
+	if path == "" {
+		return elem
+	}
+	return path + "." + elem
+}
+
+// SortArrays returns a normalizer which sorts arrays at paths matching any of
+// the patterns, so that their order doesn't matter. Elements are ordered by
+// their JSON encoding.
+func SortArrays(patterns ...string) Normalizer {
+	return func(path string, v interface{}) interface{} {
+		s, ok := v.([]interface{})
+		if !ok || !matchesAny(patterns, path) {
+			return v
+		}
+		type elem struct {
+			key string
+			v   interface{}
+		}
+		elems := make([]elem, len(s))
+		for i, e := range s {
+			elems[i] = elem{encodeJSON(e, "", ""), e}
+		}
+		sort.SliceStable(elems, func(i, j int) bool {
+			return elems[i].key < elems[j].key
+		})
+		out := make([]interface{}, len(s))
+		for i, e := range elems {
+			out[i] = e.v
+		}
+		return out
+	}
+}
+
+// DropKeys returns a normalizer which removes object properties at paths
+// matching any of the patterns.
+func DropKeys(patterns ...string) Normalizer {
+	return func(path string, v interface{}) interface{} {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		var out map[string]interface{}
+		for k := range m {
+			if !matchesAny(patterns, childPath(path, k)) {
+				continue
+			}
+			if out == nil {
+				out = make(map[string]interface{}, len(m))
+				for k, v := range m {
+					out[k] = v
+				}
+			}
+			delete(out, k)
+		}
+		if out == nil {
+			return m
+		}
+		return out
+	}
+}
+
+// RoundNumbers returns a normalizer which rounds numbers at paths matching any
+// of the patterns to the given number of decimal places. Numbers which can't
+// be parsed as floats are left intact.
+func RoundNumbers(places int, patterns ...string) Normalizer {
+	scale := math.Pow(10, float64(places))
+	return func(path string, v interface{}) interface{} {
+		n, ok := v.(json.Number)
+		if !ok || !matchesAny(patterns, path) {
+			return v
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return v
+		}
+		return json.Number(strconv.FormatFloat(math.Round(f*scale)/scale, 'f', -1, 64))
+	}
+}
+
+// LowercaseStrings returns a normalizer which converts strings at paths
+// matching any of the patterns to lower case.
+func LowercaseStrings(patterns ...string) Normalizer {
+	return func(path string, v interface{}) interface{} {
+		s, ok := v.(string)
+		if !ok || !matchesAny(patterns, path) {
+			return v
+		}
+		return strings.ToLower(s)
+	}
+}
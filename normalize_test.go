@@ -0,0 +1,33 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	opts := DefaultConsoleOptions()
+	opts.Transform = Normalize(
+		SortArrays("tags"),
+		DropKeys("**.updatedAt"),
+		RoundNumbers(2, "**.price"),
+		LowercaseStrings("**.email"),
+	)
+	cases := []struct {
+		a      string
+		b      string
+		result Difference
+	}{
+		{`{"tags": ["b", "a", {"x": 1}]}`, `{"tags": [{"x": 1}, "a", "b"]}`, FullMatch},
+		{`{"other": ["b", "a"]}`, `{"other": ["a", "b"]}`, NoMatch},
+		{`{"item": {"updatedAt": 1, "x": 2}}`, `{"item": {"x": 2, "updatedAt": 5}}`, FullMatch},
+		{`{"items": [{"price": 1.004}]}`, `{"items": [{"price": 1.0}]}`, FullMatch},
+		{`{"items": [{"price": 1.006}]}`, `{"items": [{"price": 1.0}]}`, NoMatch},
+		{`{"email": "A@B.C"}`, `{"email": "a@b.c"}`, FullMatch},
+	}
+	for i, c := range cases {
+		result, _ := Compare([]byte(c.a), []byte(c.b), &opts)
+		if result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s", i, result, c.result)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package jsondiff
+
+import (
+	"sort"
+)
+
+func isNumericKey(k string) bool {
+	if k == "" || (k[0] == '0' && len(k) > 1) {
+		return false
+	}
+	for i := 0; i < len(k); i++ {
+		if k[i] < '0' || k[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// numericKeyLess orders numeric keys numerically without parsing them, so
+// that arbitrary large keys are supported.
+func numericKeyLess(a, b string) bool {
+	an, bn := isNumericKey(a), isNumericKey(b)
+	switch {
+	case an && bn:
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	case an != bn:
+		return an
+	}
+	return a < b
+}
+
+func (ctx *context) sortKeys(keys []string) {
+	if ctx.opts.KeyOrder != nil {
+		sort.Slice(keys, func(i, j int) bool {
+			return ctx.opts.KeyOrder(keys[i], keys[j])
+		})
+	} else if ctx.opts.NumericKeyOrder {
+		sort.Slice(keys, func(i, j int) bool {
+			return numericKeyLess(keys[i], keys[j])
+		})
+	} else {
+		sort.Strings(keys)
+	}
+}
+
+// numericKeysToArray converts a non-empty object with only numeric keys to an
+// array of values ordered by key, any other value is returned as is.
+func numericKeysToArray(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return v
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if !isNumericKey(k) {
+			return v
+		}
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return numericKeyLess(keys[i], keys[j])
+	})
+	s := make([]interface{}, len(keys))
+	for i, k := range keys {
+		s[i] = m[k]
+	}
+	return s
+}
+
+// isArray tells whether the value is an array, e.g. converted from an object by
+// numericKeysToArray.
+func isArray(v interface{}) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
@@ -0,0 +1,49 @@
+package jsondiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNumericKeyOrder(t *testing.T) {
+	opts := Options{Indent: " ", NumericKeyOrder: true}
+	_, diff := Compare(
+		[]byte(`{"10": 1, "2": 1, "a": 1, "100000000000000000000": 1, "02": 1}`),
+		[]byte(`{"10": 1, "2": 1, "a": 1, "100000000000000000000": 1, "02": 1}`),
+		&opts,
+	)
+	expected := strings.Join([]string{
+		`{`,
+		` "2": 1,`,
+		` "10": 1,`,
+		` "100000000000000000000": 1,`,
+		` "02": 1,`,
+		` "a": 1`,
+		`}`,
+	}, "\n")
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+}
+
+func TestNumericKeysAsArrays(t *testing.T) {
+	cases := []struct {
+		a      string
+		b      string
+		result Difference
+	}{
+		{`{"1": "a", "5": "b"}`, `{"1": "a", "2": "b"}`, FullMatch},
+		{`{"1": "a", "10": "b"}`, `{"2": "b", "1": "a"}`, FullMatch},
+		{`{"1": "a", "5": "b"}`, `{"1": "a"}`, SupersetMatch},
+		{`{"1": "a"}`, `["a"]`, FullMatch},
+		{`{"1": "a", "x": "b"}`, `{"1": "a", "2": "b"}`, NoMatch},
+	}
+	opts := DefaultConsoleOptions()
+	opts.NumericKeysAsArrays = true
+	for i, c := range cases {
+		result, _ := Compare([]byte(c.a), []byte(c.b), &opts)
+		if result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s", i, result, c.result)
+		}
+	}
+}
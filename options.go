@@ -0,0 +1,118 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+var presets = struct {
+	sync.RWMutex
+	m map[string]Options
+}{m: map[string]Options{
+	"console":     DefaultConsoleOptions(),
+	"html":        DefaultHTMLOptions(),
+	"json":        DefaultJSONOptions(),
+	"markers":     DefaultMarkerOptions(),
+	"hal":         halOptions(),
+	"cloudevents": cloudEventsOptions(),
+	"extjson":     extendedJSONOptions(),
+}}
+
+// halOptions are the JSON options comparing links of HAL documents by their
+// paths, see HALLinks.
+func halOptions() Options {
+	opts := DefaultJSONOptions()
+	opts.Transform = Normalize(HALLinks())
+	return opts
+}
+
+// cloudEventsOptions are the JSON options comparing CloudEvents without
+// attributes specific to a producer, see CloudEvents.
+func cloudEventsOptions() Options {
+	opts := DefaultJSONOptions()
+	opts.Transform = Normalize(CloudEvents(true))
+	return opts
+}
+
+// extendedJSONOptions are the JSON options comparing MongoDB Extended JSON
+// documents as plain JSON, see ExtendedJSON.
+func extendedJSONOptions() Options {
+	opts := DefaultJSONOptions()
+	opts.Transform = Normalize(ExtendedJSON())
+	return opts
+}
+
+// RegisterPreset registers options under the given name, so that they can be
+// referenced by name later, e.g. from configuration files. Registering an
+// existing name replaces the preset. Built-in presets are "console", "html",
+// "json" and "markers", see the corresponding Default*Options functions, and
+// "hal", "cloudevents" and "extjson", which are "json" with HAL documents,
+// CloudEvents and MongoDB Extended JSON normalized, see HALLinks, CloudEvents
+// and ExtendedJSON. Data of events is decoded from base64.
+func RegisterPreset(name string, opts Options) {
+	presets.Lock()
+	presets.m[name] = opts
+	presets.Unlock()
+}
+
+// PresetOptions returns options registered under the given name. Returns
+// false if there is no such preset.
+func PresetOptions(name string) (Options, bool) {
+	presets.RLock()
+	opts, ok := presets.m[name]
+	presets.RUnlock()
+	return opts, ok
+}
+
+// Merge returns a copy of options with all the non-zero fields of override
+// applied on top. Zero values (false, 0, empty strings, nil functions and
+// slices) in override leave the corresponding fields intact, hence it's not
+// possible to reset a field to its zero value using Merge.
+func (opts Options) Merge(override Options) Options {
+	dst := reflect.ValueOf(&opts).Elem()
+	src := reflect.ValueOf(override)
+	for i := 0; i < src.NumField(); i++ {
+		if f := src.Field(i); !f.IsZero() {
+			dst.Field(i).Set(f)
+		}
+	}
+	return opts
+}
+
+// WithSkipMatches returns a copy of options with SkipMatches set.
+func (opts Options) WithSkipMatches(skip bool) Options {
+	opts.SkipMatches = skip
+	return opts
+}
+
+// WithIgnore returns a copy of options with path patterns added to Ignore.
+func (opts Options) WithIgnore(patterns ...string) Options {
+	opts.Ignore = append(opts.Ignore[:len(opts.Ignore):len(opts.Ignore)], patterns...)
+	return opts
+}
+
+// WithOptionalKeys returns a copy of options with path patterns added to
+// OptionalKeys.
+func (opts Options) WithOptionalKeys(patterns ...string) Options {
+	opts.OptionalKeys = append(opts.OptionalKeys[:len(opts.OptionalKeys):len(opts.OptionalKeys)], patterns...)
+	return opts
+}
+
+// WithSkip returns a copy of options with Skip set.
+func (opts Options) WithSkip(skip func(path string) SkipResult) Options {
+	opts.Skip = skip
+	return opts
+}
+
+// WithCompareNumbers returns a copy of options with CompareNumbers set.
+func (opts Options) WithCompareNumbers(compare func(a, b json.Number) bool) Options {
+	opts.CompareNumbers = compare
+	return opts
+}
+
+// WithFormat returns a copy of options with Format set.
+func (opts Options) WithFormat(format OutputFormat) Options {
+	opts.Format = format
+	return opts
+}
@@ -0,0 +1,57 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+func TestOptionsMerge(t *testing.T) {
+	base := DefaultConsoleOptions()
+	base.OptionalKeys = []string{"a"}
+	merged := base.Merge(Options{
+		Indent:      "  ",
+		SkipMatches: true,
+		Changed:     Tag{Begin: "(C:", End: ":C)"},
+	})
+	if merged.Indent != "  " || !merged.SkipMatches || merged.Changed.Begin != "(C:" {
+		t.Errorf("override is not applied: %+v", merged)
+	}
+	if merged.Added != base.Added || merged.ChangedSeparator != base.ChangedSeparator ||
+		merged.SkippedArrayElement == nil || len(merged.OptionalKeys) != 1 {
+		t.Errorf("base options are lost: %+v", merged)
+	}
+	if base.Indent != "    " || base.SkipMatches {
+		t.Errorf("base options are modified: %+v", base)
+	}
+}
+
+func TestPresets(t *testing.T) {
+	if opts, ok := PresetOptions("html"); !ok || opts.Added != DefaultHTMLOptions().Added {
+		t.Errorf("built-in html preset is missing")
+	}
+	strict := DefaultConsoleOptions()
+	strict.EmptyObject = EmptyCollectionMatchesEmpty
+	RegisterPreset("test-strict", strict)
+	opts, ok := PresetOptions("test-strict")
+	if !ok || opts.EmptyObject != EmptyCollectionMatchesEmpty {
+		t.Errorf("registered preset is missing")
+	}
+	if _, ok := PresetOptions("no-such-preset"); ok {
+		t.Errorf("unexpected preset")
+	}
+}
+
+func TestOptionsBuilders(t *testing.T) {
+	base := DefaultConsoleOptions().WithIgnore("a")
+	opts := base.WithSkipMatches(true).WithIgnore("b.*").WithOptionalKeys("c")
+	if len(base.Ignore) != 1 || base.SkipMatches {
+		t.Errorf("base options are modified: %+v", base)
+	}
+	other := base.WithIgnore("d")
+	if opts.Ignore[1] != "b.*" || other.Ignore[1] != "d" {
+		t.Errorf("derived options share Ignore: %q, %q", opts.Ignore, other.Ignore)
+	}
+	result, _ := Compare([]byte(`{"a": 1, "b": {"x": 2}, "c": 3}`), []byte(`{"a": 2, "b": {"x": 3}}`), &opts)
+	if result != FullMatch {
+		t.Errorf("got %s, expected FullMatch", result)
+	}
+}
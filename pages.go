@@ -0,0 +1,109 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ComparePages compares two paginated result sets, e.g. responses of an API
+// fetched page by page. Each page is a JSON document with an array of items at
+// the given path, an empty path means that the page is the array itself.
+// Other properties of pages, such as cursors, are ignored.
+//
+// Items of all pages are concatenated and compared as a single array. When key
+// is not empty, items are matched by the value of their key property instead
+// of their position, and compared as an object indexed by key values. Items
+// which don't have the key property or have a duplicate value of it are
+// indexed by "#" followed by their position in the concatenated array, key
+// values starting with "#" are prefixed with another one.
+//
+// Returns the same values as Compare. Documents are considered invalid when
+// any of their pages is invalid JSON or doesn't have an array at the items
+// path.
+func ComparePages(a, b [][]byte, items, key string, opts *Options) (Difference, string) {
+	ctx := context{opts: opts}
+	av, errA := ctx.decodePages(a, items, key)
+	bv, errB := ctx.decodePages(b, items, key)
+	if errA != nil && errB != nil {
+		return BothArgsAreInvalidJson, ctx.invalidJsonMessage("both arguments are invalid json", errA, errB)
+	}
+	if errA != nil {
+		return FirstArgIsInvalidJson, ctx.invalidJsonMessage("first argument is invalid json", errA, nil)
+	}
+	if errB != nil {
+		return SecondArgIsInvalidJson, ctx.invalidJsonMessage("second argument is invalid json", nil, errB)
+	}
+	d := ctx.compare(ctx.transform(av), ctx.transform(bv))
+	return ctx.diff, ctx.render(d)
+}
+
+func (ctx *context) decodePages(pages [][]byte, items, key string) (interface{}, error) {
+	path := splitPath(items)
+	all := []interface{}{}
+	for i, page := range pages {
+		v, err := ctx.decode(bytes.NewReader(page))
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %v", i, err)
+		}
+		v, _ = lookupPath(v, path)
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("page %d: no array at %q", i, items)
+		}
+		all = append(all, arr...)
+	}
+	if key == "" {
+		return all, nil
+	}
+	return indexByKey(all, key), nil
+}
+
+// indexByKey converts an array of objects to an object indexed by the value of
+// the key property of each element. Elements without a unique key are indexed
+// by "#" followed by their position, while key values starting with "#" are
+// prefixed with another one, so that they can't collide.
+func indexByKey(arr []interface{}, key string) map[string]interface{} {
+	m := make(map[string]interface{}, len(arr))
+	for i, v := range arr {
+		k, ok := itemKey(v, key)
+		if ok && strings.HasPrefix(k, "#") {
+			k = "#" + k
+		}
+		if _, dup := m[k]; !ok || dup {
+			k = "#" + strconv.Itoa(i)
+		}
+		m[k] = v
+	}
+	return m
+}
+
+func itemKey(v interface{}, key string) (string, bool) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	kv, ok := obj[key]
+	if !ok {
+		return "", false
+	}
+	return keyString(kv)
+}
+
+// keyString returns the string representation of a value identifying an
+// item: strings and numbers as is, other values as JSON.
+func keyString(kv interface{}) (string, bool) {
+	switch kv := kv.(type) {
+	case string:
+		return kv, true
+	case json.Number:
+		return kv.String(), true
+	}
+	data, err := json.Marshal(kv)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
@@ -0,0 +1,64 @@
+package jsondiff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComparePages(t *testing.T) {
+	opts := DefaultConsoleOptions()
+	opts.Added = Tag{Begin: "+", End: "+"}
+	opts.Removed = Tag{Begin: "-", End: "-"}
+	opts.Changed = Tag{Begin: "~", End: "~"}
+	opts.SkipMatches = true
+	opts.SkippedObjectProperty = nil
+	a := [][]byte{
+		[]byte(`{"items": [{"id": 1, "v": "a"}, {"id": 2, "v": "b"}], "cursor": "x"}`),
+		[]byte(`{"items": [{"id": 3, "v": "c"}], "cursor": null}`),
+	}
+	b := [][]byte{
+		[]byte(`{"items": [{"id": 2, "v": "b"}], "cursor": "y"}`),
+		[]byte(`{"items": [{"id": 1, "v": "a"}, {"id": 3, "v": "d"}], "cursor": null}`),
+	}
+	result, diff := ComparePages(a, b, "items", "id", &opts)
+	if result != NoMatch {
+		t.Fatalf("got %s, expected NoMatch", result)
+	}
+	expected := "{\n    \"3\": {\n        \"v\": ~\"c\" => \"d\"~\n    }\n}"
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+
+	b[1] = []byte(`{"items": [{"id": 1, "v": "a"}, {"id": 3, "v": "c"}], "cursor": null}`)
+	if result, diff := ComparePages(a, b, "items", "id", &opts); result != FullMatch {
+		t.Errorf("got %s, expected FullMatch:\n%s", result, diff)
+	}
+	if result, _ := ComparePages(a, b, "items", "", &opts); result != NoMatch {
+		t.Errorf("got %s, expected NoMatch when matching by position", result)
+	}
+
+	arrays := [][]byte{[]byte(`[1, 2]`), []byte(`[3]`)}
+	if result, _ := ComparePages(arrays, [][]byte{[]byte(`[1, 2, 3]`)}, "", "", &opts); result != FullMatch {
+		t.Errorf("got %s, expected FullMatch for array pages", result)
+	}
+
+	opts.VerboseErrors = true
+	result, msg := ComparePages(a, [][]byte{[]byte(`{"items": 1}`)}, "items", "id", &opts)
+	if result != SecondArgIsInvalidJson || msg != `second argument is invalid json: page 0: no array at "items"` {
+		t.Errorf("got %s %q", result, msg)
+	}
+}
+
+func TestIndexByKey(t *testing.T) {
+	arr := []interface{}{
+		map[string]interface{}{"id": "#1"},
+		map[string]interface{}{"x": 1},
+		map[string]interface{}{"id": "a"},
+		map[string]interface{}{"id": "a"},
+	}
+	m := indexByKey(arr, "id")
+	expected := map[string]interface{}{"##1": arr[0], "#1": arr[1], "a": arr[2], "#3": arr[3]}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("got %v, expected %v", m, expected)
+	}
+}
@@ -0,0 +1,189 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+type patchOp struct {
+	Op    string          `json:"op"`
+	From  string          `json:"from,omitempty"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+
+	// removed or added value and whether it's an object property, used to
+	// detect moves and copies
+	value    interface{}
+	property bool
+}
+
+func newPatchOp(op, path string, v interface{}, property bool) patchOp {
+	o := patchOp{Op: op, Path: path, value: v, property: property}
+	if op != "remove" {
+		o.Value = json.RawMessage(encodeJSON(v, "", ""))
+	}
+	return o
+}
+
+func patchSize(ops []patchOp) int {
+	n := 0
+	for i := range ops {
+		n += len(ops[i].Op) + len(ops[i].From) + len(ops[i].Path) + len(ops[i].Value)
+	}
+	return n
+}
+
+func (ctx *context) patchOps(d *delta, path string, property bool) []patchOp {
+	if orig, ok := ctx.originals[d]; ok {
+		// paths inside of the collection don't exist in the document
+		if !d.differs {
+			return nil
+		}
+		return []patchOp{newPatchOp("replace", path, orig.b, property)}
+	}
+	switch d.kind {
+	case deltaChanged:
+		return []patchOp{newPatchOp("replace", path, d.b, property)}
+	case deltaAdded:
+		return []patchOp{newPatchOp("add", path, d.b, property)}
+	case deltaRemoved:
+		return []patchOp{newPatchOp("remove", path, d.a, property)}
+	case deltaCollection:
+		if !d.differs {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	var ops []patchOp
+	removed := -1
+	for i, e := range d.elems {
+		if d.isObject() {
+			ops = append(ops, ctx.patchOps(e, pointerChild(path, d.keys[i]), true)...)
+			continue
+		}
+		ops = append(ops, ctx.patchOps(e, pointerChild(path, strconv.Itoa(i)), false)...)
+		if e.kind == deltaRemoved && removed < 0 {
+			removed = len(ops) - 1
+		}
+	}
+	if removed >= 0 {
+		// elements are removed from the end of the array, so they have to be
+		// removed starting from the last one to keep the indices valid
+		for i, j := removed, len(ops)-1; i < j; i, j = i+1, j-1 {
+			ops[i], ops[j] = ops[j], ops[i]
+		}
+	}
+	if ctx.opts.CompactPatch {
+		replace := []patchOp{newPatchOp("replace", path, d.b, property)}
+		if patchSize(replace) < patchSize(ops) {
+			return replace
+		}
+	}
+	return ops
+}
+
+// patchSources collects pointers to the values which are not changed, in the
+// order of the document.
+func (ctx *context) patchSources(d *delta, path string, sources []patchOp) []patchOp {
+	if orig, ok := ctx.originals[d]; ok {
+		if !d.differs {
+			sources = append(sources, patchOp{Path: path, value: orig.b})
+		}
+		return sources
+	}
+	if d.kind == deltaMatch || (d.kind == deltaCollection && !d.differs) {
+		return append(sources, patchOp{Path: path, value: d.b})
+	}
+	if d.kind != deltaCollection {
+		return sources
+	}
+	for i, e := range d.elems {
+		if d.isObject() {
+			sources = ctx.patchSources(e, pointerChild(path, d.keys[i]), sources)
+		} else {
+			sources = ctx.patchSources(e, pointerChild(path, strconv.Itoa(i)), sources)
+		}
+	}
+	return sources
+}
+
+// compactPatch replaces added values with moves of removed object properties,
+// and with copies of unchanged values when it makes the patch smaller. Values
+// are looked up by their encoding, so that the patch is compacted in linear
+// time.
+func compactPatch(ops []patchOp, sources []patchOp) []patchOp {
+	removed := make(map[string][]int)
+	for j := range ops {
+		if ops[j].Op == "remove" && ops[j].property {
+			k := encodeJSON(ops[j].value, "", "")
+			removed[k] = append(removed[k], j)
+		}
+	}
+	var copies map[string]int
+	for i := range ops {
+		add := &ops[i]
+		if add.Op != "add" {
+			continue
+		}
+		if add.property {
+			k := string(add.Value)
+			// moved properties are dropped from the front of the list
+			rms := removed[k]
+			for len(rms) > 0 && ops[rms[0]].Op != "remove" {
+				rms = rms[1:]
+			}
+			removed[k] = rms
+			for _, j := range rms {
+				rm := &ops[j]
+				if rm.Op == "remove" && reflect.DeepEqual(rm.value, add.value) {
+					*add = patchOp{Op: "move", From: rm.Path, Path: add.Path}
+					rm.Op = ""
+					break
+				}
+			}
+			if add.Op != "add" {
+				continue
+			}
+		}
+		if copies == nil {
+			copies = copySources(sources)
+		}
+		if j, ok := copies[string(add.Value)]; ok && reflect.DeepEqual(sources[j].value, add.value) {
+			*add = patchOp{Op: "copy", From: sources[j].Path, Path: add.Path}
+		}
+	}
+	compacted := ops[:0]
+	for _, o := range ops {
+		if o.Op != "" {
+			compacted = append(compacted, o)
+		}
+	}
+	return compacted
+}
+
+// copySources maps encoded values to the first source which is shorter to
+// copy than to add the value.
+func copySources(sources []patchOp) map[string]int {
+	copies := make(map[string]int)
+	for j, s := range sources {
+		k := encodeJSON(s.value, "", "")
+		if _, ok := copies[k]; !ok && len(s.Path) < len(k) {
+			copies[k] = j
+		}
+	}
+	return copies
+}
+
+func (ctx *context) renderPatch(d *delta) string {
+	ops := ctx.patchOps(d, ctx.root, false)
+	if ctx.opts.CompactPatch {
+		ops = compactPatch(ops, ctx.patchSources(d, ctx.root, nil))
+	}
+	if ops == nil {
+		ops = []patchOp{}
+	}
+	return encodeJSON(ops, ctx.opts.Prefix, ctx.opts.Indent)
+}
@@ -0,0 +1,152 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var patchCases = []struct {
+	a        string
+	b        string
+	compact  bool
+	expected string
+}{
+	{`{"a": [1, 2, 3], "b": "foo", "c": null}`, `{"a": [1, 5], "b": "foo", "d": null}`, false,
+		`[{"op":"replace","path":"/a/1","value":5},{"op":"remove","path":"/a/2"},{"op":"remove","path":"/c"},{"op":"add","path":"/d","value":null}]`},
+	{`[1, 2, 3, 4]`, `[1]`, false,
+		`[{"op":"remove","path":"/3"},{"op":"remove","path":"/2"},{"op":"remove","path":"/1"}]`},
+	{`{"a/b": 1}`, `{"a/b": 1}`, false, `[]`},
+	{`[1, 2, 3, 4]`, `[5, 6, 7]`, true, `[{"op":"replace","path":"","value":[5,6,7]}]`},
+	{`{"x": {"a": 1}, "long_property_name": "some long value", "y": "unchanged value to keep"}`,
+		`{"x": {"a": 2}, "other": "some long value", "y": "unchanged value to keep"}`, true,
+		`[{"op":"move","from":"/long_property_name","path":"/other"},{"op":"replace","path":"/x/a","value":2}]`},
+	{`{"a": "some long value", "b": {}}`, `{"a": "some long value", "b": {"c": "some long value"}}`, true,
+		`[{"op":"copy","from":"/a","path":"/b/c"}]`},
+}
+
+// applyPatch applies JSON Patch operations produced by the tests.
+func applyPatch(t *testing.T, doc interface{}, ops []patchOp) interface{} {
+	for _, o := range ops {
+		path, _ := splitPointer(o.Path)
+		var v interface{}
+		var err error
+		switch o.Op {
+		case "add", "replace":
+			kind := ChangeAdded
+			if o.Op == "replace" {
+				kind = ChangeModified
+			}
+			json.Unmarshal(o.Value, &v)
+			doc, err = patch(doc, path, kind, v)
+		case "remove":
+			doc, err = patch(doc, path, ChangeRemoved, nil)
+		case "move", "copy":
+			from, _ := splitPointer(o.From)
+			v, _ = lookupPath(doc, from)
+			if o.Op == "move" {
+				doc, err = patch(doc, from, ChangeRemoved, nil)
+			}
+			if err == nil {
+				doc, err = patch(doc, path, ChangeAdded, v)
+			}
+		}
+		if err != nil {
+			t.Fatalf("%s %s: %v", o.Op, o.Path, err)
+		}
+	}
+	return doc
+}
+
+func TestJSONPatchOutput(t *testing.T) {
+	for _, c := range patchCases {
+		opts := Options{Format: JSONPatchOutput, CompactPatch: c.compact}
+		_, diff := Compare([]byte(c.a), []byte(c.b), &opts)
+		if diff != c.expected {
+			t.Errorf("%s %s: got:\n%s\nexpected:\n%s", c.a, c.b, diff, c.expected)
+			continue
+		}
+		var ops []patchOp
+		if err := json.Unmarshal([]byte(diff), &ops); err != nil {
+			t.Fatal(err)
+		}
+		var a, b interface{}
+		json.Unmarshal([]byte(c.a), &a)
+		json.Unmarshal([]byte(c.b), &b)
+		if got := applyPatch(t, a, ops); !reflect.DeepEqual(got, b) {
+			t.Errorf("%s %s: patch produced %v", c.a, c.b, got)
+		}
+	}
+}
+
+func TestJSONPatchOutputQuickFullMatch(t *testing.T) {
+	opts := Options{Format: JSONPatchOutput, QuickFullMatch: true, SkipMatches: true, Indent: "  "}
+	if _, diff := Compare([]byte(`{"a": 1}`), []byte(`{"a":1}`), &opts); diff != "[]" {
+		t.Errorf("got %q, expected empty patch", diff)
+	}
+	_, diff := Compare([]byte(`[1]`), []byte(`[2]`), &opts)
+	var buf bytes.Buffer
+	json.Indent(&buf, []byte(`[{"op":"replace","path":"/0","value":2}]`), "", "  ")
+	if diff != strings.TrimSpace(buf.String()) {
+		t.Errorf("got indented patch:\n%s", diff)
+	}
+}
+
+// rewritingCases are documents compared with options which change the shape
+// of collections, their patches and changes must still apply to the first
+// document. Exact results are expected to be identical to the second document,
+// the rest only match it with the same options.
+var rewritingCases = []struct {
+	opts  Options
+	a     string
+	b     string
+	exact bool
+}{
+	{Options{UnorderedArrays: []string{"x"}}, `{"x": [1, 2, 3]}`, `{"x": [3, 1, 9]}`, true},
+	{Options{UnorderedArrays: []string{"x"}}, `{"x": [1, 2, 3], "y": 1}`, `{"x": [3, 2, 1], "y": 2}`, false},
+	{Options{SortArraysAt: map[string]func(a, b interface{}) bool{"x": func(a, b interface{}) bool {
+		return a.(string) < b.(string)
+	}}}, `{"x": ["c", "a", "b"], "y": 1}`, `{"x": ["b", "d", "a"], "y": 2}`, true},
+	{Options{NumericKeysAsArrays: true}, `{"x": {"0": "a", "1": "b"}}`, `{"x": {"0": "a", "2": "c"}}`, true},
+	{Options{NumericKeysAsArrays: true}, `{"x": {"0": "a"}, "y": [1]}`, `{"x": ["b"], "y": {"0": 1}}`, false},
+	{Options{KeyedArrays: map[string]string{"x": "id"}}, `{"x": [{"id": 1, "v": 1}, {"id": 2}]}`, `{"x": [{"id": 2}, {"id": 1, "v": 2}]}`, true},
+	{Options{FirstRoot: "data", SecondRoot: "result"}, `{"data": {"a": [1, 2], "b": 1}, "meta": 1}`, `{"result": {"a": [2], "c": 1}}`, false},
+	{Options{FirstRoot: "data.0", SecondRoot: "missing"}, `{"data": [{"a": 1}], "meta": 1}`, `{"a": 2}`, false},
+}
+
+// checkRewritingResult checks the result of applying a patch or changes of a
+// rewritingCases element to the first document.
+func checkRewritingResult(t *testing.T, i int, result []byte) {
+	t.Helper()
+	c := rewritingCases[i]
+	if c.exact {
+		var got, b interface{}
+		json.Unmarshal(result, &got)
+		json.Unmarshal([]byte(c.b), &b)
+		if !reflect.DeepEqual(got, b) {
+			t.Errorf("%s %s: got %s", c.a, c.b, result)
+		}
+		return
+	}
+	opts := c.opts
+	if diff, _ := Compare(result, []byte(c.b), &opts); diff != FullMatch {
+		t.Errorf("%s %s: got %s, %s", c.a, c.b, result, diff)
+	}
+}
+
+func TestJSONPatchRewritingOptions(t *testing.T) {
+	for i, c := range rewritingCases {
+		opts := c.opts
+		opts.Format = JSONPatchOutput
+		_, diff := Compare([]byte(c.a), []byte(c.b), &opts)
+		var ops []patchOp
+		if err := json.Unmarshal([]byte(diff), &ops); err != nil {
+			t.Fatal(err)
+		}
+		var a interface{}
+		json.Unmarshal([]byte(c.a), &a)
+		checkRewritingResult(t, i, []byte(encodeJSON(applyPatch(t, a, ops), "", "")))
+	}
+}
@@ -0,0 +1,120 @@
+package jsondiff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// splitPath splits a path pattern, see Compare documentation for the syntax.
+func splitPath(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ".")
+}
+
+func matchPath(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "**":
+			for i := 0; i <= len(path); i++ {
+				if matchPath(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(path) == 0 {
+				return false
+			}
+		default:
+			if len(path) == 0 || pattern[0] != path[0] {
+				return false
+			}
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
+// pathMatches returns true if the path of the value being compared matches
+// any of the patterns.
+func (ctx *context) pathMatches(patterns []string) bool {
+	for _, p := range patterns {
+		if matchPath(splitPath(p), ctx.path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ctx *context) pathString() string {
+	return strings.Join(ctx.path, ".")
+}
+
+func (ctx *context) transform(v interface{}) interface{} {
+	if ctx.opts.Transform == nil {
+		return v
+	}
+	return ctx.opts.Transform(ctx.pathString(), v)
+}
+
+func (ctx *context) pushPath(elem string) {
+	ctx.path = append(ctx.path, elem)
+}
+
+func (ctx *context) popPath() {
+	ctx.path = ctx.path[:len(ctx.path)-1]
+}
+
+// lookupPath returns a value at the given path without wildcards.
+func lookupPath(v interface{}, path []string) (interface{}, bool) {
+	for _, elem := range path {
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = vv[elem]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(elem)
+			if err != nil || i < 0 || i >= len(vv) {
+				return nil, false
+			}
+			v = vv[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// rootPointer returns a JSON Pointer to the value at the given root path along
+// with the same path in the jd format, or empty ones if there is no such value,
+// see selectRoot.
+func rootPointer(v interface{}, root string) (string, []interface{}) {
+	path := splitPath(root)
+	if _, ok := lookupPath(v, path); !ok {
+		return "", nil
+	}
+	p, jd := "", make([]interface{}, 0, len(path))
+	for _, k := range path {
+		p = pointerChild(p, k)
+		if s, ok := v.([]interface{}); ok {
+			i, _ := strconv.Atoi(k)
+			jd, v = append(jd, i), s[i]
+		} else {
+			jd, v = append(jd, k), v.(map[string]interface{})[k]
+		}
+	}
+	return p, jd
+}
+
+// selectRoot returns a value at the given root path, or the whole document if
+// there is no such value.
+func selectRoot(v interface{}, root string) interface{} {
+	if rv, ok := lookupPath(v, splitPath(root)); ok {
+		return rv
+	}
+	return v
+}
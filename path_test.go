@@ -0,0 +1,118 @@
+package jsondiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    []string
+		match   bool
+	}{
+		{"", nil, true},
+		{"", []string{"a"}, false},
+		{"a", []string{"a"}, true},
+		{"a.b", []string{"a"}, false},
+		{"a.*.c", []string{"a", "0", "c"}, true},
+		{"a.*.c", []string{"a", "c"}, false},
+		{"**", nil, true},
+		{"**.id", []string{"id"}, true},
+		{"**.id", []string{"a", "3", "id"}, true},
+		{"a.**", []string{"a", "b", "c"}, true},
+		{"a.**.c", []string{"a", "b"}, false},
+	}
+	for _, c := range cases {
+		if matchPath(splitPath(c.pattern), c.path) != c.match {
+			t.Errorf("pattern %q, path %q: expected %v", c.pattern, c.path, c.match)
+		}
+	}
+}
+
+func TestOptionalKeys(t *testing.T) {
+	cases := []struct {
+		a      string
+		b      string
+		result Difference
+	}{
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, FullMatch},
+		{`{"a": 1, "b": 2}`, `{"a": 1}`, FullMatch},
+		{`{"a": 1, "b": 2}`, `{"a": 1, "b": 3}`, NoMatch},
+		{`{"a": 1}`, `{"a": 1, "c": 2}`, NoMatch},
+		{`{"items": [{"x": 1}]}`, `{"items": [{"x": 1, "etag": "1"}]}`, FullMatch},
+	}
+	opts := DefaultConsoleOptions()
+	opts.OptionalKeys = []string{"b", "**.etag"}
+	for i, c := range cases {
+		result, _ := Compare([]byte(c.a), []byte(c.b), &opts)
+		if result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s", i, result, c.result)
+		}
+	}
+}
+
+func TestRoots(t *testing.T) {
+	cases := []struct {
+		a          string
+		b          string
+		firstRoot  string
+		secondRoot string
+		result     Difference
+	}{
+		{`{"data": {"x": 1}}`, `{"x": 1}`, "data", "", FullMatch},
+		{`{"result": {"x": 1}}`, `{"data": {"x": 2}}`, "result", "data", NoMatch},
+		{`{"result": [{"x": 1}]}`, `{"data": {"x": 1}}`, "result.0", "data", FullMatch},
+		{`{"x": 1}`, `{"x": 1}`, "data", "data", FullMatch},
+	}
+	for i, c := range cases {
+		opts := DefaultConsoleOptions()
+		opts.FirstRoot = c.firstRoot
+		opts.SecondRoot = c.secondRoot
+		result, _ := Compare([]byte(c.a), []byte(c.b), &opts)
+		if result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s", i, result, c.result)
+		}
+	}
+}
+
+func TestRootsQuickFullMatch(t *testing.T) {
+	opts := Options{QuickFullMatch: true, FirstRoot: "result", SecondRoot: "data"}
+	result, _ := Compare([]byte(`{"result": 1, "data": 2}`), []byte(`{"result": 1, "data": 2}`), &opts)
+	if result != NoMatch {
+		t.Errorf("got %s, expected NoMatch", result)
+	}
+	opts = Options{QuickFullMatch: true, FirstRoot: "data", SecondRoot: "data"}
+	_, diff := Compare([]byte(`{"result": 1, "data": 2}`), []byte(`{"result": 1, "data": 2}`), &opts)
+	if diff != "2" {
+		t.Errorf("got %q, expected %q", diff, "2")
+	}
+}
+
+func TestTransform(t *testing.T) {
+	opts := DefaultConsoleOptions()
+	opts.Transform = func(path string, v interface{}) interface{} {
+		if s, ok := v.(string); ok && matchPath(splitPath("**.email"), splitPath(path)) {
+			return strings.ToLower(s)
+		}
+		if m, ok := v.(map[string]interface{}); ok && path == "" {
+			delete(m, "requestId")
+		}
+		return v
+	}
+	cases := []struct {
+		a      string
+		b      string
+		result Difference
+	}{
+		{`{"user": {"email": "Foo@Example.com"}, "requestId": 1}`, `{"user": {"email": "foo@example.com"}}`, FullMatch},
+		{`{"user": {"email": "foo@example.com"}}`, `{"user": {"email": "bar@example.com"}}`, NoMatch},
+		{`{"name": "Foo"}`, `{"name": "foo"}`, NoMatch},
+	}
+	for i, c := range cases {
+		result, _ := Compare([]byte(c.a), []byte(c.b), &opts)
+		if result != c.result {
+			t.Errorf("case %d failed, got: %s, expected: %s", i, result, c.result)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Placeholder is a string token which matches values of the first document
+// when it's used as a value in the second document, see Options.Placeholders.
+type Placeholder string
+
+// Presence is the string form of the Present placeholder.
+const Presence = "<<PRESENCE>>"
+
+const (
+	// Matches any value, hence it only checks presence of an object property
+	// or an array element.
+	Present Placeholder = Presence
+	// Match values of the corresponding JSON type.
+	TypeNull    Placeholder = "<<TYPE:null>>"
+	TypeBoolean Placeholder = "<<TYPE:boolean>>"
+	TypeNumber  Placeholder = "<<TYPE:number>>"
+	TypeString  Placeholder = "<<TYPE:string>>"
+	TypeArray   Placeholder = "<<TYPE:array>>"
+	TypeObject  Placeholder = "<<TYPE:object>>"
+)
+
+// Expect is an object of the expected (second) document built
+// programmatically. Its values may be placeholders, nested Expect objects and
+// any other values encodable as JSON, e.g.:
+//
+//	doc, err := jsondiff.Expect{"name": jsondiff.Present, "age": jsondiff.TypeNumber}.Marshal()
+//
+// Placeholders are encoded as the corresponding string tokens.
+type Expect map[string]interface{}
+
+// Marshal encodes the document as JSON. Unlike json.Marshal, it doesn't
+// escape the angle brackets of placeholders, so that the document stays
+// readable.
+func (e Expect) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(e); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// placeholderTypes maps placeholders to the type names of values they match,
+// see typeName. Present matches any type.
+var placeholderTypes = map[Placeholder]string{
+	Present:     "",
+	TypeNull:    "null",
+	TypeBoolean: "boolean",
+	TypeNumber:  "number",
+	TypeString:  "string",
+	TypeArray:   "array",
+	TypeObject:  "object",
+}
+
+// Match returns true if v, a value decoded from JSON, is matched by the
+// placeholder. Returns false for strings which aren't valid placeholders.
+// Placeholders are matchers, so CompareInterfaces recognizes them regardless
+// of Options.Placeholders.
+func (p Placeholder) Match(v interface{}) bool {
+	return p.matchesType(typeName(v))
+}
+
+// String returns the token of the placeholder.
+func (p Placeholder) String() string {
+	return string(p)
+}
+
+// matchesType works like Matches given the type name of the value.
+func (p Placeholder) matchesType(name string) bool {
+	t, ok := placeholderTypes[p]
+	return ok && (t == "" || t == name)
+}
+
+// placeholder returns the placeholder if b is a valid one and placeholders
+// are enabled.
+func (ctx *context) placeholder(b interface{}) (Placeholder, bool) {
+	if !ctx.opts.Placeholders {
+		return "", false
+	}
+	s, ok := b.(string)
+	if !ok {
+		return "", false
+	}
+	_, ok = placeholderTypes[Placeholder(s)]
+	return Placeholder(s), ok
+}
+
+// tokenTypeName returns the type name of the value starting with the token,
+// see typeName.
+func tokenTypeName(t json.Token) string {
+	switch t {
+	case json.Delim('['):
+		return "array"
+	case json.Delim('{'):
+		return "object"
+	}
+	return typeName(t)
+}
@@ -0,0 +1,51 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+func TestPlaceholders(t *testing.T) {
+	expected, err := Expect{
+		"id":   Present,
+		"name": TypeString,
+		"age":  TypeNumber,
+		"tags": TypeArray,
+		"meta": Expect{"owner": TypeObject},
+	}.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(expected) != `{"age":"<<TYPE:number>>","id":"<<PRESENCE>>","meta":{"owner":"<<TYPE:object>>"},"name":"<<TYPE:string>>","tags":"<<TYPE:array>>"}` {
+		t.Fatalf("unexpected encoding: %s", expected)
+	}
+
+	opts := DefaultConsoleOptions()
+	opts.Placeholders = true
+	cases := []struct {
+		a      string
+		result Difference
+	}{
+		{`{"id": 7, "name": "Joe", "age": 42, "tags": [], "meta": {"owner": {}}}`, FullMatch},
+		{`{"id": null, "name": "Joe", "age": 42, "tags": [1], "meta": {"owner": {"x": 1}}, "extra": 1}`, SupersetMatch},
+		{`{"name": "Joe", "age": 42, "tags": [], "meta": {"owner": {}}}`, NoMatch},
+		{`{"id": 7, "name": "Joe", "age": "42", "tags": [], "meta": {"owner": {}}}`, NoMatch},
+		{`{"id": 7, "name": "Joe", "age": 42, "tags": {}, "meta": {"owner": {}}}`, NoMatch},
+	}
+	for _, c := range cases {
+		if result, diff := Compare([]byte(c.a), expected, &opts); result != c.result {
+			t.Errorf("%s: got %s, expected %s:\n%s", c.a, result, c.result, diff)
+		}
+		if result := Verdict([]byte(c.a), expected, &opts); result != c.result {
+			t.Errorf("%s: got verdict %s, expected %s", c.a, result, c.result)
+		}
+	}
+
+	// placeholders are compared literally by default
+	opts.Placeholders = false
+	if result, _ := Compare([]byte(`{"id": 7}`), []byte(`{"id": "<<PRESENCE>>"}`), &opts); result != NoMatch {
+		t.Errorf("got %s, expected NoMatch", result)
+	}
+	if !TypeNull.Match(nil) || Placeholder("<<TYPE:date>>").Match("x") {
+		t.Error("unexpected placeholder matching")
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/nsf/jsondiff/protorules
+
+go 1.18
+
+require (
+	github.com/nsf/jsondiff v0.0.0
+	google.golang.org/protobuf v1.34.2
+)
+
+replace github.com/nsf/jsondiff => ../
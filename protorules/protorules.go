@@ -0,0 +1,158 @@
+// Package protorules derives jsondiff comparison rules from field options of
+// protobuf messages, so that the policy of comparing protojson payloads
+// lives in the .proto files, e.g.:
+//
+//	extend google.protobuf.FieldOptions {
+//		bool diff_ignore = 50001;
+//		double diff_epsilon = 50002;
+//	}
+//
+//	message Order {
+//		string id = 1;
+//		google.protobuf.Timestamp created_at = 2 [(diff_ignore) = true];
+//		double total = 3 [(diff_epsilon) = 0.01];
+//	}
+//
+// The options are passed as their generated extension types:
+//
+//	rules := protorules.Rules{Ignore: pb.E_DiffIgnore, Epsilon: pb.E_DiffEpsilon}
+//	opts := rules.Options((&pb.Order{}).ProtoReflect().Descriptor(), jsondiff.DefaultJSONOptions())
+package protorules
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/nsf/jsondiff"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Rules names the field options which mark fields of messages.
+type Rules struct {
+	// Extension of google.protobuf.FieldOptions of bool type, fields for
+	// which it's true are ignored, see jsondiff.Options.Ignore.
+	Ignore protoreflect.ExtensionType
+	// Extension of google.protobuf.FieldOptions of double type, numbers of
+	// fields for which it's set are equal if they are within the epsilon
+	// from each other. Applies to repeated fields and map values as well.
+	Epsilon protoreflect.ExtensionType
+	// When true, fields are named as in the .proto files, as protojson does
+	// with MarshalOptions.UseProtoNames. Fields are named by their JSON
+	// names otherwise.
+	UseProtoNames bool
+}
+
+// Options returns a copy of options with the rules of the fields of the
+// message added to Ignore and Comparators. Fields of nested messages are
+// included, except for well-known types, which have special JSON mappings,
+// and recursive messages, which are only followed once.
+func (r Rules) Options(md protoreflect.MessageDescriptor, opts jsondiff.Options) jsondiff.Options {
+	opts.Ignore = opts.Ignore[:len(opts.Ignore):len(opts.Ignore)]
+	opts.Comparators = opts.Comparators[:len(opts.Comparators):len(opts.Comparators)]
+	r.message(md, "", map[protoreflect.FullName]bool{}, &opts)
+	return opts
+}
+
+// RegisterPreset registers the "json" preset with the rules of the message
+// under the given name, see jsondiff.RegisterPreset.
+func (r Rules) RegisterPreset(name string, md protoreflect.MessageDescriptor) {
+	base, _ := jsondiff.PresetOptions("json")
+	jsondiff.RegisterPreset(name, r.Options(md, base))
+}
+
+func (r Rules) message(md protoreflect.MessageDescriptor, path string, visiting map[protoreflect.FullName]bool, opts *jsondiff.Options) {
+	if visiting[md.FullName()] || strings.HasPrefix(string(md.FullName()), "google.protobuf.") {
+		return
+	}
+	visiting[md.FullName()] = true
+	defer delete(visiting, md.FullName())
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		name := fd.JSONName()
+		if r.UseProtoNames {
+			name = string(fd.Name())
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		if r.ignored(fd) {
+			opts.Ignore = append(opts.Ignore, fieldPath)
+			continue
+		}
+		// elements of repeated fields and values of maps are one level
+		// deeper
+		valuePath, valueDesc := fieldPath, fd
+		if fd.IsList() {
+			valuePath += ".*"
+		} else if fd.IsMap() {
+			valuePath += ".*"
+			valueDesc = fd.MapValue()
+		}
+		if epsilon, ok := r.epsilon(fd); ok {
+			opts.Comparators = append(opts.Comparators, jsondiff.Comparator{
+				Paths: []string{valuePath},
+				Equal: withinEpsilon(epsilon),
+			})
+		}
+		if m := valueDesc.Message(); m != nil {
+			r.message(m, valuePath, visiting, opts)
+		}
+	}
+}
+
+func (r Rules) ignored(fd protoreflect.FieldDescriptor) bool {
+	if r.Ignore == nil {
+		return false
+	}
+	o := fd.Options()
+	if o == nil || !proto.HasExtension(o, r.Ignore) {
+		return false
+	}
+	v, _ := proto.GetExtension(o, r.Ignore).(bool)
+	return v
+}
+
+func (r Rules) epsilon(fd protoreflect.FieldDescriptor) (float64, bool) {
+	if r.Epsilon == nil {
+		return 0, false
+	}
+	o := fd.Options()
+	if o == nil || !proto.HasExtension(o, r.Epsilon) {
+		return 0, false
+	}
+	v, ok := proto.GetExtension(o, r.Epsilon).(float64)
+	return v, ok
+}
+
+// withinEpsilon returns a comparator of numbers, which protojson encodes as
+// JSON numbers or, for 64-bit integers and non-finite floats, as strings.
+func withinEpsilon(epsilon float64) func(a, b interface{}) bool {
+	return func(a, b interface{}) bool {
+		af, okA := number(a)
+		bf, okB := number(b)
+		if !okA || !okB {
+			return reflect.DeepEqual(a, b)
+		}
+		return af == bf || math.Abs(af-bf) <= epsilon
+	}
+}
+
+func number(v interface{}) (float64, bool) {
+	var n json.Number
+	switch v := v.(type) {
+	case json.Number:
+		n = v
+	case string:
+		n = json.Number(v)
+	default:
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
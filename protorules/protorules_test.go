@@ -0,0 +1,133 @@
+package protorules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nsf/jsondiff"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string, repeated bool, opts *descriptorpb.FieldOptions) *descriptorpb.FieldDescriptorProto {
+	label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	if repeated {
+		label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	}
+	fd := &descriptorpb.FieldDescriptorProto{
+		Name:    proto.String(name),
+		Number:  proto.Int32(number),
+		Type:    typ.Enum(),
+		Label:   label.Enum(),
+		Options: opts,
+	}
+	if typeName != "" {
+		fd.TypeName = proto.String(typeName)
+	}
+	return fd
+}
+
+// testMessage builds the Order message marked with dynamic extensions.
+func testMessage(t *testing.T) (protoreflect.MessageDescriptor, Rules) {
+	ext := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("ext.proto"),
+		Package:    proto.String("test"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Extension: []*descriptorpb.FieldDescriptorProto{
+			field("diff_ignore", 50001, descriptorpb.FieldDescriptorProto_TYPE_BOOL, "", false, nil),
+			field("diff_epsilon", 50002, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, "", false, nil),
+		},
+	}
+	for _, x := range ext.Extension {
+		x.Extendee = proto.String(".google.protobuf.FieldOptions")
+	}
+	extFile, err := protodesc.NewFile(ext, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := Rules{
+		Ignore:  dynamicpb.NewExtensionType(extFile.Extensions().Get(0)),
+		Epsilon: dynamicpb.NewExtensionType(extFile.Extensions().Get(1)),
+	}
+	ignore := &descriptorpb.FieldOptions{}
+	proto.SetExtension(ignore, rules.Ignore, true)
+	epsilon := &descriptorpb.FieldOptions{}
+	proto.SetExtension(epsilon, rules.Epsilon, 0.01)
+
+	order := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("order.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"ext.proto", "google/protobuf/timestamp.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Order"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", false, nil),
+				field("created_at", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp", false, ignore),
+				field("total", 3, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, "", false, epsilon),
+				field("lines", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".test.Line", true, nil),
+				field("parent", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".test.Order", false, nil),
+			},
+		}, {
+			Name: proto.String("Line"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("sku", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", false, ignore),
+				field("unit_price", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64, "", false, epsilon),
+			},
+		}},
+	}
+	files := new(protoregistry.Files)
+	for _, f := range []protoreflect.FileDescriptor{extFile} {
+		if err := files.RegisterFile(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"google/protobuf/descriptor.proto", "google/protobuf/timestamp.proto"} {
+		f, err := protoregistry.GlobalFiles.FindFileByPath(name)
+		if err != nil {
+			t.Skip(err)
+		}
+		files.RegisterFile(f)
+	}
+	orderFile, err := protodesc.NewFile(order, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return orderFile.Messages().ByName("Order"), rules
+}
+
+func TestRules(t *testing.T) {
+	md, rules := testMessage(t)
+	opts := rules.Options(md, jsondiff.DefaultJSONOptions())
+	if expected := []string{"createdAt", "lines.*.sku"}; !reflect.DeepEqual(opts.Ignore, expected) {
+		t.Errorf("got ignored %v, expected %v", opts.Ignore, expected)
+	}
+	var paths []string
+	for _, c := range opts.Comparators {
+		paths = append(paths, c.Paths...)
+	}
+	if expected := []string{"total", "lines.*.unitPrice"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("got comparators of %v, expected %v", paths, expected)
+	}
+
+	a := []byte(`{"id": "1", "createdAt": "2024-01-01T00:00:00Z", "total": 10.001, "lines": [{"sku": "a", "unitPrice": "100"}]}`)
+	b := []byte(`{"id": "1", "createdAt": "2024-01-02T00:00:00Z", "total": 10, "lines": [{"sku": "b", "unitPrice": "100"}]}`)
+	if diff, s := jsondiff.Compare(a, b, &opts); diff != jsondiff.FullMatch {
+		t.Errorf("got %s, expected FullMatch:\n%s", diff, s)
+	}
+	b = []byte(`{"id": "1", "total": 10.1, "lines": [{"unitPrice": "101"}]}`)
+	if diff, _ := jsondiff.Compare(a, b, &opts); diff != jsondiff.NoMatch {
+		t.Errorf("got %s, expected NoMatch", diff)
+	}
+
+	rules.UseProtoNames = true
+	rules.RegisterPreset("order", md)
+	preset, ok := jsondiff.PresetOptions("order")
+	if !ok || !reflect.DeepEqual(preset.Ignore, []string{"created_at", "lines.*.sku"}) {
+		t.Errorf("got preset ignoring %v, expected proto names", preset.Ignore)
+	}
+}
@@ -0,0 +1,69 @@
+package jsondiff
+
+import (
+	"bytes"
+)
+
+// ReasonKind describes why a value downgraded the verdict of the comparison.
+type ReasonKind int
+
+const (
+	// Values are present in both documents, but differ. Causes NoMatch.
+	ValueChanged ReasonKind = iota
+	// Value is present only in the second document. Causes NoMatch.
+	ValueAdded
+	// Value is present only in the first document. Causes SupersetMatch.
+	ValueRemoved
+	// Collection in the first document is not empty, while an empty one is
+	// expected, see EmptyCollectionMatchesEmpty. Causes NoMatch.
+	CollectionNotEmpty
+)
+
+func (k ReasonKind) String() string {
+	switch k {
+	case ValueChanged:
+		return "ValueChanged"
+	case ValueAdded:
+		return "ValueAdded"
+	case ValueRemoved:
+		return "ValueRemoved"
+	case CollectionNotEmpty:
+		return "CollectionNotEmpty"
+	}
+	return "Invalid"
+}
+
+// Difference returns the verdict caused by the reason.
+func (k ReasonKind) Difference() Difference {
+	if k == ValueRemoved {
+		return SupersetMatch
+	}
+	return NoMatch
+}
+
+// Reason describes a single value which downgraded the verdict of the
+// comparison. Path uses the same dot separated syntax as path patterns, root
+// value has an empty path.
+type Reason struct {
+	Path string
+	Kind ReasonKind
+}
+
+func (ctx *context) downgrade(kind ReasonKind) {
+	ctx.result(kind.Difference())
+	if ctx.collectReasons {
+		ctx.reasons = append(ctx.reasons, Reason{
+			Path: ctx.pathString(),
+			Kind: kind,
+		})
+	}
+}
+
+// CompareWithReasons works like Compare, but also returns a list of reasons
+// explaining which values caused SupersetMatch or NoMatch verdict. Reasons are
+// collected regardless of the rendering options, e.g. SkipMatches.
+func CompareWithReasons(a, b []byte, opts *Options) (Difference, string, []Reason) {
+	ctx := context{opts: opts, collectReasons: true}
+	diff, s := ctx.compareStreams(bytes.NewReader(a), bytes.NewReader(b))
+	return diff, s, ctx.reasons
+}
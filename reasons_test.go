@@ -0,0 +1,32 @@
+package jsondiff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareWithReasons(t *testing.T) {
+	opts := DefaultConsoleOptions()
+	opts.SkipMatches = true
+	result, _, reasons := CompareWithReasons(
+		[]byte(`{"a": [1, 2, 3], "b": "foo", "c": {"d": 1}}`),
+		[]byte(`{"a": [1, 5], "b": "foo", "c": {"d": 1, "e": 2}}`),
+		&opts,
+	)
+	expected := []Reason{
+		{"a.1", ValueChanged},
+		{"a.2", ValueRemoved},
+		{"c.e", ValueAdded},
+	}
+	if result != NoMatch {
+		t.Errorf("got %s, expected NoMatch", result)
+	}
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("got %v, expected %v", reasons, expected)
+	}
+
+	result, _, reasons = CompareWithReasons([]byte(`{"a": 1}`), []byte(`{"a": 1}`), &opts)
+	if result != FullMatch || len(reasons) != 0 {
+		t.Errorf("got %s %v, expected FullMatch without reasons", result, reasons)
+	}
+}
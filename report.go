@@ -0,0 +1,101 @@
+package jsondiff
+
+import (
+	"bytes"
+	"time"
+)
+
+// Report is the result of CompareReport, which combines the results of the
+// other comparison functions.
+type Report struct {
+	// Verdict of the comparison.
+	Difference Difference
+	// Rendering of the difference in Options.Format, or the message of an
+	// invalid JSON verdict.
+	Diff string
+	// Renderings of the difference in the additional formats requested from
+	// CompareReport, nil for invalid JSON verdicts.
+	Renderings map[OutputFormat]string
+	// Changes between the documents, see CompareWithChanges.
+	Changes []Change
+	// Reasons of the verdict, see CompareWithReasons.
+	Reasons []Reason
+	// Numbers of the changed, added, removed and skipped values.
+	Stats ReportStats
+	// Time spent in the stages of the comparison.
+	Timing ReportTiming
+	// Errors of the documents for invalid JSON verdicts, see InputErrors.
+	FirstError  *InputError
+	SecondError *InputError
+	// Conditions which make the verdict less conclusive than it looks, e.g.
+	// sampled arrays.
+	Warnings []string
+}
+
+// ReportStats counts values by their comparison results, see Report.
+type ReportStats struct {
+	Changed int
+	Added   int
+	Removed int
+	// Values skipped by Options.Skip and the options based on it, including
+	// elements of sampled arrays.
+	Skipped int
+}
+
+// ReportTiming is the time spent in the stages of the comparison, see
+// Report. Render includes the additional formats.
+type ReportTiming struct {
+	Decode  time.Duration
+	Compare time.Duration
+	Render  time.Duration
+}
+
+// CompareReport compares two JSON documents like Compare and returns
+// everything known about the comparison at once: the verdict, its reasons,
+// structured changes, renderings in Options.Format and in the given
+// additional formats, statistics and timing. Documents are always compared
+// value by value, regardless of QuickFullMatch.
+func CompareReport(a, b []byte, opts *Options, formats ...OutputFormat) *Report {
+	ctx := context{opts: opts, collectReasons: true}
+	r := &Report{}
+	start := time.Now()
+	av, bv, diff, msg := ctx.decodeBoth(bytes.NewReader(a), bytes.NewReader(b))
+	r.Timing.Decode = time.Since(start)
+	if diff.IsError() {
+		r.Difference, r.Diff = diff, msg
+		r.FirstError, r.SecondError = InputErrors(a, b, opts)
+		return r
+	}
+
+	start = time.Now()
+	d := ctx.compareRoots(av, bv)
+	r.Timing.Compare = time.Since(start)
+	r.Difference = ctx.diff
+	r.Reasons = ctx.reasons
+	r.Changes = ctx.changes(d, ctx.root, nil)
+	var counts deltaCounts
+	counts.count(d)
+	r.Stats = ReportStats{Changed: counts.changed, Added: counts.added, Removed: counts.removed, Skipped: counts.skipped}
+
+	start = time.Now()
+	r.Diff = ctx.render(d)
+	if len(formats) != 0 {
+		r.Renderings = make(map[OutputFormat]string, len(formats))
+		o := *opts
+		ctx.opts = &o
+		for _, f := range formats {
+			o.Format = f
+			r.Renderings[f] = ctx.render(d)
+		}
+		ctx.opts = opts
+	}
+	r.Timing.Render = time.Since(start)
+
+	if ctx.sampled {
+		r.Warnings = append(r.Warnings, "arrays were sampled, the verdict is approximate")
+	}
+	if d.differs && r.Difference == FullMatch {
+		r.Warnings = append(r.Warnings, "differences were tolerated, e.g. missing optional keys")
+	}
+	return r
+}
@@ -0,0 +1,38 @@
+package jsondiff
+
+import "testing"
+
+func TestCompareReport(t *testing.T) {
+	opts := Options{ChangedSeparator: " => ", Indent: "  ", OptionalKeys: []string{"etag"}, Skip: func(path string) SkipResult {
+		if path == "at" {
+			return SkipSilently
+		}
+		return DontSkip
+	}}
+	a, b := []byte(`{"a": 1, "b": [1, 2], "at": 1}`), []byte(`{"a": 2, "b": [1], "c": true, "at": 2}`)
+	r := CompareReport(a, b, &opts, JSONPatchOutput)
+	if r.Difference != NoMatch {
+		t.Errorf("got %s, expected NoMatch", r.Difference)
+	}
+	if _, expected := Compare(a, b, &opts); r.Diff != expected {
+		t.Errorf("got diff:\n%s\nexpected:\n%s", r.Diff, expected)
+	}
+	patchOpts := opts
+	patchOpts.Format = JSONPatchOutput
+	if _, expected := Compare(a, b, &patchOpts); r.Renderings[JSONPatchOutput] != expected {
+		t.Errorf("got patch:\n%s\nexpected:\n%s", r.Renderings[JSONPatchOutput], expected)
+	}
+	if len(r.Changes) != 3 || len(r.Reasons) != 3 || r.Stats != (ReportStats{Changed: 1, Added: 1, Removed: 1, Skipped: 1}) || len(r.Warnings) != 0 {
+		t.Errorf("got %+v", r)
+	}
+
+	r = CompareReport([]byte(`{"a": 1}`), []byte(`{"a": 1, "etag": "x"}`), &opts)
+	if r.Difference != FullMatch || len(r.Changes) != 1 || len(r.Warnings) != 1 {
+		t.Errorf("got %+v", r)
+	}
+
+	r = CompareReport([]byte(`{"a": 1}`), []byte(`{"a": `), &opts)
+	if r.Difference != SecondArgIsInvalidJson || r.FirstError != nil || r.SecondError == nil || r.Changes != nil {
+		t.Errorf("got %+v", r)
+	}
+}
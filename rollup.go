@@ -0,0 +1,36 @@
+package jsondiff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RollupSummary describes the changes of a collection summarized in the text
+// output, e.g. "37 changed, 2 added", see Options.RollupDepth. Kinds of
+// changes which didn't happen are omitted.
+func RollupSummary(changed, added, removed int) string {
+	var parts []string
+	for _, c := range [...]struct {
+		n    int
+		verb string
+	}{{changed, "changed"}, {added, "added"}, {removed, "removed"}} {
+		if c.n != 0 {
+			parts = append(parts, strconv.Itoa(c.n)+" "+c.verb)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printRollup writes the counts of the changes of the collection instead of
+// its contents.
+func (ctx *context) printRollup(d *delta) {
+	var counts deltaCounts
+	counts.count(d)
+	summary := ctx.opts.RollupSummary
+	if summary == nil {
+		summary = RollupSummary
+	}
+	ctx.w.Tag(ChangedTag)
+	ctx.w.Text(summary(counts.changed, counts.added, counts.removed))
+	ctx.writeAnnotations(d.a, d.b)
+}
@@ -0,0 +1,63 @@
+package jsondiff
+
+import "testing"
+
+func TestRollupDepth(t *testing.T) {
+	a := `{"id": 1, "items": [{"n": 1}, {"n": 2}, 3], "meta": {"a": {"b": 1}, "c": 1}, "tags": ["x"]}`
+	b := `{"id": 2, "items": [{"n": 1}, {"n": 5}, 4, 5, 6], "meta": {"a": {"b": 2}}, "tags": ["x"]}`
+	cases := []struct {
+		depth    int
+		summary  func(changed, added, removed int) string
+		expected string
+	}{
+		{1, nil, `{
+    "id": (1 => 2),
+    "items": (2 changed, 2 added),
+    "meta": (1 changed, 1 removed),
+    "tags": [
+        "x"
+    ]
+}`},
+		{2, func(changed, added, removed int) string { return "..." }, `{
+    "id": (1 => 2),
+    "items": [
+        {
+            "n": 1
+        },
+        (...),
+        (3 => 4),
+        +5+,
+        +6+
+    ],
+    "meta": {
+        "a": (...),
+        -"c": 1-
+    },
+    "tags": [
+        "x"
+    ]
+}`},
+	}
+	for _, c := range cases {
+		opts := Options{
+			Added:            Tag{Begin: "+", End: "+"},
+			Removed:          Tag{Begin: "-", End: "-"},
+			Changed:          Tag{Begin: "(", End: ")"},
+			ChangedSeparator: " => ",
+			Indent:           "    ",
+			RollupDepth:      c.depth,
+			RollupSummary:    c.summary,
+		}
+		result, diff := Compare([]byte(a), []byte(b), &opts)
+		if result != NoMatch || diff != c.expected {
+			t.Errorf("depth %d: got %s:\n%s\nexpected:\n%s", c.depth, result, diff, c.expected)
+		}
+	}
+
+	// the structured result keeps the full detail
+	opts := Options{RollupDepth: 1}
+	_, node := CompareTree([]byte(a), []byte(b), &opts)
+	if paths := node.UnmatchedPaths(); len(paths) != 7 {
+		t.Errorf("got unmatched paths %v", paths)
+	}
+}
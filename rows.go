@@ -0,0 +1,218 @@
+package jsondiff
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// RowsOptions configures CompareRows.
+type RowsOptions struct {
+	// Options of the comparison of rows.
+	Options *Options
+	// Names of the columns identifying rows, e.g. the primary key columns.
+	KeyColumns []string
+	// Names of the columns holding JSON documents, e.g. of json or jsonb
+	// type. Values of other columns are compared as JSON scalars.
+	JSONColumns []string
+	// Names of the compared columns, all columns except the key columns
+	// are compared if empty.
+	Columns []string
+}
+
+// RowDiff is the comparison result of a pair of rows, see CompareRows.
+type RowDiff struct {
+	// Values of the key columns, the value itself if there is a single key
+	// column, otherwise a JSON array of the values.
+	Key string
+	// Verdict of the rows, a row present only in the first result set is a
+	// SupersetMatch, a row present only in the second one is a NoMatch.
+	Difference Difference
+	// Rendering of the difference of the rows, a row present in one result
+	// set only is rendered as removed or added.
+	Diff string
+}
+
+// RowsReport is the result of CompareRows.
+type RowsReport struct {
+	// Verdict of the whole comparison.
+	Difference Difference
+	// Comparison results of the rows in the order of the first result set,
+	// followed by the rows present only in the second result set.
+	Rows []RowDiff
+}
+
+// CompareRows compares two result sets row by row, e.g. of a table before
+// and after a data migration. Rows are paired by the values of the key
+// columns and every row is converted to an object of the compared columns,
+// e.g.:
+//
+//	{"payload": {"items": [1, 2]}, "status": "done"}
+//
+// which is compared according to RowsOptions.Options, so path patterns are
+// relative to the row object, e.g. "payload.items". Values of the JSON
+// columns are decoded, NULL is null, byte slices of other columns are
+// strings and times are RFC 3339 strings. Both result sets are read until
+// the end. Returns an error if reading fails, any of the columns is missing,
+// a value of a JSON column is invalid or a key is duplicate.
+func CompareRows(a, b *sql.Rows, opts *RowsOptions) (*RowsReport, error) {
+	if len(opts.KeyColumns) == 0 {
+		return nil, errors.New("jsondiff: no key columns")
+	}
+	ctx := context{opts: opts.Options}
+	aKeys, aRows, err := ctx.readRows(a, opts)
+	if err != nil {
+		return nil, err
+	}
+	bKeys, bRows, err := ctx.readRows(b, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RowsReport{}
+	add := func(k string, a interface{}, aOK bool, b interface{}, bOK bool) {
+		r := RowDiff{Key: k}
+		r.Difference, r.Diff = compareItems(a, aOK, b, bOK, opts.Options)
+		report.Rows = append(report.Rows, r)
+		report.Difference = combineDifferences(report.Difference, r.Difference)
+	}
+	for _, k := range aKeys {
+		bv, ok := bRows[k]
+		add(k, aRows[k], true, bv, ok)
+	}
+	for _, k := range bKeys {
+		if _, ok := aRows[k]; !ok {
+			add(k, nil, false, bRows[k], true)
+		}
+	}
+	return report, nil
+}
+
+// readRows reads the rows as objects indexed by their keys, keys are returned
+// in the order of the result set.
+func (ctx *context) readRows(rows *sql.Rows, opts *RowsOptions) ([]string, map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	index := make(map[string]int, len(columns))
+	for i, c := range columns {
+		index[c] = i
+	}
+	keyColumns := make([]int, len(opts.KeyColumns))
+	for i, c := range opts.KeyColumns {
+		if keyColumns[i], err = columnIndex(index, c); err != nil {
+			return nil, nil, err
+		}
+	}
+	compared := opts.Columns
+	if len(compared) == 0 {
+		for _, c := range columns {
+			if !containsString(opts.KeyColumns, c) {
+				compared = append(compared, c)
+			}
+		}
+	}
+	for _, c := range compared {
+		if _, err := columnIndex(index, c); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var keys []string
+	m := make(map[string]interface{})
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, err
+		}
+		key := make([]interface{}, len(keyColumns))
+		for i, c := range keyColumns {
+			key[i] = columnValue(values[c])
+		}
+		k, err := rowKey(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, dup := m[k]; dup {
+			return nil, nil, errors.New("jsondiff: duplicate row key " + k)
+		}
+		row := make(map[string]interface{}, len(compared))
+		for _, c := range compared {
+			v := values[index[c]]
+			if v == nil || !containsString(opts.JSONColumns, c) {
+				row[c] = columnValue(v)
+				continue
+			}
+			var data []byte
+			switch v := v.(type) {
+			case []byte:
+				data = v
+			case string:
+				data = []byte(v)
+			default:
+				return nil, nil, errors.New("jsondiff: column " + c + " doesn't hold json")
+			}
+			if row[c], err = ctx.decode(bytes.NewReader(data)); err != nil {
+				return nil, nil, errors.New("jsondiff: column " + c + " of row " + k + ": " + err.Error())
+			}
+		}
+		keys = append(keys, k)
+		m[k] = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return keys, m, nil
+}
+
+func columnIndex(index map[string]int, column string) (int, error) {
+	i, ok := index[column]
+	if !ok {
+		return 0, errors.New("jsondiff: no column " + strconv.Quote(column))
+	}
+	return i, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// columnValue converts a value scanned by database/sql to a decoded JSON
+// value.
+func columnValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case float64:
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+// rowKey returns the key of a row from the values of its key columns.
+func rowKey(key []interface{}) (string, error) {
+	if len(key) == 1 {
+		if k, ok := keyString(key[0]); ok {
+			return k, nil
+		}
+	}
+	data, err := json.Marshal(key)
+	return string(data), err
+}
@@ -0,0 +1,126 @@
+package jsondiff
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// testTables is a driver serving rows of tables named by the queries, rows
+// are given as column names followed by "|"-separated values of every row,
+// "NULL" is nil.
+type testTables map[string][]string
+
+func (t testTables) Open(name string) (driver.Conn, error) { return testConn(t), nil }
+
+type testConn testTables
+
+func (c testConn) Prepare(query string) (driver.Stmt, error) {
+	table, ok := c[query]
+	if !ok {
+		return nil, errors.New("no table " + query)
+	}
+	return testStmt(table), nil
+}
+func (c testConn) Close() error              { return nil }
+func (c testConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type testStmt []string
+
+func (s testStmt) Close() error  { return nil }
+func (s testStmt) NumInput() int { return 0 }
+func (s testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &testRows{columns: strings.Split(s[0], "|"), rows: s[1:]}, nil
+}
+
+type testRows struct {
+	columns []string
+	rows    []string
+}
+
+func (r *testRows) Columns() []string { return r.columns }
+func (r *testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	for i, v := range strings.Split(r.rows[0], "|") {
+		if v == "NULL" {
+			dest[i] = nil
+		} else {
+			dest[i] = []byte(v)
+		}
+	}
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("jsondiff-test", testTables{
+		"before": {"id|payload|status", `1|{"a": 1}|done`, `2|{"a": 2}|new`, `3|[]|NULL`},
+		"after":  {"id|payload|status", `2|{"a": 2, "b": 3}|new`, `1|{"a": 1}|done`, `4|{}|new`},
+		"dup":    {"id|payload|status", `1|{}|done`, `1|{}|done`},
+		"bad":    {"id|payload|status", `1|{|done`},
+	})
+}
+
+func TestCompareRows(t *testing.T) {
+	db, err := sql.Open("jsondiff-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	compare := func(a, b string, opts *RowsOptions) (*RowsReport, error) {
+		ra, err := db.Query(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ra.Close()
+		rb, err := db.Query(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rb.Close()
+		return CompareRows(ra, rb, opts)
+	}
+
+	opts := Options{Added: Tag{Begin: "+"}, Removed: Tag{Begin: "-"}, Changed: Tag{Begin: "~"}, ChangedSeparator: " => "}
+	report, err := compare("before", "after", &RowsOptions{Options: &opts, KeyColumns: []string{"id"}, JSONColumns: []string{"payload"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range report.Rows {
+		got = append(got, r.Key+":"+r.Difference.String())
+	}
+	expected := "1:FullMatch 2:NoMatch 3:SupersetMatch 4:NoMatch"
+	if strings.Join(got, " ") != expected || report.Difference != NoMatch {
+		t.Errorf("got %v, %s, expected %s, NoMatch", got, report.Difference, expected)
+	}
+	if d := report.Rows[1].Diff; !strings.Contains(d, `+"b": 3`) {
+		t.Errorf("got diff %q, expected an added property", d)
+	}
+
+	report, err = compare("before", "after", &RowsOptions{Options: &opts, KeyColumns: []string{"id", "status"}, Columns: []string{"status"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows[0].Key != `["1","done"]` {
+		t.Errorf("got key %s, expected a composite key", report.Rows[0].Key)
+	}
+
+	for _, c := range []struct{ a, b string }{{"dup", "after"}, {"before", "bad"}} {
+		if _, err := compare(c.a, c.b, &RowsOptions{Options: &opts, KeyColumns: []string{"id"}, JSONColumns: []string{"payload"}}); err == nil {
+			t.Errorf("expected an error comparing %s and %s", c.a, c.b)
+		}
+	}
+	if _, err := compare("before", "after", &RowsOptions{Options: &opts, KeyColumns: []string{"pk"}}); err == nil {
+		t.Errorf("expected an error for a missing key column")
+	}
+}
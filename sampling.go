@@ -0,0 +1,75 @@
+package jsondiff
+
+import (
+	"bytes"
+	"math/rand"
+)
+
+// ArraySampling configures comparison of a subset of elements of large
+// arrays, see Options.ArraySampling.
+type ArraySampling struct {
+	// Arrays with more elements than MinLength in any of the documents are
+	// sampled. Sampling is disabled when it's zero.
+	MinLength int
+	// Number of leading and trailing elements which are always compared.
+	Head int
+	Tail int
+	// Number of elements picked at random between the head and the tail.
+	Random int
+	// Seed of the random choice. Arrays of the same length have the same
+	// elements picked with the same seed, so results are reproducible.
+	Seed int64
+}
+
+func (s *ArraySampling) enabled() bool {
+	return s.MinLength > 0
+}
+
+// sample returns which elements of arrays of the given lengths are compared,
+// or nil if all of them are. When lengths differ, the first element missing
+// in the shorter array is always compared, so that the difference in length
+// is reported.
+func (s *ArraySampling) sample(lenA, lenB int) []bool {
+	n, min := lenA, lenB
+	if n < min {
+		n, min = min, n
+	}
+	if !s.enabled() || n <= s.MinLength || s.Head+s.Tail+s.Random >= n {
+		return nil
+	}
+	picked := make([]bool, n)
+	for i := 0; i < s.Head; i++ {
+		picked[i] = true
+	}
+	for i := n - s.Tail; i < n; i++ {
+		picked[i] = true
+	}
+	if min < n {
+		picked[min] = true
+	}
+
+	// Floyd's algorithm picks distinct elements of the middle part without
+	// enumerating all of them
+	first, middle := s.Head, n-s.Head-s.Tail
+	rnd := rand.New(rand.NewSource(s.Seed))
+	chosen := make(map[int]bool, s.Random)
+	for j := middle - s.Random; j < middle; j++ {
+		k := rnd.Intn(j + 1)
+		if chosen[k] {
+			k = j
+		}
+		chosen[k] = true
+		picked[first+k] = true
+	}
+	return picked
+}
+
+// CompareWithSampling works like Compare, but also tells whether the result
+// is approximate, i.e. any array was sampled according to
+// Options.ArraySampling. Approximate FullMatch and SupersetMatch verdicts may
+// be wrong, while NoMatch is always accurate.
+func CompareWithSampling(a, b []byte, opts *Options) (Difference, string, bool) {
+	ctx := context{opts: opts}
+	diff, s := ctx.compareStreams(bytes.NewReader(a), bytes.NewReader(b))
+	return diff, s, ctx.sampled
+}
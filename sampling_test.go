@@ -0,0 +1,98 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func sequence(n int) []interface{} {
+	s := make([]interface{}, n)
+	for i := range s {
+		s[i] = i
+	}
+	return s
+}
+
+func TestArraySampling(t *testing.T) {
+	s := ArraySampling{MinLength: 10, Head: 2, Tail: 3, Random: 4, Seed: 7}
+	picked := s.sample(100, 100)
+	n := 0
+	for i, p := range picked {
+		if p {
+			n++
+		}
+		if (i < 2 || i >= 97) && !p {
+			t.Errorf("element %d is not sampled", i)
+		}
+	}
+	if n != 9 {
+		t.Errorf("got %d sampled elements, expected 9", n)
+	}
+	if again := s.sample(100, 100); !equalSamples(picked, again) {
+		t.Errorf("samples differ with the same seed")
+	}
+	if s.sample(10, 10) != nil {
+		t.Errorf("short arrays are sampled")
+	}
+	if s := (ArraySampling{MinLength: 1, Head: 5, Tail: 5}); s.sample(10, 10) != nil {
+		t.Errorf("arrays are sampled entirely")
+	}
+	if picked := s.sample(100, 50); !picked[50] {
+		t.Errorf("first missing element is not sampled")
+	}
+}
+
+func equalSamples(a, b []bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCompareWithSampling(t *testing.T) {
+	a := sequence(1000)
+	b := sequence(1000)
+	b[999] = -1
+	ab, _ := json.Marshal(map[string]interface{}{"items": a, "short": []int{1, 2}})
+	bb, _ := json.Marshal(map[string]interface{}{"items": b, "short": []int{1, 2}})
+
+	opts := Options{Format: DocumentOutput, DocumentHeader: true, SkipMatches: true}
+	opts.ArraySampling = ArraySampling{MinLength: 100, Head: 10, Tail: 10, Random: 10, Seed: 1}
+	result, diff, approximate := CompareWithSampling(ab, bb, &opts)
+	if result != NoMatch || !approximate || !strings.Contains(diff, `"approximate":true`) {
+		t.Errorf("got %s, approximate: %v:\n%s", result, approximate, diff)
+	}
+	if result := Verdict(ab, bb, &opts); result != NoMatch {
+		t.Errorf("got verdict %s, expected NoMatch", result)
+	}
+
+	// differences outside of the sample are not reported
+	b[999] = 999
+	for i, p := range opts.ArraySampling.sample(1000, 1000) {
+		if !p {
+			b[i] = -1
+			break
+		}
+	}
+	bb, _ = json.Marshal(map[string]interface{}{"items": b, "short": []int{1, 2}})
+	if result, _, approximate := CompareWithSampling(ab, bb, &opts); result != FullMatch || !approximate {
+		t.Errorf("got %s, approximate: %v, expected approximate FullMatch", result, approximate)
+	}
+
+	// length differences are always reported
+	bb, _ = json.Marshal(map[string]interface{}{"items": sequence(2000), "short": []int{1, 2}})
+	if result, _, _ := CompareWithSampling(ab, bb, &opts); result != NoMatch {
+		t.Errorf("got %s, expected NoMatch", result)
+	}
+
+	result, _, approximate = CompareWithSampling([]byte(`[1, 2]`), []byte(`[1, 2]`), &opts)
+	if result != FullMatch || approximate {
+		t.Errorf("got %s, approximate: %v, expected exact FullMatch", result, approximate)
+	}
+}
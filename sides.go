@@ -0,0 +1,133 @@
+package jsondiff
+
+import (
+	"bytes"
+)
+
+// sideValue returns the value of the delta in one of the documents, false if
+// it's missing there.
+func (d *delta) sideValue(left bool) (interface{}, bool) {
+	switch d.kind {
+	case deltaAdded:
+		return d.b, !left
+	case deltaRemoved:
+		return d.a, left
+	case deltaSkipped:
+		if left {
+			return d.a, !d.aMissing
+		}
+		if d.bMissing {
+			return nil, false
+		}
+		return d.b, true
+	}
+	if left {
+		return d.a, true
+	}
+	return d.b, true
+}
+
+func sideTag(d *delta) TagKind {
+	if d.kind == deltaAdded {
+		return AddedTag
+	}
+	return RemovedTag
+}
+
+// printSide prints one of the compared documents in full, annotated with
+// changes relevant to it: changes and removals for the left (first) document,
+// changes and additions for the right (second) one.
+func (ctx *context) printSide(d *delta, left bool) {
+	v, _ := d.sideValue(left)
+	switch d.kind {
+	case deltaChanged:
+		ctx.w.Tag(ChangedTag)
+		ctx.writeValue(v, true)
+	case deltaAdded, deltaRemoved:
+		ctx.w.Tag(sideTag(d))
+		ctx.writeValue(v, true)
+	case deltaSkipped:
+		if d.placeholder != "" {
+			ctx.w.Tag(SkippedTag)
+			ctx.w.Text(d.placeholder)
+		} else {
+			ctx.w.Tag(NormalTag)
+			ctx.writeValue(v, true)
+		}
+	case deltaCollection:
+		ctx.printSideCollection(d, left)
+	default:
+		ctx.w.Tag(NormalTag)
+		ctx.writeValue(v, true)
+	}
+}
+
+func (ctx *context) printSideCollection(d *delta, left bool) {
+	open, close := "[", "]"
+	if d.isObject() {
+		open, close = "{", "}"
+	}
+	visible := make([]int, 0, len(d.elems))
+	for i, e := range d.elems {
+		if _, ok := e.sideValue(left); ok {
+			visible = append(visible, i)
+		}
+	}
+
+	if d.isObject() {
+		ctx.pushKeyWidth(d.keys, func(i int) bool {
+			_, ok := d.elems[i].sideValue(left)
+			return ok
+		})
+		defer ctx.popKeyWidth()
+	}
+
+	ctx.w.Tag(NormalTag)
+	if len(visible) == 0 {
+		ctx.w.Text(open)
+	} else {
+		ctx.level++
+		ctx.newline(open)
+	}
+	for n, i := range visible {
+		e := d.elems[i]
+		if e.kind == deltaAdded || e.kind == deltaRemoved {
+			// key is a part of the tagged text, like in the regular output
+			ctx.w.Tag(sideTag(e))
+		}
+		ctx.elemKey(d, i)
+		ctx.printSide(e, left)
+		ctx.w.Tag(NormalTag)
+		if n != len(visible)-1 {
+			ctx.newline(",")
+		} else {
+			ctx.level--
+			ctx.newline("")
+		}
+	}
+	ctx.w.Text(close)
+	v, _ := d.sideValue(left)
+	ctx.writeTypeMaybe(v)
+}
+
+// CompareSides compares two JSON documents like Compare, but instead of a
+// single combined rendering returns two: the first document annotated with
+// changed and removed values and the second document annotated with changed
+// and added values. It is meant for user interfaces showing the documents
+// side by side. Both documents are always rendered in full using the text
+// format, so SkipMatches, ChangedLayout and Format options are ignored.
+func CompareSides(a, b []byte, opts *Options) (Difference, string, string) {
+	ctx := context{opts: opts}
+	d, diff, msg := ctx.decodeAndCompare(bytes.NewReader(a), bytes.NewReader(b))
+	if d == nil {
+		return diff, msg, msg
+	}
+
+	left := newTextWriter(opts)
+	ctx.w = left
+	ctx.printSide(d, true)
+	right := newTextWriter(opts)
+	ctx.w = right
+	ctx.printSide(d, false)
+	return ctx.diff, left.String(), right.String()
+}
@@ -0,0 +1,53 @@
+package jsondiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareSides(t *testing.T) {
+	opts := Options{
+		Added:   Tag{Begin: "(A:", End: ":A)"},
+		Removed: Tag{Begin: "(R:", End: ":R)"},
+		Changed: Tag{Begin: "(C:", End: ":C)"},
+		Indent:  "  ",
+	}
+	result, left, right := CompareSides(
+		[]byte(`{"a": [1, 2, 3], "b": "foo", "c": true}`),
+		[]byte(`{"a": [1, 5], "b": "foo", "d": {}}`),
+		&opts,
+	)
+	expectedLeft := strings.TrimSpace(`
+{
+  "a": [
+    1,
+    (C:2:C),
+    (R:3:R)
+  ],
+  "b": "foo",
+  (R:"c": true:R)
+}`)
+	expectedRight := strings.TrimSpace(`
+{
+  "a": [
+    1,
+    (C:5:C)
+  ],
+  "b": "foo",
+  (A:"d": {}:A)
+}`)
+	if result != NoMatch {
+		t.Errorf("got %s, expected NoMatch", result)
+	}
+	if left != expectedLeft {
+		t.Errorf("left, got:\n%s\nexpected:\n%s", left, expectedLeft)
+	}
+	if right != expectedRight {
+		t.Errorf("right, got:\n%s\nexpected:\n%s", right, expectedRight)
+	}
+
+	result, left, right = CompareSides([]byte(`{`), []byte(`{}`), &opts)
+	if result != FirstArgIsInvalidJson || left != right {
+		t.Errorf("got %s: %q, %q", result, left, right)
+	}
+}
@@ -0,0 +1,103 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// SkipResult tells whether and how a value is skipped, see Options.Skip.
+type SkipResult struct {
+	skip        bool
+	placeholder string
+}
+
+var (
+	// Value is compared as usual.
+	DontSkip = SkipResult{}
+	// Value is not compared and it's rendered as if it was matching.
+	SkipSilently = SkipResult{skip: true}
+)
+
+// SkipButPrintPlaceholder returns a SkipResult which tells that the value is
+// not compared and it's rendered as a given placeholder text, so that the
+// output explicitly shows which values were skipped. JSON based output formats
+// render the placeholder as a string, jd output ignores skipped values.
+func SkipButPrintPlaceholder(placeholder string) SkipResult {
+	return SkipResult{skip: true, placeholder: placeholder}
+}
+
+func (ctx *context) skip(a interface{}, aOK bool, b interface{}, bOK bool) SkipResult {
+	if ctx.pathMatches(ctx.opts.Ignore) {
+		return SkipSilently
+	}
+	if len(ctx.opts.IgnoreValuesMatching) != 0 &&
+		(!aOK || ctx.valueIgnored(a)) && (!bOK || ctx.valueIgnored(b)) {
+		return SkipSilently
+	}
+	if ctx.opts.Skip == nil {
+		return DontSkip
+	}
+	return ctx.opts.Skip(ctx.pathString())
+}
+
+// valueIgnored tells whether the leaf value matches any of the expressions in
+// Options.IgnoreValuesMatching.
+func (ctx *context) valueIgnored(v interface{}) bool {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		s = "null"
+	case bool:
+		s = strconv.FormatBool(v)
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		return false
+	}
+	for _, re := range ctx.opts.IgnoreValuesMatching {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// omittedPaths appends paths of the values which are not printed because of
+// SkipMatches.
+func omittedPaths(d *delta, path string, out []string) []string {
+	if !d.output {
+		return append(out, path)
+	}
+	if d.kind != deltaCollection {
+		return out
+	}
+	for i, e := range d.elems {
+		if d.isObject() {
+			out = omittedPaths(e, childPath(path, d.keys[i]), out)
+		} else {
+			out = omittedPaths(e, childPath(path, strconv.Itoa(i)), out)
+		}
+	}
+	return out
+}
+
+// CompareWithSkipped works like Compare, but also returns paths of the values
+// omitted from the text output because of SkipMatches, in the order of the
+// output. Paths use the syntax of path patterns, root value has an empty
+// path. Returns nil paths when SkipMatches is false.
+func CompareWithSkipped(a, b []byte, opts *Options) (Difference, string, []string) {
+	ctx := context{opts: opts}
+	d, diff, msg := ctx.decodeAndCompare(bytes.NewReader(a), bytes.NewReader(b))
+	if d == nil {
+		return diff, msg, nil
+	}
+	s := ctx.render(d)
+	if !opts.SkipMatches {
+		return ctx.diff, s, nil
+	}
+	ctx.markOutput(d)
+	return ctx.diff, s, omittedPaths(d, "", nil)
+}
@@ -0,0 +1,137 @@
+package jsondiff
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSkip(t *testing.T) {
+	opts := Options{
+		Added:            Tag{Begin: "(A:", End: ":A)"},
+		Removed:          Tag{Begin: "(R:", End: ":R)"},
+		Changed:          Tag{Begin: "(C:", End: ":C)"},
+		Skipped:          Tag{Begin: "(S:", End: ":S)"},
+		Indent:           "  ",
+		ChangedSeparator: " => ",
+		Skip: func(path string) SkipResult {
+			switch path {
+			case "id":
+				return SkipButPrintPlaceholder("<ignored>")
+			case "tags.1", "time":
+				return SkipSilently
+			}
+			return DontSkip
+		},
+	}
+	a := `{"id": 1, "name": "foo", "tags": ["a", "b"], "time": 5}`
+	b := `{"id": 2, "name": "bar", "tags": ["a", "c"]}`
+	result, diff := Compare([]byte(a), []byte(b), &opts)
+	expected := strings.TrimSpace(`
+{
+  "id": (S:<ignored>:S),
+  "name": (C:"foo" => "bar":C),
+  "tags": [
+    "a",
+    "b"
+  ],
+  "time": 5
+}`)
+	if result != NoMatch || diff != expected {
+		t.Errorf("got %s:\n%s\nexpected NoMatch:\n%s", result, diff, expected)
+	}
+
+	opts.SkipMatches = true
+	result, diff = Compare([]byte(a), []byte(strings.Replace(b, "bar", "foo", 1)), &opts)
+	expected = strings.TrimSpace(`
+{
+  "id": (S:<ignored>:S)
+}`)
+	if result != FullMatch || diff != expected {
+		t.Errorf("got %s:\n%s\nexpected FullMatch:\n%s", result, diff, expected)
+	}
+}
+
+func TestIgnoreValuesMatching(t *testing.T) {
+	opts := Options{
+		Added:   Tag{Begin: "(A:", End: ":A)"},
+		Removed: Tag{Begin: "(R:", End: ":R)"},
+		Changed: Tag{Begin: "(C:", End: ":C)"},
+		IgnoreValuesMatching: []*regexp.Regexp{
+			regexp.MustCompile(`^\d{4}-\d\d-\d\dT`),
+		},
+	}
+	a := `{"at": "2020-01-01T00:00:00Z", "log": [{"at": "2020-01-02T00:00:00Z"}], "x": 1}`
+	b := `{"at": "2021-05-01T10:00:00Z", "log": [{"at": "2021-05-02T00:00:00Z"}], "x": 1, "seen": "2021-05-03T00:00:00Z"}`
+	if result, diff := Compare([]byte(a), []byte(b), &opts); result != FullMatch {
+		t.Errorf("got %s, expected FullMatch:\n%s", result, diff)
+	}
+
+	b = `{"at": null, "log": [{"at": "2021-05-02T00:00:00Z"}], "x": 1}`
+	if result, diff := Compare([]byte(a), []byte(b), &opts); result != NoMatch {
+		t.Errorf("got %s, expected NoMatch when only one value matches:\n%s", result, diff)
+	}
+}
+
+func TestSkippedPlacement(t *testing.T) {
+	opts := Options{
+		Indent:                "  ",
+		ChangedSeparator:      " => ",
+		SkipMatches:           true,
+		SkippedArrayElement:   SkippedArrayElement,
+		SkippedObjectProperty: SkippedObjectProperty,
+	}
+	a := []byte(`[1, 2, 3, 4, 5]`)
+	b := []byte(`[1, 0, 3, 4, 0]`)
+	cases := []struct {
+		placement SkippedPlacement
+		expected  string
+	}{
+		{SkippedInPlace, "[\n  ...skipped 1 array element...,\n  2 => 0,\n  ...skipped 2 array elements...,\n  5 => 0\n]"},
+		{SkippedBefore, "[\n  ...skipped 3 array elements...,\n  2 => 0,\n  5 => 0\n]"},
+		{SkippedAfter, "[\n  2 => 0,\n  5 => 0,\n  ...skipped 3 array elements...\n]"},
+		{SkippedAround, "[\n  ...skipped 1 array element...,\n  2 => 0,\n  5 => 0,\n  ...skipped 2 array elements...\n]"},
+	}
+	for _, c := range cases {
+		opts.SkippedPlacement = c.placement
+		_, diff := Compare(a, b, &opts)
+		if diff != c.expected {
+			t.Errorf("%d: got:\n%s\nexpected:\n%s", c.placement, diff, c.expected)
+		}
+	}
+
+	opts.SeparateSkipped = true
+	separate := []struct {
+		placement SkippedPlacement
+		expected  string
+	}{
+		{SkippedInPlace, "[\n  ...skipped 1 array element...,\n  2 => 0,\n  ...skipped 1 array element...,\n  ...skipped 1 array element...,\n  5 => 0\n]"},
+		{SkippedAfter, "[\n  2 => 0,\n  5 => 0,\n  ...skipped 1 array element...,\n  ...skipped 1 array element...,\n  ...skipped 1 array element...\n]"},
+	}
+	for _, c := range separate {
+		opts.SkippedPlacement = c.placement
+		_, diff := Compare(a, b, &opts)
+		if diff != c.expected {
+			t.Errorf("%d separate: got:\n%s\nexpected:\n%s", c.placement, diff, c.expected)
+		}
+	}
+}
+
+func TestCompareWithSkipped(t *testing.T) {
+	opts := Options{SkipMatches: true}
+	_, _, paths := CompareWithSkipped(
+		[]byte(`{"a": [1, 2], "b": {"c": 1}, "d": 1}`),
+		[]byte(`{"a": [1, 3], "b": {"c": 1}, "d": 2}`),
+		&opts,
+	)
+	if strings.Join(paths, " ") != "a.0 b" {
+		t.Errorf("got %q", paths)
+	}
+	if _, _, paths := CompareWithSkipped([]byte(`1`), []byte(`1`), &opts); len(paths) != 1 || paths[0] != "" {
+		t.Errorf("got %q for matching documents", paths)
+	}
+	opts.SkipMatches = false
+	if _, _, paths := CompareWithSkipped([]byte(`[1]`), []byte(`[2]`), &opts); paths != nil {
+		t.Errorf("got %q without SkipMatches", paths)
+	}
+}
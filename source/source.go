@@ -0,0 +1,239 @@
+// Package source reads JSON documents from local files and remote locations
+// given as URIs, so that stored snapshots can be compared without
+// downloading them first:
+//
+//	data, err := source.ReadAll(ctx, "s3://snapshots/orders/42.json")
+//
+// Locations are fetched by the fetcher registered for the URI scheme.
+// Built-in fetchers are:
+//
+//	path, file:///path                local files
+//	http://..., https://...           GET requests
+//	s3://bucket/key                   objects of public S3 buckets
+//	gs://bucket/object                objects of public Cloud Storage buckets
+//	redis://[:password@]host[:port]/key[?db=n]
+//	rediss://...                      values of string keys, rediss uses TLS
+//
+// The object store fetchers use anonymous HTTPS requests. Fetchers using
+// credentials, e.g. based on the vendor SDKs, can replace them with
+// Register.
+package source
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Fetcher opens the location given as a parsed URI.
+type Fetcher func(ctx context.Context, u *url.URL) (io.ReadCloser, error)
+
+var fetchers = struct {
+	sync.RWMutex
+	m map[string]Fetcher
+}{m: map[string]Fetcher{
+	"file":   fetchFile,
+	"http":   fetchHTTP,
+	"https":  fetchHTTP,
+	"s3":     fetchObject(s3URL),
+	"gs":     fetchObject(gsURL),
+	"redis":  fetchRedis,
+	"rediss": fetchRedis,
+}}
+
+// Register registers the fetcher of the URI scheme. Registering an existing
+// scheme replaces the fetcher.
+func Register(scheme string, f Fetcher) {
+	fetchers.Lock()
+	fetchers.m[strings.ToLower(scheme)] = f
+	fetchers.Unlock()
+}
+
+// Open opens the location, which is either a URI with a registered scheme or
+// a path of a local file. Returns an error if the scheme is not registered.
+func Open(ctx context.Context, location string) (io.ReadCloser, error) {
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 {
+		// not a URI, single letter schemes are Windows drives
+		return os.Open(location)
+	}
+	fetchers.RLock()
+	f, ok := fetchers.m[strings.ToLower(u.Scheme)]
+	fetchers.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("jsondiff: unsupported source scheme %q", u.Scheme)
+	}
+	return f(ctx, u)
+}
+
+// ReadAll reads the whole contents of the location, see Open.
+func ReadAll(ctx context.Context, location string) ([]byte, error) {
+	r, err := Open(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func fetchFile(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, fmt.Errorf("jsondiff: remote file host %q", u.Host)
+	}
+	return os.Open(u.Path)
+}
+
+func fetchHTTP(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("jsondiff: fetching %s: %s", u.Redacted(), resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// fetchObject returns a fetcher of objects of a store, which are available
+// at the HTTPS URLs returned by objectURL.
+func fetchObject(objectURL func(u *url.URL) *url.URL) Fetcher {
+	return func(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+		if u.Host == "" || strings.TrimPrefix(u.Path, "/") == "" {
+			return nil, fmt.Errorf("jsondiff: %s uri must include a bucket and an object", u.Scheme)
+		}
+		return fetchHTTP(ctx, objectURL(u))
+	}
+}
+
+func s3URL(u *url.URL) *url.URL {
+	return &url.URL{
+		Scheme: "https",
+		Host:   u.Host + ".s3.amazonaws.com",
+		Path:   u.Path,
+	}
+}
+
+func gsURL(u *url.URL) *url.URL {
+	return &url.URL{
+		Scheme: "https",
+		Host:   "storage.googleapis.com",
+		Path:   "/" + u.Host + u.Path,
+	}
+}
+
+// fetchRedis returns the value of a string key, the connection is closed
+// after the value is read.
+func fetchRedis(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+	key := strings.TrimPrefix(u.Path, "/")
+	if key == "" {
+		return nil, errors.New("jsondiff: redis uri must include a key")
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if u.Scheme == "rediss" {
+		tc := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		defer tc.Close()
+		conn = tc
+	}
+
+	c := redisConn{w: bufio.NewWriter(conn), r: bufio.NewReader(conn)}
+	if password, ok := u.User.Password(); ok {
+		args := []string{"AUTH", password}
+		if name := u.User.Username(); name != "" {
+			args = []string{"AUTH", name, password}
+		}
+		if _, err := c.do(args...); err != nil {
+			return nil, err
+		}
+	}
+	if db := u.Query().Get("db"); db != "" {
+		if _, err := strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("jsondiff: invalid redis db %q", db)
+		}
+		if _, err := c.do("SELECT", db); err != nil {
+			return nil, err
+		}
+	}
+	v, err := c.do("GET", key)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("jsondiff: redis key %q doesn't exist", key)
+	}
+	return io.NopCloser(strings.NewReader(*v)), nil
+}
+
+// redisConn speaks just enough of the Redis protocol to send commands and
+// read simple string, error and bulk string replies.
+type redisConn struct {
+	w *bufio.Writer
+	r *bufio.Reader
+}
+
+// do sends the command and returns its reply, nil for a null bulk string.
+func (c *redisConn) do(args ...string) (*string, error) {
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("jsondiff: invalid redis reply")
+	}
+	switch line[0] {
+	case '+':
+		s := line[1:]
+		return &s, nil
+	case '-':
+		return nil, errors.New("jsondiff: redis: " + line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errors.New("jsondiff: invalid redis reply")
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		s := string(buf[:n])
+		return &s, nil
+	}
+	return nil, fmt.Errorf("jsondiff: unexpected redis reply %q", line)
+}
@@ -0,0 +1,124 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadAll(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.json")
+	if err := os.WriteFile(path, []byte(`{"a": 1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/a.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"b": 2}`))
+	}))
+	defer srv.Close()
+	Register("test", func(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(u.Opaque)), nil
+	})
+
+	cases := []struct {
+		location string
+		expected string
+	}{
+		{path, `{"a": 1}`},
+		{"file://" + filepath.ToSlash(path), `{"a": 1}`},
+		{srv.URL + "/a.json", `{"b": 2}`},
+		{"TEST:[3]", `[3]`},
+	}
+	for i, c := range cases {
+		got, err := ReadAll(context.Background(), c.location)
+		if err != nil || string(got) != c.expected {
+			t.Errorf("case %d failed, got: %s, %v, expected: %s", i, got, err, c.expected)
+		}
+	}
+
+	for _, location := range []string{srv.URL + "/b.json", "ftp://host/a.json", "s3://bucket", filepath.Join(dir, "b.json")} {
+		if _, err := ReadAll(context.Background(), location); err == nil {
+			t.Errorf("expected an error for %s", location)
+		}
+	}
+}
+
+func TestObjectURLs(t *testing.T) {
+	u, _ := url.Parse("s3://bucket/dir/a.json")
+	if got := s3URL(u).String(); got != "https://bucket.s3.amazonaws.com/dir/a.json" {
+		t.Errorf("got %s, expected an s3 url", got)
+	}
+	u, _ = url.Parse("gs://bucket/dir/a.json")
+	if got := gsURL(u).String(); got != "https://storage.googleapis.com/bucket/dir/a.json" {
+		t.Errorf("got %s, expected a cloud storage url", got)
+	}
+}
+
+// serveRedis serves a single connection, replying to commands with the
+// replies in order and recording the commands.
+func serveRedis(t *testing.T, replies ...string) (addr string, commands <-chan []string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan []string, len(replies))
+	go func() {
+		defer l.Close()
+		defer close(ch)
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for _, reply := range replies {
+			var n int
+			line, _ := r.ReadString('\n')
+			if _, err := fmt.Sscanf(line, "*%d", &n); err != nil {
+				return
+			}
+			args := make([]string, n)
+			for i := range args {
+				r.ReadString('\n')
+				arg, _ := r.ReadString('\n')
+				args[i] = strings.TrimSuffix(arg, "\r\n")
+			}
+			ch <- args
+			io.WriteString(conn, reply)
+		}
+	}()
+	return l.Addr().String(), ch
+}
+
+func TestRedis(t *testing.T) {
+	addr, commands := serveRedis(t, "+OK\r\n", "+OK\r\n", "$8\r\n{\"a\": 1}\r\n")
+	got, err := ReadAll(context.Background(), "redis://:secret@"+addr+"/snapshots:1?db=2")
+	if err != nil || string(got) != `{"a": 1}` {
+		t.Errorf("got %s, %v, expected the value", got, err)
+	}
+	var cmds []string
+	for c := range commands {
+		cmds = append(cmds, strings.Join(c, " "))
+	}
+	if strings.Join(cmds, ", ") != "AUTH secret, SELECT 2, GET snapshots:1" {
+		t.Errorf("got commands %v", cmds)
+	}
+
+	addr, _ = serveRedis(t, "$-1\r\n")
+	if _, err := ReadAll(context.Background(), "redis://"+addr+"/missing"); err == nil {
+		t.Errorf("expected an error for a missing key")
+	}
+}
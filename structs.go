@@ -0,0 +1,192 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// structRules are the comparison rules read from the jsondiff struct tags,
+// see CompareStruct.
+type structRules struct {
+	ignore      []string
+	optional    []string
+	comparators []Comparator
+	seen        map[string]bool
+	visiting    map[reflect.Type]bool
+}
+
+// CompareStruct compares two Go values like CompareInterfaces, with
+// comparison rules of struct fields read from their jsondiff tags, so that
+// the policy lives next to the type definition:
+//
+//	type Order struct {
+//		ID        string    `json:"id"`
+//		CreatedAt time.Time `json:"createdAt" jsondiff:"ignore"`
+//		Total     float64   `json:"total" jsondiff:"epsilon=0.01"`
+//		Note      string    `json:"note,omitempty" jsondiff:"optional"`
+//	}
+//
+// The tag is a comma separated list of rules:
+//
+//   - "ignore" skips the field silently, see Options.Ignore.
+//   - "optional" allows the field to be missing, see Options.OptionalKeys.
+//   - "epsilon=x" treats numbers within x from each other as equal. For
+//     slices, arrays and maps it applies to their elements.
+//
+// Fields are named as encoding/json names them, rules of nested structs,
+// including elements of slices and maps, apply to all of their occurrences,
+// e.g. "lines.*.price". Rules of both values' types are combined and added
+// to copies of Ignore, OptionalKeys and Comparators of the options, which
+// take precedence, and structs are encoded as with MarshalUnknownValues.
+// Panics if a tag is invalid.
+func CompareStruct(a, b interface{}, opts *Options) (Difference, string) {
+	r := structRules{seen: make(map[string]bool), visiting: make(map[reflect.Type]bool)}
+	for _, v := range []interface{}{a, b} {
+		if v != nil {
+			r.typ(reflect.TypeOf(v), "")
+		}
+	}
+	o := *opts
+	o.MarshalUnknownValues = true
+	o.Ignore = append(o.Ignore[:len(o.Ignore):len(o.Ignore)], r.ignore...)
+	o.OptionalKeys = append(o.OptionalKeys[:len(o.OptionalKeys):len(o.OptionalKeys)], r.optional...)
+	o.Comparators = append(o.Comparators[:len(o.Comparators):len(o.Comparators)], r.comparators...)
+	return CompareInterfaces(a, b, &o)
+}
+
+// elemPath returns the type of values nested in the collection type along
+// with their path pattern, the type itself if it's not a collection.
+func elemPath(t reflect.Type, path string) (reflect.Type, string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoded as base64 strings
+			return t, path
+		}
+		return t.Elem(), childPath(path, "*")
+	case reflect.Map:
+		return t.Elem(), childPath(path, "*")
+	}
+	return t, path
+}
+
+// typ reads the rules of the fields of the struct type, or of the structs
+// nested in the collection type, at the path.
+func (r *structRules) typ(t reflect.Type, path string) {
+	for {
+		et, ep := elemPath(t, path)
+		if et == t && ep == path {
+			break
+		}
+		t, path = et, ep
+	}
+	if t.Kind() != reflect.Struct || r.visiting[t] || t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return
+	}
+	r.visiting[t] = true
+	defer delete(r.visiting, t)
+	r.fields(t, path)
+}
+
+func (r *structRules) fields(t reflect.Type, path string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, tagged := jsonFieldName(f)
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && !tagged && ft.Kind() == reflect.Struct {
+			// fields of embedded structs are promoted, those of a struct
+			// embedding itself are shadowed by its own fields
+			if !r.visiting[ft] {
+				r.visiting[ft] = true
+				r.fields(ft, path)
+				delete(r.visiting, ft)
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		fieldPath := childPath(path, name)
+		r.rules(f, fieldPath)
+		r.typ(f.Type, fieldPath)
+	}
+}
+
+// jsonFieldName returns the name of the field in JSON, "-" if it's omitted,
+// and whether the name comes from the json tag.
+func jsonFieldName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "-", true
+	}
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "" {
+		return f.Name, false
+	}
+	return tag, true
+}
+
+func (r *structRules) rules(f reflect.StructField, path string) {
+	tag, ok := f.Tag.Lookup("jsondiff")
+	if !ok {
+		return
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		if rule == "" {
+			continue
+		}
+		name, arg := rule, ""
+		if i := strings.IndexByte(rule, '='); i >= 0 {
+			name, arg = rule[:i], rule[i+1:]
+		}
+		key := name + " " + path
+		switch name {
+		case "ignore":
+			if !r.seen[key] {
+				r.ignore = append(r.ignore, path)
+			}
+		case "optional":
+			if !r.seen[key] {
+				r.optional = append(r.optional, path)
+			}
+		case "epsilon":
+			epsilon, err := strconv.ParseFloat(arg, 64)
+			if err != nil || epsilon < 0 {
+				panic("jsondiff: invalid epsilon of field " + f.Name + ": " + arg)
+			}
+			key += " " + arg
+			if !r.seen[key] {
+				// numbers are either the field itself or its elements
+				_, numbers := elemPath(f.Type, path)
+				compare := epsilonComparator(epsilon)
+				r.comparators = append(r.comparators, Comparator{
+					Paths: []string{numbers},
+					Equal: func(a, b interface{}) bool {
+						an, okA := a.(json.Number)
+						bn, okB := b.(json.Number)
+						if !okA || !okB {
+							return reflect.DeepEqual(a, b)
+						}
+						return compare(an, bn)
+					},
+				})
+			}
+		default:
+			panic("jsondiff: invalid rule of field " + f.Name + ": " + rule)
+		}
+		r.seen[key] = true
+	}
+}
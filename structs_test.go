@@ -0,0 +1,93 @@
+package jsondiff
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type structTestLine struct {
+	SKU    string    `json:"sku" jsondiff:"ignore"`
+	Prices []float64 `json:"prices" jsondiff:"epsilon=0.01"`
+}
+
+type structTestBase struct {
+	ID string `json:"id"`
+}
+
+type structTestOrder struct {
+	structTestBase
+	CreatedAt time.Time         `json:"createdAt" jsondiff:"ignore"`
+	Total     float64           `json:"total" jsondiff:"epsilon=0.01"`
+	Note      string            `json:"note,omitempty" jsondiff:"optional"`
+	Lines     []structTestLine  `json:"lines"`
+	Parent    *structTestOrder  `json:"parent,omitempty"`
+	Internal  string            `json:"-" jsondiff:"ignore"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// structTestNode embeds itself, its embedded fields are shadowed in JSON.
+type structTestNode struct {
+	*structTestNode
+	X int `jsondiff:"epsilon=1"`
+}
+
+func TestCompareStruct(t *testing.T) {
+	a := structTestOrder{
+		structTestBase: structTestBase{"1"},
+		CreatedAt:      time.Unix(0, 0),
+		Total:          10.001,
+		Note:           "gift",
+		Lines:          []structTestLine{{"a", []float64{1.001, 2}}},
+	}
+	b := a
+	b.CreatedAt = time.Unix(1, 0)
+	b.Total = 10
+	b.Note = ""
+	b.Lines = []structTestLine{{"b", []float64{1, 2.001}}}
+	opts := Options{Added: Tag{Begin: "+"}, Removed: Tag{Begin: "-"}, Changed: Tag{Begin: "~"}, ChangedSeparator: " => "}
+	if diff, s := CompareStruct(a, b, &opts); diff != FullMatch {
+		t.Errorf("got %s, expected FullMatch:\n%s", diff, s)
+	}
+
+	b.Total = 10.1
+	if diff, _ := CompareStruct(a, b, &opts); diff != NoMatch {
+		t.Errorf("got %s, expected NoMatch", diff)
+	}
+
+	r := structRules{seen: make(map[string]bool), visiting: make(map[reflect.Type]bool)}
+	r.typ(reflect.TypeOf(&a), "")
+	if expected := []string{"createdAt", "lines.*.sku"}; !reflect.DeepEqual(r.ignore, expected) {
+		t.Errorf("got ignored %v, expected %v", r.ignore, expected)
+	}
+	var paths []string
+	for _, c := range r.comparators {
+		paths = append(paths, c.Paths...)
+	}
+	if expected := []string{"total", "lines.*.prices.*"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("got comparators of %v, expected %v", paths, expected)
+	}
+	if len(opts.Ignore) != 0 || len(opts.Comparators) != 0 {
+		t.Errorf("options were modified")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for an invalid tag")
+		}
+	}()
+	CompareStruct(struct {
+		A int `jsondiff:"epsilon=x"`
+	}{}, nil, &opts)
+}
+
+func TestCompareStructSelfEmbedding(t *testing.T) {
+	opts := Options{}
+	a := structTestNode{&structTestNode{X: 5}, 1}
+	if diff, s := CompareStruct(a, structTestNode{X: 2}, &opts); diff != FullMatch {
+		t.Errorf("got %s, expected FullMatch:\n%s", diff, s)
+	}
+	if diff, _ := CompareStruct(a, structTestNode{X: 3}, &opts); diff != NoMatch {
+		t.Errorf("got %s, expected NoMatch", diff)
+	}
+}
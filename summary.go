@@ -0,0 +1,78 @@
+package jsondiff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// summaryPath converts a JSON Pointer to the dot separated path syntax.
+func summaryPath(pointer string) string {
+	tokens, _ := splitPointer(pointer)
+	return strings.Join(tokens, ".")
+}
+
+// summaryParent returns the parent path of the change and whether it's an
+// array element.
+func summaryParent(c *Change) (string, bool) {
+	i := strings.LastIndexByte(c.Path, '/')
+	if i < 0 {
+		return "", false
+	}
+	last := c.Path[i+1:]
+	_, err := strconv.Atoi(last)
+	return c.Path[:i], err == nil && (last == "0" || last[0] != '0')
+}
+
+// Summarize describes changes in plain English for people who don't read JSON
+// diffs, e.g.:
+//
+//	`spec.replicas` changed from 3 to 5; `metadata.labels.team` added
+//
+// Paths use the dot separated syntax. Consecutive added or removed properties
+// of the same object and elements of the same array are grouped together, e.g.
+// "2 elements removed from `items`". Unchanged values are ignored.
+func Summarize(changes []Change) string {
+	return SummarizeWith(changes, EnglishLabels)
+}
+
+// SummarizeWith works like Summarize, but uses the given wording of sentences.
+func SummarizeWith(changes []Change, labels Labels) string {
+	var sentences []string
+	for i := 0; i < len(changes); {
+		c := &changes[i]
+		if c.Kind == ChangeUnchanged {
+			i++
+			continue
+		}
+
+		// group of consecutive siblings added or removed together
+		parent, element := summaryParent(c)
+		n := 1
+		for i+n < len(changes) && changes[i+n].Kind == c.Kind && c.Kind != ChangeModified {
+			p, e := summaryParent(&changes[i+n])
+			if p != parent || e != element || changes[i+n].Path == "" {
+				break
+			}
+			n++
+		}
+
+		item := SummaryItem{Kind: c.Kind, Path: summaryPath(c.Path), Count: 1, Old: c.Old, New: c.New}
+		if n > 1 {
+			item.Path = summaryPath(parent)
+			item.Count = n
+			item.Element = element
+			if !element {
+				item.Keys = make([]string, n)
+				for j := range item.Keys {
+					item.Keys[j] = summaryPath(changes[i+j].Path[len(parent):])
+				}
+			}
+		}
+		sentences = append(sentences, labels.Summary(item))
+		i += n
+	}
+	if len(sentences) == 0 {
+		return labels.NoChanges()
+	}
+	return strings.Join(sentences, "; ")
+}
@@ -0,0 +1,30 @@
+package jsondiff
+
+import (
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	opts := DefaultJSONOptions()
+	cases := []struct {
+		a        string
+		b        string
+		expected string
+	}{
+		{
+			`{"spec": {"replicas": 3}, "metadata": {"labels": {}}}`,
+			`{"spec": {"replicas": 5}, "metadata": {"labels": {"team": "x"}}}`,
+			"`metadata.labels.team` added; `spec.replicas` changed from 3 to 5",
+		},
+		{`{"items": [1, 2, 3]}`, `{"items": [1]}`, "2 elements removed from `items`"},
+		{`{"a": 1, "b": 2, "c": {}}`, `{"c": []}`, "2 properties removed from the document: `a`, `b`; `c` changed from an object to an array"},
+		{`"x"`, `null`, `the document changed from "x" to null`},
+		{`{"a": 1}`, `{"a": 1}`, "no changes"},
+	}
+	for _, c := range cases {
+		_, _, changes := CompareWithChanges([]byte(c.a), []byte(c.b), &opts)
+		if got := Summarize(changes); got != c.expected {
+			t.Errorf("%s %s: got %q, expected %q", c.a, c.b, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package jsondiff
+
+import (
+	"io"
+	"text/template"
+)
+
+// TemplateChange is a change passed to templates, see RenderTemplate.
+type TemplateChange struct {
+	Change
+	// Lowercase name of the kind: "added", "removed", "modified" or
+	// "unchanged".
+	Op string
+	// Old and New values encoded as JSON, empty for values missing in the
+	// corresponding documents.
+	OldJSON string
+	NewJSON string
+}
+
+// TemplateData is the data passed to templates, see RenderTemplate.
+type TemplateData struct {
+	Changes []TemplateChange
+	// Number of changes of each kind.
+	Added     int
+	Removed   int
+	Modified  int
+	Unchanged int
+}
+
+// RenderTemplate executes the template with data describing the changes, so
+// that reports in custom formats can be produced, see TemplateData. E.g.:
+//
+//	{{range .Changes}}{{.Path}}: {{.Op}} {{.OldJSON}} {{.NewJSON}}
+//	{{end}}{{.Modified}} modified
+//
+// The changes are usually produced by CompareWithChanges.
+func RenderTemplate(changes []Change, tmpl *template.Template, w io.Writer) error {
+	data := TemplateData{Changes: make([]TemplateChange, len(changes))}
+	for i, c := range changes {
+		ov, nv := exportValues(&c)
+		data.Changes[i] = TemplateChange{Change: c, Op: c.Kind.name(), OldJSON: ov, NewJSON: nv}
+		switch c.Kind {
+		case ChangeAdded:
+			data.Added++
+		case ChangeRemoved:
+			data.Removed++
+		case ChangeModified:
+			data.Modified++
+		case ChangeUnchanged:
+			data.Unchanged++
+		}
+	}
+	return tmpl.Execute(w, data)
+}
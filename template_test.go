@@ -0,0 +1,24 @@
+package jsondiff
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("report").Parse(
+		"{{range .Changes}}{{.Path}} {{.Op}}{{if .OldJSON}} from {{.OldJSON}}{{end}}{{if .NewJSON}} to {{.NewJSON}}{{end}}\n" +
+			"{{end}}{{.Added}}/{{.Removed}}/{{.Modified}}"))
+	var buf bytes.Buffer
+	if err := RenderTemplate(exportChanges, tmpl, &buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := "/a modified from 1 to \"x,y\"\n" +
+		"/b added to null\n" +
+		"/c removed from {\"d\":true}\n" +
+		"1/1/1"
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
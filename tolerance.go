@@ -0,0 +1,105 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+)
+
+// Tolerance is a rule of differences which are accepted by the verdict, e.g.
+// of snapshots compared by monitoring pipelines, where some drift is
+// expected:
+//
+//	jsondiff.Tolerance{
+//		Paths:                []string{"results"},
+//		MaxDifferingElements: 0.05,
+//	}
+//
+// Tolerated differences are still rendered, like missing OptionalKeys, but
+// the verdict is FullMatch if there are no other differences.
+type Tolerance struct {
+	// Path patterns of the arrays and numbers the rule applies to, see
+	// Compare.
+	Paths []string
+	// Fraction of elements of the arrays which may differ, e.g. 0.05 for up
+	// to 5%. Elements present in only one of the arrays count as differing,
+	// the fraction is of the length of the longer array. Skipped elements,
+	// e.g. the ones ArraySampling doesn't compare, count towards neither.
+	MaxDifferingElements float64
+	// Relative difference of the numbers which is tolerated, e.g. 0.001 for
+	// a drift below 0.1%. Numbers differ by at most this fraction of the
+	// larger of their absolute values.
+	RelativeDrift float64
+}
+
+// tolerance returns the first tolerance applicable to the value at the
+// current path which has the rule, nil if there is none.
+func (ctx *context) tolerance(has func(t *Tolerance) bool) *Tolerance {
+	for i := range ctx.opts.Tolerance {
+		if t := &ctx.opts.Tolerance[i]; has(t) && ctx.pathMatches(t.Paths) {
+			return t
+		}
+	}
+	return nil
+}
+
+func hasMaxDifferingElements(t *Tolerance) bool { return t.MaxDifferingElements > 0 }
+func hasRelativeDrift(t *Tolerance) bool        { return t.RelativeDrift > 0 }
+
+// withinDrift returns true if the values are numbers within the tolerated
+// drift of each other.
+func (ctx *context) withinDrift(a, b interface{}) bool {
+	an, okA := a.(json.Number)
+	bn, okB := b.(json.Number)
+	if !okA || !okB || len(ctx.opts.Tolerance) == 0 {
+		return false
+	}
+	t := ctx.tolerance(hasRelativeDrift)
+	if t == nil {
+		return false
+	}
+	x, errA := strconv.ParseFloat(string(an), 64)
+	y, errB := strconv.ParseFloat(string(bn), 64)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return math.Abs(x-y) <= t.RelativeDrift*math.Max(math.Abs(x), math.Abs(y))
+}
+
+// arrayVerdict is the state of the verdict before an array with a tolerance
+// of differing elements is compared, see tolerateElements.
+type arrayVerdict struct {
+	t       *Tolerance
+	diff    Difference
+	reasons int
+}
+
+func (ctx *context) saveArrayVerdict() arrayVerdict {
+	if len(ctx.opts.Tolerance) == 0 {
+		return arrayVerdict{}
+	}
+	return arrayVerdict{ctx.tolerance(hasMaxDifferingElements), ctx.diff, len(ctx.reasons)}
+}
+
+// tolerateElements restores the verdict saved before the array was compared
+// if few enough of its elements differ.
+func (ctx *context) tolerateElements(v arrayVerdict, d *delta) {
+	if v.t == nil || !d.differs {
+		return
+	}
+	n, compared := 0, 0
+	for _, e := range d.elems {
+		if e.kind == deltaSkipped {
+			continue
+		}
+		compared++
+		if e.differs {
+			n++
+		}
+	}
+	if float64(n) <= v.t.MaxDifferingElements*float64(compared) {
+		ctx.diff = v.diff
+		ctx.reasons = ctx.reasons[:v.reasons]
+		ctx.result(FullMatch)
+	}
+}
@@ -0,0 +1,57 @@
+package jsondiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTolerance(t *testing.T) {
+	tolerance := []Tolerance{
+		{Paths: []string{"results"}, MaxDifferingElements: 0.25},
+		{Paths: []string{"**.latency"}, RelativeDrift: 0.001},
+	}
+	cases := []struct {
+		a, b   string
+		result Difference
+	}{
+		{`{"results": [1, 2, 3, 4]}`, `{"results": [1, 2, 3, 5]}`, FullMatch},
+		{`{"results": [1, 2, 3, 4]}`, `{"results": [1, 2, 5, 5]}`, NoMatch},
+		{`{"results": [1, 2, 3, 4]}`, `{"results": [1, 2, 3]}`, FullMatch},
+		{`{"results": [1, 2, 3, 4], "n": 1}`, `{"results": [1, 2, 3, 5], "n": 2}`, NoMatch},
+		{`{"results": [1, 2, 3, 4], "x": 1}`, `{"results": [1, 2, 3, 5]}`, SupersetMatch},
+		{`{"s": {"latency": 1000}}`, `{"s": {"latency": 1000.9}}`, FullMatch},
+		{`{"s": {"latency": -1000}}`, `{"s": {"latency": -1001.1}}`, NoMatch},
+		{`{"s": {"latency": 1000}}`, `{"s": {"latency": "1000"}}`, NoMatch},
+		{`{"s": {"count": 1000}}`, `{"s": {"count": 1000.9}}`, NoMatch},
+		{`{"results": [{"latency": 100}, 2]}`, `{"results": [{"latency": 100.01}, 3]}`, NoMatch},
+	}
+	for _, c := range cases {
+		opts := Options{Tolerance: tolerance}
+		result, node := CompareTree([]byte(c.a), []byte(c.b), &opts)
+		if result != c.result || !node.Differs {
+			t.Errorf("%s %s: got %s, expected %s", c.a, c.b, result, c.result)
+		}
+		if v := Verdict([]byte(c.a), []byte(c.b), &opts); v != c.result {
+			t.Errorf("%s %s: got verdict %s, expected %s", c.a, c.b, v, c.result)
+		}
+	}
+
+	opts := Options{Tolerance: tolerance}
+	_, _, reasons := CompareWithReasons([]byte(`{"results": [1, 2, 3, 4], "n": 1}`), []byte(`{"results": [1, 2, 3, 5], "n": 2}`), &opts)
+	if len(reasons) != 1 || reasons[0].Path != "n" {
+		t.Errorf("got reasons %v", reasons)
+	}
+}
+
+func TestToleranceSampling(t *testing.T) {
+	opts := Options{
+		Tolerance:     []Tolerance{{Paths: []string{""}, MaxDifferingElements: 0.25}},
+		ArraySampling: ArraySampling{MinLength: 10, Head: 2, Tail: 2},
+	}
+	a := []byte("[0, 0" + strings.Repeat(", 0", 100) + "]")
+	b := []byte("[1, 1" + strings.Repeat(", 0", 100) + "]")
+	// half of the compared elements differ, regardless of the skipped ones
+	if result, _ := Compare(a, b, &opts); result != NoMatch {
+		t.Errorf("got %s, expected NoMatch", result)
+	}
+}
@@ -0,0 +1,49 @@
+package jsondiff
+
+import (
+	"strconv"
+)
+
+var traceDecisions = [...]string{
+	deltaMatch:      "match",
+	deltaChanged:    "changed",
+	deltaAdded:      "added",
+	deltaRemoved:    "removed",
+	deltaCollection: "collection",
+	deltaSkipped:    "skipped",
+}
+
+// trace writes the decision made for the value at the current path to
+// Options.Trace.
+func (ctx *context) trace(d *delta) {
+	if ctx.opts.Trace == nil {
+		return
+	}
+	buf := make([]byte, 0, 64)
+	buf = strconv.AppendQuote(buf, ctx.pathString())
+	for _, left := range [...]bool{true, false} {
+		buf = append(buf, ' ')
+		v, ok := d.sideValue(left)
+		if _, matcher := v.(Matcher); matcher {
+			buf = append(buf, "matcher"...)
+		} else if ok {
+			buf = append(buf, typeName(v)...)
+		} else {
+			buf = append(buf, '-')
+		}
+	}
+	buf = append(buf, ' ')
+	buf = append(buf, traceDecisions[d.kind]...)
+	switch {
+	case d.kind == deltaCollection && d.differs:
+		buf = append(buf, " differs"...)
+	case d.kind == deltaCollection:
+		buf = append(buf, " matches"...)
+	case d.kind == deltaSkipped && d.placeholder != "":
+		buf = append(buf, " placeholder "...)
+		buf = strconv.AppendQuote(buf, d.placeholder)
+	}
+	buf = append(buf, '\n')
+	// tracing is a debugging aid, it never fails the comparison
+	_, _ = ctx.opts.Trace.Write(buf)
+}
@@ -0,0 +1,43 @@
+package jsondiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	var sb strings.Builder
+	opts := Options{
+		Trace:          &sb,
+		QuickFullMatch: true,
+		Ignore:         []string{"t"},
+		Skip: func(path string) SkipResult {
+			if path == "s" {
+				return SkipButPrintPlaceholder("<secret>")
+			}
+			return DontSkip
+		},
+	}
+	result := Verdict([]byte(`{"a": [1, 2], "b": true, "s": "x", "t": 1}`), []byte(`{"a": [1, "2"], "c": null, "s": "y", "t": 2}`), &opts)
+	if result != NoMatch {
+		t.Errorf("got %s, expected NoMatch", result)
+	}
+	expected := `"a.0" number number match
+"a.1" number string changed
+"a" array array collection differs
+"b" boolean - removed
+"c" - null added
+"s" string string skipped placeholder "<secret>"
+"t" number number skipped
+"" object object collection differs
+`
+	if sb.String() != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", sb.String(), expected)
+	}
+
+	sb.Reset()
+	Compare([]byte(`1`), []byte(`1`), &opts)
+	if sb.String() != "\"\" number number match\n" {
+		t.Errorf("got %q", sb.String())
+	}
+}
@@ -0,0 +1,152 @@
+package jsondiff
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// NodeKind is a kind of the comparison result node, see Node.
+type NodeKind int
+
+const (
+	// Values are equal.
+	NodeMatch NodeKind = iota
+	// Values are present in both documents, but differ. Includes values of
+	// different types.
+	NodeChanged
+	// Value is present only in the second document.
+	NodeAdded
+	// Value is present only in the first document.
+	NodeRemoved
+	// Both values are objects or both are arrays, see Children.
+	NodeCollection
+	// Value is skipped, see Options.Skip.
+	NodeSkipped
+)
+
+func (k NodeKind) String() string {
+	switch k {
+	case NodeMatch:
+		return "NodeMatch"
+	case NodeChanged:
+		return "NodeChanged"
+	case NodeAdded:
+		return "NodeAdded"
+	case NodeRemoved:
+		return "NodeRemoved"
+	case NodeCollection:
+		return "NodeCollection"
+	case NodeSkipped:
+		return "NodeSkipped"
+	}
+	return "Invalid"
+}
+
+// Node is a node of the structured comparison result tree, see CompareTree.
+type Node struct {
+	Kind NodeKind
+	// Path of the value, uses the syntax of path patterns.
+	Path string
+	// Object key of the value, empty for array elements and the root value.
+	Key string
+	// Array index of the value, -1 for object properties and the root value.
+	Index int
+	// Values in the first and the second documents, nil if the value is
+	// missing in the corresponding document.
+	A interface{}
+	B interface{}
+	// Elements of compared collections ordered as in the text output.
+	Children []*Node
+	// True if there is a difference anywhere in this subtree.
+	Differs bool
+	// Placeholder of a skipped value, see SkipButPrintPlaceholder.
+	Placeholder string
+}
+
+var nodeKinds = [...]NodeKind{
+	deltaMatch:      NodeMatch,
+	deltaChanged:    NodeChanged,
+	deltaAdded:      NodeAdded,
+	deltaRemoved:    NodeRemoved,
+	deltaCollection: NodeCollection,
+	deltaSkipped:    NodeSkipped,
+}
+
+func (ctx *context) node(d *delta, path, key string, index int) *Node {
+	n := &Node{
+		Kind:        nodeKinds[d.kind],
+		Path:        path,
+		Key:         key,
+		Index:       index,
+		A:           d.a,
+		B:           d.b,
+		Differs:     d.differs,
+		Placeholder: d.placeholder,
+	}
+	if d.kind == deltaSkipped {
+		n.A, _ = d.sideValue(true)
+		n.B, _ = d.sideValue(false)
+	}
+	if len(d.elems) != 0 {
+		n.Children = make([]*Node, len(d.elems))
+	}
+	for i, e := range d.elems {
+		if d.isObject() {
+			n.Children[i] = ctx.node(e, childPath(path, d.keys[i]), d.keys[i], -1)
+		} else {
+			idx := strconv.Itoa(i)
+			n.Children[i] = ctx.node(e, childPath(path, idx), "", i)
+		}
+	}
+	return n
+}
+
+// CompareTree compares two JSON documents like Compare, but returns the result
+// as a tree of nodes instead of a rendering, which is suitable for building
+// interactive user interfaces. Returns nil node when any of the documents is
+// invalid JSON.
+func CompareTree(a, b []byte, opts *Options) (Difference, *Node) {
+	ctx := context{opts: opts}
+	d, diff, _ := ctx.decodeAndCompare(bytes.NewReader(a), bytes.NewReader(b))
+	if d == nil {
+		return diff, nil
+	}
+	return ctx.diff, ctx.node(d, "", "", -1)
+}
+
+// MatchedPaths returns paths of the values in the subtree which fully matched,
+// in the order of the text output. Collections are described by paths of
+// their elements, unless they are empty or matched as a whole, e.g. because
+// of EmptyCollectionMatchesAny. Skipped values are neither matched nor
+// unmatched.
+func (n *Node) MatchedPaths() []string {
+	return n.appendPaths(nil, false)
+}
+
+// UnmatchedPaths returns paths of the changed, added and removed values in
+// the subtree, in the order of the text output, see MatchedPaths.
+func (n *Node) UnmatchedPaths() []string {
+	return n.appendPaths(nil, true)
+}
+
+func (n *Node) appendPaths(paths []string, unmatched bool) []string {
+	switch n.Kind {
+	case NodeSkipped:
+	case NodeCollection:
+		if len(n.Children) == 0 && n.Differs == unmatched {
+			paths = append(paths, n.Path)
+		}
+		for _, c := range n.Children {
+			paths = c.appendPaths(paths, unmatched)
+		}
+	case NodeMatch:
+		if !unmatched {
+			paths = append(paths, n.Path)
+		}
+	default:
+		if unmatched {
+			paths = append(paths, n.Path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,71 @@
+package jsondiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareTree(t *testing.T) {
+	opts := DefaultJSONOptions()
+	result, root := CompareTree(
+		[]byte(`{"a": [1, 2, 3], "b": "foo", "c": true}`),
+		[]byte(`{"a": [1, 5], "b": "foo", "d": {}}`),
+		&opts,
+	)
+	if result != NoMatch {
+		t.Fatalf("got %s, expected NoMatch", result)
+	}
+	if root.Kind != NodeCollection || !root.Differs || root.Index != -1 {
+		t.Fatalf("unexpected root node: %+v", root)
+	}
+	expected := []struct {
+		path string
+		kind NodeKind
+	}{
+		{"a", NodeCollection},
+		{"b", NodeMatch},
+		{"c", NodeRemoved},
+		{"d", NodeAdded},
+	}
+	if len(root.Children) != len(expected) {
+		t.Fatalf("got %d children, expected %d", len(root.Children), len(expected))
+	}
+	for i, e := range expected {
+		n := root.Children[i]
+		if n.Path != e.path || n.Key != e.path || n.Kind != e.kind {
+			t.Errorf("child %d: got %s %s, expected %s %s", i, n.Path, n.Kind, e.path, e.kind)
+		}
+	}
+	arr := root.Children[0].Children
+	if len(arr) != 3 {
+		t.Fatalf("got %d array elements, expected 3", len(arr))
+	}
+	if arr[1].Path != "a.1" || arr[1].Index != 1 || arr[1].Kind != NodeChanged {
+		t.Errorf("unexpected array element: %+v", arr[1])
+	}
+	if arr[2].Kind != NodeRemoved || arr[2].B != nil {
+		t.Errorf("unexpected removed element: %+v", arr[2])
+	}
+
+	result, root = CompareTree([]byte(`{`), []byte(`{}`), &opts)
+	if result != FirstArgIsInvalidJson || root != nil {
+		t.Errorf("got %s %v, expected FirstArgIsInvalidJson and nil node", result, root)
+	}
+}
+
+func TestMatchedPaths(t *testing.T) {
+	opts := DefaultJSONOptions()
+	_, root := CompareTree(
+		[]byte(`{"a": [1, 2, 3], "b": "foo", "c": true, "e": {}}`),
+		[]byte(`{"a": [1, 5], "b": "foo", "d": {}, "e": {}}`),
+		&opts,
+	)
+	matched := strings.Join(root.MatchedPaths(), " ")
+	if matched != "a.0 b e" {
+		t.Errorf("got matched paths %q, expected %q", matched, "a.0 b e")
+	}
+	unmatched := strings.Join(root.UnmatchedPaths(), " ")
+	if unmatched != "a.1 a.2 c d" {
+		t.Errorf("got unmatched paths %q, expected %q", unmatched, "a.1 a.2 c d")
+	}
+}
@@ -0,0 +1,275 @@
+// Package tui provides a Bubble Tea model for browsing jsondiff comparison
+// results interactively.
+//
+// Collections are shown as foldable lines, collections without differences
+// start folded. Key bindings:
+//
+//	up/k, down/j    move the cursor
+//	pgup, pgdown    move the cursor by a page
+//	enter, space    fold or unfold the collection under the cursor
+//	n, N            jump to the next or the previous difference
+//	q, ctrl+c       quit
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nsf/jsondiff"
+)
+
+const (
+	colorReset   = "\x1b[0m"
+	colorAdded   = "\x1b[30;42m"
+	colorRemoved = "\x1b[30;41m"
+	colorChanged = "\x1b[30;43m"
+	colorSkipped = "\x1b[90m"
+	colorCursor  = "\x1b[7m"
+)
+
+type line struct {
+	node  *jsondiff.Node
+	depth int
+	// True for the closing bracket line of an unfolded collection.
+	closing bool
+}
+
+// Model is a Bubble Tea model displaying a comparison result tree, see
+// jsondiff.CompareTree.
+type Model struct {
+	root     *jsondiff.Node
+	folded   map[*jsondiff.Node]bool
+	lines    []line
+	cursor   int
+	offset   int
+	height   int
+	Indent   string
+	Quitting bool
+}
+
+// New returns a model displaying the given comparison result tree.
+func New(root *jsondiff.Node) Model {
+	m := Model{
+		root:   root,
+		folded: make(map[*jsondiff.Node]bool),
+		height: 24,
+		Indent: "    ",
+	}
+	fold(root, m.folded)
+	m.lines = m.flatten()
+	return m
+}
+
+func fold(n *jsondiff.Node, folded map[*jsondiff.Node]bool) {
+	if n == nil || n.Kind != jsondiff.NodeCollection {
+		return
+	}
+	if !n.Differs && len(n.Children) != 0 {
+		folded[n] = true
+		return
+	}
+	for _, c := range n.Children {
+		fold(c, folded)
+	}
+}
+
+func (m *Model) flatten() []line {
+	var lines []line
+	var walk func(n *jsondiff.Node, depth int)
+	walk = func(n *jsondiff.Node, depth int) {
+		lines = append(lines, line{node: n, depth: depth})
+		if n.Kind != jsondiff.NodeCollection || m.folded[n] || len(n.Children) == 0 {
+			return
+		}
+		for _, c := range n.Children {
+			walk(c, depth+1)
+		}
+		lines = append(lines, line{node: n, depth: depth, closing: true})
+	}
+	if m.root != nil {
+		walk(m.root, 0)
+	}
+	return lines
+}
+
+// Init implements tea.Model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			m.Quitting = true
+			return m, tea.Quit
+		case "up", "k":
+			m.cursor--
+		case "down", "j":
+			m.cursor++
+		case "pgup":
+			m.cursor -= m.height
+		case "pgdown":
+			m.cursor += m.height
+		case "enter", " ":
+			m.toggle()
+		case "n":
+			m.seek(1)
+		case "N":
+			m.seek(-1)
+		}
+	}
+	m.clamp()
+	return m, nil
+}
+
+func (m *Model) toggle() {
+	if m.cursor >= len(m.lines) {
+		return
+	}
+	n := m.lines[m.cursor].node
+	if n.Kind != jsondiff.NodeCollection || len(n.Children) == 0 {
+		return
+	}
+	m.folded[n] = !m.folded[n]
+	m.lines = m.flatten()
+	// keep the cursor on the opening line when folding from the closing one
+	for i, l := range m.lines {
+		if l.node == n && !l.closing {
+			m.cursor = i
+			break
+		}
+	}
+}
+
+// seek moves the cursor to the next difference in the given direction,
+// unfolding collections along the way.
+func (m *Model) seek(dir int) {
+	for i := m.cursor + dir; i >= 0 && i < len(m.lines); i += dir {
+		l := m.lines[i]
+		if l.closing || !l.node.Differs {
+			continue
+		}
+		if l.node.Kind == jsondiff.NodeCollection {
+			if m.folded[l.node] {
+				m.folded[l.node] = false
+				m.lines = m.flatten()
+			}
+			continue
+		}
+		m.cursor = i
+		return
+	}
+}
+
+func (m *Model) clamp() {
+	if m.cursor >= len(m.lines) {
+		m.cursor = len(m.lines) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.height > 0 && m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+// View implements tea.Model.
+func (m Model) View() string {
+	if m.Quitting {
+		return ""
+	}
+	if m.root == nil {
+		return "nothing to compare\n"
+	}
+	var buf strings.Builder
+	end := len(m.lines)
+	if m.height > 0 && m.offset+m.height < end {
+		end = m.offset + m.height
+	}
+	for i := m.offset; i < end; i++ {
+		s := m.render(m.lines[i])
+		if i == m.cursor {
+			s = colorCursor + s + colorReset
+		}
+		buf.WriteString(strings.Repeat(m.Indent, m.lines[i].depth))
+		buf.WriteString(s)
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+func (m *Model) render(l line) string {
+	n := l.node
+	if l.closing {
+		return closing(n)
+	}
+	prefix := ""
+	if n.Index == -1 && n != m.root {
+		prefix = encode(n.Key) + ": "
+	}
+	switch n.Kind {
+	case jsondiff.NodeCollection:
+		if len(n.Children) == 0 {
+			return prefix + opening(n) + closing(n)
+		}
+		if m.folded[n] {
+			return fmt.Sprintf("%s%s ... %d %s", prefix, opening(n), len(n.Children), closing(n))
+		}
+		return prefix + opening(n)
+	case jsondiff.NodeChanged:
+		return colorChanged + prefix + encode(n.A) + " => " + encode(n.B) + colorReset
+	case jsondiff.NodeAdded:
+		return colorAdded + prefix + encode(n.B) + colorReset
+	case jsondiff.NodeRemoved:
+		return colorRemoved + prefix + encode(n.A) + colorReset
+	case jsondiff.NodeSkipped:
+		if n.Placeholder != "" {
+			return colorSkipped + prefix + n.Placeholder + colorReset
+		}
+		return colorSkipped + prefix + "..." + colorReset
+	}
+	return prefix + encode(n.A)
+}
+
+func opening(n *jsondiff.Node) string {
+	if _, ok := n.A.(map[string]interface{}); ok {
+		return "{"
+	}
+	return "["
+}
+
+func closing(n *jsondiff.Node) string {
+	if _, ok := n.A.(map[string]interface{}); ok {
+		return "}"
+	}
+	return "]"
+}
+
+func encode(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// Run compares two JSON documents and browses the result interactively until
+// the user quits.
+func Run(a, b []byte, opts *jsondiff.Options) error {
+	diff, root := jsondiff.CompareTree(a, b, opts)
+	if root == nil {
+		return fmt.Errorf("jsondiff: %s", diff)
+	}
+	_, err := tea.NewProgram(New(root), tea.WithAltScreen()).Run()
+	return err
+}
@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nsf/jsondiff"
+)
+
+func TestModel(t *testing.T) {
+	opts := jsondiff.DefaultJSONOptions()
+	_, root := jsondiff.CompareTree(
+		[]byte(`{"a": [1, 2], "b": {"x": 1}, "c": 1}`),
+		[]byte(`{"a": [1, 3], "b": {"x": 1}, "c": 1}`),
+		&opts,
+	)
+	m := New(root)
+	view := m.View()
+	if !strings.Contains(view, `"b": { ... 1 }`) {
+		t.Errorf("expected folded matching object, got:\n%s", view)
+	}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = next.(Model)
+	if l := m.lines[m.cursor]; l.node.Path != "a.1" {
+		t.Errorf("got cursor at %q, expected a.1", l.node.Path)
+	}
+}
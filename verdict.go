@@ -0,0 +1,256 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+var (
+	// errNoMatch stops incremental comparison once the verdict is known.
+	errNoMatch = errors.New("jsondiff: no match")
+	// errNotIncremental stops incremental comparison when the document
+	// can't be compared this way, e.g. because of duplicate object keys.
+	errNotIncremental = errors.New("jsondiff: document can't be compared incrementally")
+)
+
+// Verdict compares two JSON documents like Compare, but only returns the
+// verdict without rendering the difference. The second document is decoded
+// as usual, while the first one is decoded along with the comparison, which
+// stops as soon as the verdict is NoMatch, so that the rest of the document
+// is only validated. Strict, binary input formats, Tolerance and the options
+// which rewrite documents before they are compared (Lenient, FirstRoot,
+// SecondRoot, NumericKeysAsArrays, KeyAliases, NormalizeKeys, Transform,
+// UnorderedArrays, SortArraysAt, KeyedArrays, ArraySampling and Comparators)
+// require both documents to be decoded fully.
+// When the first document has duplicate object keys, the last one wins as
+// usual, unless the verdict is NoMatch before the duplicate is read.
+func Verdict(a, b []byte, opts *Options) Difference {
+	ctx := context{opts: opts}
+	if ctx.quickFullMatchAllowed() && identicalJSON(a, b) {
+		return FullMatch
+	}
+	if ctx.incremental() {
+		if diff, ok := ctx.verdict(a, b); ok {
+			return diff
+		}
+		ctx = context{opts: opts}
+	}
+	d, diff, _ := ctx.decodeAndCompare(bytes.NewReader(a), bytes.NewReader(b))
+	if d == nil {
+		return diff
+	}
+	return ctx.diff
+}
+
+// incremental tells whether options allow to compare the first document
+// while it's being decoded.
+func (ctx *context) incremental() bool {
+	o := ctx.opts
+	return !o.Lenient && !o.Strict && o.InputFormat == JSONInput && o.FirstRoot == "" && o.SecondRoot == "" && !o.NumericKeysAsArrays &&
+		len(o.KeyAliases) == 0 && o.NormalizeKeys == nil && o.Transform == nil &&
+		len(o.UnorderedArrays) == 0 && len(o.SortArraysAt) == 0 && len(o.KeyedArrays) == 0 &&
+		!o.ArraySampling.enabled() && o.Trace == nil && len(o.Comparators) == 0 &&
+		len(o.Tolerance) == 0
+}
+
+// verdict compares the first document incrementally, returns false if it has
+// to be compared as usual.
+func (ctx *context) verdict(a, b []byte) (Difference, bool) {
+	// validate the first document the same way decode does, without
+	// building its values
+	var raw json.RawMessage
+	dec := json.NewDecoder(newEncodingReader(bytes.NewReader(a)))
+	errA := dec.Decode(&raw)
+	bv, errB := ctx.decode(bytes.NewReader(b))
+	switch {
+	case errA != nil && errB != nil:
+		return BothArgsAreInvalidJson, true
+	case errA != nil:
+		return FirstArgIsInvalidJson, true
+	case errB != nil:
+		return SecondArgIsInvalidJson, true
+	}
+
+	dec = json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	t, err := dec.Token()
+	if err == nil {
+		err = ctx.streamValue(dec, t, bv)
+	}
+	if err != nil && err != errNoMatch {
+		return 0, false
+	}
+	return ctx.diff, true
+}
+
+// streamValue compares the value starting with the given token against b,
+// mirrors compare.
+func (ctx *context) streamValue(dec *json.Decoder, t json.Token, b interface{}) error {
+	if p, ok := ctx.placeholder(b); ok && p.matchesType(tokenTypeName(t)) {
+		ctx.result(FullMatch)
+		return skipValue(dec, t)
+	}
+	switch t {
+	case json.Delim('{'):
+		if bb, ok := b.(map[string]interface{}); ok {
+			return ctx.streamObject(dec, bb)
+		}
+	case json.Delim('['):
+		if bb, ok := b.([]interface{}); ok {
+			return ctx.streamArray(dec, bb)
+		}
+	default:
+		if ctx.equalLeaves(t, b) {
+			ctx.result(FullMatch)
+			return nil
+		}
+	}
+	ctx.downgrade(ValueChanged)
+	return errNoMatch
+}
+
+// streamElem compares the next collection element against b, mirrors
+// compareElem.
+func (ctx *context) streamElem(dec *json.Decoder, b interface{}, bOK bool, optional bool) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	// collections are passed as delimiters, which are never ignored values
+	if r := ctx.skip(t, true, b, bOK); r.skip {
+		return skipValue(dec, t)
+	}
+	if bOK {
+		return ctx.streamValue(dec, t, b)
+	}
+	if !optional {
+		ctx.downgrade(ValueRemoved)
+	}
+	return skipValue(dec, t)
+}
+
+// streamAdded handles an element missing in the first document.
+func (ctx *context) streamAdded(b interface{}, optional bool) error {
+	if r := ctx.skip(nil, false, b, true); r.skip || optional {
+		return nil
+	}
+	ctx.downgrade(ValueAdded)
+	return errNoMatch
+}
+
+// streamEmpty applies EmptyCollectionMode to a non-empty first collection and
+// an empty second one, returns true if the collection is compared.
+func (ctx *context) streamEmpty(dec *json.Decoder, mode EmptyCollectionMode, open json.Delim) (bool, error) {
+	switch mode {
+	case EmptyCollectionMatchesAny:
+		ctx.result(FullMatch)
+		return true, skipValue(dec, open)
+	case EmptyCollectionMatchesEmpty:
+		ctx.downgrade(CollectionNotEmpty)
+		return true, errNoMatch
+	}
+	return false, nil
+}
+
+func (ctx *context) streamObject(dec *json.Decoder, b map[string]interface{}) error {
+	if len(b) == 0 && dec.More() {
+		if done, err := ctx.streamEmpty(dec, ctx.opts.EmptyObject, '{'); done {
+			return err
+		}
+	}
+	seen := make(map[string]bool, len(b))
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		k := t.(string)
+		vb, bOK := b[k]
+		if bOK {
+			// the last of duplicate keys wins when decoding
+			if seen[k] {
+				return errNotIncremental
+			}
+			seen[k] = true
+		}
+		ctx.pushPath(k)
+		err = ctx.streamElem(dec, vb, bOK, !bOK && ctx.pathMatches(ctx.opts.OptionalKeys))
+		ctx.popPath()
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	if len(seen) == len(b) {
+		return nil
+	}
+	for k, vb := range b {
+		if seen[k] {
+			continue
+		}
+		ctx.pushPath(k)
+		err := ctx.streamAdded(vb, ctx.pathMatches(ctx.opts.OptionalKeys))
+		ctx.popPath()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ctx *context) streamArray(dec *json.Decoder, b []interface{}) error {
+	if len(b) == 0 && dec.More() {
+		if done, err := ctx.streamEmpty(dec, ctx.opts.EmptyArray, '['); done {
+			return err
+		}
+	}
+	i := 0
+	for ; dec.More(); i++ {
+		var vb interface{}
+		if i < len(b) {
+			vb = b[i]
+		}
+		ctx.pushPath(strconv.Itoa(i))
+		err := ctx.streamElem(dec, vb, i < len(b), false)
+		ctx.popPath()
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for ; i < len(b); i++ {
+		ctx.pushPath(strconv.Itoa(i))
+		err := ctx.streamAdded(b[i], false)
+		ctx.popPath()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipValue reads the rest of the value starting with the given token.
+func skipValue(dec *json.Decoder, t json.Token) error {
+	depth := 0
+	for {
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+		var err error
+		if t, err = dec.Token(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestVerdict(t *testing.T) {
+	cases := []struct {
+		a    string
+		b    string
+		opts Options
+	}{
+		{`{"a": 1, "b": [1, 2]}`, `{"b": [1, 2], "a": 1}`, Options{}},
+		{`{"a": 1, "b": [1, 2, 3]}`, `{"a": 1, "b": [1, 2]}`, Options{}},
+		{`{"a": 1, "b": [1, 2]}`, `{"a": 1, "b": [1, 2, 3]}`, Options{}},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, Options{}},
+		{`{"a": {"x": 1}}`, `{"a": [1]}`, Options{}},
+		{`{"a": "1"}`, `{"a": 1}`, Options{}},
+		{`[null, true, "s"]`, `[null, true, "s"]`, Options{}},
+		{`{"a": {"b": 1}}`, `{"a": {}}`, Options{EmptyObject: EmptyCollectionMatchesAny}},
+		{`{"a": {"b": 1}}`, `{"a": {}}`, Options{EmptyObject: EmptyCollectionMatchesEmpty}},
+		{`[[1, 2]]`, `[[]]`, Options{EmptyArray: EmptyCollectionMatchesAny}},
+		{`[[1, 2]]`, `[[]]`, Options{}},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, Options{OptionalKeys: []string{"b"}}},
+		{`{"a": 1, "t": 5}`, `{"a": 1, "t": 6}`, Options{Ignore: []string{"t"}}},
+		{`{"id": "x-1", "v": 2}`, `{"id": "x-2", "v": 2}`, Options{IgnoreValuesMatching: []*regexp.Regexp{regexp.MustCompile(`^x-`)}}},
+		{`{"n": 1.0}`, `{"n": 1}`, Options{CompareNumbers: func(a, b json.Number) bool { return true }}},
+		{`{"a": {"x": 1, "y": 2}, "a": {"x": 1}}`, `{"a": {"x": 1}}`, Options{}},
+		{`{"a": 1, "A": 2}`, `{"a": 1}`, Options{NormalizeKeys: strings.ToLower}},
+		{`{"a": 1}`, `{"a": 1}`, Options{QuickFullMatch: true}},
+		{`{"a": 1`, `{"a": 1}`, Options{}},
+		{`{"a": 1}`, `[`, Options{}},
+		{`x`, `[`, Options{}},
+		{`{"a": 1} trailing`, `{"a": 1}`, Options{}},
+	}
+	for _, c := range cases {
+		expected, _ := Compare([]byte(c.a), []byte(c.b), &c.opts)
+		if result := Verdict([]byte(c.a), []byte(c.b), &c.opts); result != expected {
+			t.Errorf("%s vs %s: got %s, expected %s", c.a, c.b, result, expected)
+		}
+	}
+}
+
+func largeDocument(n int, last string) []byte {
+	var buf strings.Builder
+	buf.WriteString(`{"first": 0, "items": [`)
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, `{"id": %d, "name": "item %d", "tags": ["a", "b", "c"]}`, i, i)
+	}
+	buf.WriteString(`], "last": "` + last + `"}`)
+	return []byte(buf.String())
+}
+
+func BenchmarkVerdictMismatch(b *testing.B) {
+	doc := largeDocument(10000, "x")
+	other := []byte(`{"first": 1}`)
+	opts := Options{}
+	for i := 0; i < b.N; i++ {
+		Verdict(doc, other, &opts)
+	}
+}
+
+func BenchmarkCompareMismatch(b *testing.B) {
+	doc := largeDocument(10000, "x")
+	other := []byte(`{"first": 1}`)
+	opts := Options{SkipMatches: true}
+	for i := 0; i < b.N; i++ {
+		Compare(doc, other, &opts)
+	}
+}
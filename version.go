@@ -0,0 +1,10 @@
+package jsondiff
+
+// version of the library, included into structured outputs
+const version = "0.1.0"
+
+// Version returns the version of the library, which is also included into
+// structured outputs, see Options.DocumentHeader.
+func Version() string {
+	return version
+}
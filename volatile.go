@@ -0,0 +1,43 @@
+package jsondiff
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// VolatilePaths analyzes samples of the "same" document, e.g. responses to the
+// same request made at different times, and returns sorted paths of values
+// which vary between samples. The paths use the syntax of path patterns and are
+// candidates for Options.Ignore.
+//
+// Every sample is compared with the first one using the given options, so that
+// already ignored or skipped values aren't reported. A path of a collection is
+// reported instead of paths of its elements when it's added, removed or its
+// type changes. Returns an error when any of the samples is invalid JSON.
+func VolatilePaths(samples [][]byte, opts *Options) ([]string, error) {
+	values := make([]interface{}, len(samples))
+	for i, s := range samples {
+		ctx := context{opts: opts}
+		v, err := ctx.decode(bytes.NewReader(s))
+		if err != nil {
+			return nil, fmt.Errorf("jsondiff: sample %d is invalid json: %v", i, err)
+		}
+		values[i] = ctx.transform(v)
+	}
+
+	seen := make(map[string]bool)
+	paths := []string{}
+	for i := 1; i < len(values); i++ {
+		ctx := context{opts: opts, collectReasons: true}
+		ctx.compare(values[0], values[i])
+		for _, r := range ctx.reasons {
+			if !seen[r.Path] {
+				seen[r.Path] = true
+				paths = append(paths, r.Path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
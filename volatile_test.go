@@ -0,0 +1,33 @@
+package jsondiff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVolatilePaths(t *testing.T) {
+	opts := DefaultJSONOptions()
+	samples := [][]byte{
+		[]byte(`{"id": 1, "time": 10, "items": [{"name": "a", "etag": "x"}], "meta": {"trace": "q"}}`),
+		[]byte(`{"id": 1, "time": 20, "items": [{"name": "a", "etag": "y"}]}`),
+		[]byte(`{"id": 1, "time": 30, "items": [{"name": "a", "etag": "x"}], "meta": {"trace": "w"}}`),
+	}
+	paths, err := VolatilePaths(samples, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"items.0.etag", "meta", "meta.trace", "time"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("got %q, expected %q", paths, expected)
+	}
+
+	opts.Ignore = []string{"time", "meta"}
+	paths, _ = VolatilePaths(samples, &opts)
+	if !reflect.DeepEqual(paths, []string{"items.0.etag"}) {
+		t.Errorf("got %q with ignored paths", paths)
+	}
+
+	if _, err := VolatilePaths([][]byte{[]byte(`{}`), []byte(`{`)}, &opts); err == nil {
+		t.Error("expected an error for invalid sample")
+	}
+}
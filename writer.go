@@ -0,0 +1,318 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// TagKind identifies highlighting of the text, see DiffWriter.
+type TagKind int
+
+const (
+	// No highlighting, used to end the current tag.
+	NoTag TagKind = iota
+	NormalTag
+	AddedTag
+	RemovedTag
+	ChangedTag
+	SkippedTag
+)
+
+// DiffWriter receives the text format rendering as a sequence of low-level
+// calls, which allows to implement custom renderers without reimplementing the
+// comparison and layout logic, see CompareWithWriter. The text format itself is
+// produced by a DiffWriter which maps tag kinds to Options tags.
+type DiffWriter interface {
+	// Tag switches highlighting of the following output. It's called even
+	// if the kind is the same as the current one.
+	Tag(kind TagKind)
+	// Text writes punctuation and other auxiliary text: brackets, commas,
+	// changed values separator, type annotations, skipped values messages and
+	// placeholders.
+	Text(s string)
+	// Newline ends the current line and starts a new one, indented for the
+	// given nesting level. Highlighting continues on the new line.
+	Newline(level int)
+	// Key writes an object key, it's followed by the corresponding value.
+	Key(key string)
+	// Scalar writes a scalar JSON value: nil, bool, json.Number or string, or
+	// a Matcher of the expected document or a value of a type with an
+	// equality function, see CompareInterfaces.
+	Scalar(v interface{})
+}
+
+// textWriter is a DiffWriter which produces the text format.
+type textWriter struct {
+	opts    *Options
+	buf     *bytes.Buffer
+	lastTag *Tag
+	// current highlighting, the first highlighting of the current line and
+	// where the line starts, used to print glyphs
+	kind      TagKind
+	lineKind  TagKind
+	lineStart int
+	// nesting level and width of the current line, and whether anything
+	// was written after the indentation, used to wrap lines
+	level     int
+	col       int
+	lineEmpty bool
+}
+
+func newTextWriter(opts *Options) *textWriter {
+	return &textWriter{opts: opts, buf: &bytes.Buffer{}, lineEmpty: true}
+}
+
+func (w *textWriter) tag(kind TagKind) *Tag {
+	switch kind {
+	case NormalTag:
+		return &w.opts.Normal
+	case AddedTag:
+		return &w.opts.Added
+	case RemovedTag:
+		return &w.opts.Removed
+	case ChangedTag:
+		return &w.opts.Changed
+	case SkippedTag:
+		return &w.opts.Skipped
+	}
+	return nil
+}
+
+func (w *textWriter) Tag(kind TagKind) {
+	w.kind = kind
+	if w.lineKind == NoTag || w.lineKind == NormalTag {
+		w.lineKind = kind
+	}
+	tag := w.tag(kind)
+	if w.lastTag == tag {
+		return
+	} else if w.lastTag != nil {
+		w.buf.WriteString(w.lastTag.End)
+	}
+	if tag != nil {
+		w.buf.WriteString(tag.Begin)
+	}
+	w.lastTag = tag
+}
+
+// write writes the text wrapping it at Options.MaxLineWidth.
+func (w *textWriter) write(s string) {
+	runes := utf8.RuneCountInString(s)
+	for max := w.opts.MaxLineWidth; max > 0; {
+		avail := max - w.col
+		if runes <= avail || (avail <= 0 && w.lineEmpty) {
+			break
+		}
+		if avail > 0 {
+			i := 0
+			for n := 0; n < avail; n++ {
+				_, size := utf8.DecodeRuneInString(s[i:])
+				i += size
+			}
+			w.buf.WriteString(s[:i])
+			s = s[i:]
+			runes -= avail
+		}
+		w.wrap()
+	}
+	if s != "" {
+		w.buf.WriteString(s)
+		w.col += runes
+		w.lineEmpty = false
+	}
+}
+
+func (w *textWriter) Text(s string) {
+	w.write(s)
+}
+
+// glyph inserts the glyph at the beginning of the current line.
+func (w *textWriter) glyph() {
+	var g string
+	switch w.lineKind {
+	case AddedTag:
+		g = w.opts.Glyphs.Added
+	case RemovedTag:
+		g = w.opts.Glyphs.Removed
+	case ChangedTag:
+		g = w.opts.Glyphs.Changed
+	case SkippedTag:
+		g = w.opts.Glyphs.Skipped
+	default:
+		g = w.opts.Glyphs.Normal
+	}
+	if g == "" {
+		return
+	}
+	line := append([]byte(nil), w.buf.Bytes()[w.lineStart:]...)
+	w.buf.Truncate(w.lineStart)
+	w.buf.WriteString(g)
+	w.buf.Write(line)
+}
+
+func (w *textWriter) Newline(level int) {
+	w.level = level
+	w.breakLine(level)
+	w.lineKind = w.kind
+}
+
+// wrap continues the current line on the next one with an extra indentation.
+func (w *textWriter) wrap() {
+	w.breakLine(w.level + 1)
+}
+
+func (w *textWriter) breakLine(level int) {
+	if w.lastTag != nil {
+		w.buf.WriteString(w.lastTag.End)
+	}
+	w.glyph()
+	w.buf.WriteString("\n")
+	w.lineStart = w.buf.Len()
+	w.buf.WriteString(w.opts.Prefix)
+	for i := 0; i < level; i++ {
+		w.buf.WriteString(w.opts.Indent)
+	}
+	w.col = utf8.RuneCountInString(w.opts.Prefix) + level*utf8.RuneCountInString(w.opts.Indent)
+	w.lineEmpty = true
+	if w.lastTag != nil {
+		w.buf.WriteString(w.lastTag.Begin)
+	}
+}
+
+func (w *textWriter) Key(k string) {
+	w.write(quoteKey(k, w.opts))
+	w.write(": ")
+}
+
+func (w *textWriter) Scalar(v interface{}) {
+	switch vv := v.(type) {
+	case bool:
+		w.write(strconv.FormatBool(vv))
+	case json.Number:
+		if w.opts.NormalizeNumbers {
+			w.write(normalizeNumber(vv))
+		} else {
+			w.write(string(vv))
+		}
+	case string:
+		w.write(quoteString(vv, w.opts.QuoteMode))
+	case Matcher:
+		w.write(vv.String())
+	default:
+		if w.opts.Labels != nil {
+			w.write(w.opts.Labels.Null())
+		} else {
+			w.write("null")
+		}
+	}
+}
+
+// quoteKey quotes the object key according to Options.QuoteMode, unless it's
+// an identifier left unquoted because of Options.UnquotedKeys.
+func quoteKey(k string, opts *Options) string {
+	if opts.UnquotedKeys && isIdentifier(k) {
+		return k
+	}
+	return quoteString(k, opts.QuoteMode)
+}
+
+// isIdentifier returns true if the string consists of letters, digits and
+// underscores and doesn't start with a digit.
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// quoteString quotes the string according to the mode.
+func quoteString(s string, mode QuoteMode) string {
+	switch mode {
+	case QuoteJSON:
+		return quoteJSON(s, true)
+	case QuoteRaw:
+		return quoteJSON(s, false)
+	}
+	return strconv.Quote(s)
+}
+
+// quoteJSON quotes the string as a JSON string, non-ASCII characters are
+// escaped only when ascii is true.
+func quoteJSON(s string, ascii bool) string {
+	const hex = "0123456789abcdef"
+	buf := make([]byte, 0, len(s)+2)
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if !ascii {
+				buf = append(buf, s[i:i+size]...)
+			} else if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+				buf = append(buf, '\\', 'u', hex[r1>>12], hex[r1>>8&0xf], hex[r1>>4&0xf], hex[r1&0xf])
+				buf = append(buf, '\\', 'u', hex[r2>>12], hex[r2>>8&0xf], hex[r2>>4&0xf], hex[r2&0xf])
+			} else {
+				// invalid UTF-8 is decoded as U+FFFD, the same way
+				// encoding/json replaces it
+				buf = append(buf, '\\', 'u', hex[r>>12], hex[r>>8&0xf], hex[r>>4&0xf], hex[r&0xf])
+			}
+			i += size
+			continue
+		}
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			if c < 0x20 {
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			} else {
+				buf = append(buf, c)
+			}
+		}
+		i++
+	}
+	return string(append(buf, '"'))
+}
+
+// String terminates the current tag and returns the output.
+func (w *textWriter) String() string {
+	w.Tag(NoTag)
+	w.glyph()
+	return w.buf.String()
+}
+
+// CompareWithWriter compares two JSON documents like Compare, but instead of
+// returning the text format rendering, passes it to the given DiffWriter.
+// Format option is ignored. When any of the documents is invalid JSON, the
+// message is written as Text.
+func CompareWithWriter(a, b []byte, opts *Options, w DiffWriter) Difference {
+	return CompareStreamsWithWriter(bytes.NewReader(a), bytes.NewReader(b), opts, w)
+}
+
+// CompareStreamsWithWriter is like CompareWithWriter, but reads JSON documents
+// from the specified readers.
+func CompareStreamsWithWriter(a, b io.Reader, opts *Options, w DiffWriter) Difference {
+	ctx := context{opts: opts}
+	d, diff, msg := ctx.decodeAndCompare(a, b)
+	if d == nil {
+		w.Text(msg)
+		return diff
+	}
+	ctx.w = w
+	ctx.markOutput(d)
+	ctx.printDelta(d)
+	return ctx.diff
+}
@@ -0,0 +1,122 @@
+package jsondiff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// markupWriter renders tags as named brackets and ignores indentation.
+type markupWriter struct {
+	sb  strings.Builder
+	tag TagKind
+}
+
+func (w *markupWriter) Tag(kind TagKind) {
+	if w.tag == kind {
+		return
+	}
+	if w.tag > NormalTag {
+		w.sb.WriteString(">")
+	}
+	if kind > NormalTag {
+		w.sb.WriteString(fmt.Sprintf("<%d:", kind))
+	}
+	w.tag = kind
+}
+
+func (w *markupWriter) Text(s string)        { w.sb.WriteString(s) }
+func (w *markupWriter) Newline(level int)    { w.sb.WriteString(" ") }
+func (w *markupWriter) Key(key string)       { w.sb.WriteString(key + "=") }
+func (w *markupWriter) Scalar(v interface{}) { w.sb.WriteString(fmt.Sprint(v)) }
+
+func TestCompareWithWriter(t *testing.T) {
+	opts := Options{ChangedSeparator: "->"}
+	var w markupWriter
+	result := CompareWithWriter([]byte(`{"a": 1, "b": [true], "c": "x"}`), []byte(`{"a": 2, "b": [true]}`), &opts, &w)
+	w.Tag(NoTag)
+	expected := fmt.Sprintf("{ a=<%d:1->2>, b=[ true ], <%d:c=x> }", ChangedTag, RemovedTag)
+	if result != NoMatch || w.sb.String() != expected {
+		t.Errorf("got %s: %q, expected NoMatch: %q", result, w.sb.String(), expected)
+	}
+}
+
+func TestGlyphs(t *testing.T) {
+	opts := Options{
+		Indent:           " ",
+		ChangedSeparator: " => ",
+		Added:            Tag{Begin: "<", End: ">"},
+		SkipMatches:      true,
+		Glyphs:           Glyphs{Normal: ". ", Added: "+ ", Removed: "- ", Changed: "~ "},
+	}
+	_, diff := Compare([]byte(`{"a": 1, "b": 2}`), []byte(`{"a": 2, "c": {"d": 1}}`), &opts)
+	expected := ". {\n" +
+		"~  \"a\": 1 => 2,\n" +
+		"-  \"b\": 2,\n" +
+		"+  <\"c\": {>\n" +
+		"+   <\"d\": 1>\n" +
+		"+  <}>\n" +
+		". }"
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+}
+
+func TestMaxLineWidth(t *testing.T) {
+	opts := Options{
+		Indent:           "  ",
+		ChangedSeparator: " => ",
+		Changed:          Tag{Begin: "<", End: ">"},
+		Glyphs:           Glyphs{Changed: "~"},
+		MaxLineWidth:     16,
+	}
+	_, diff := Compare([]byte(`{"a": "0123456789abcdef", "b": 1}`), []byte(`{"a": "x", "b": 1}`), &opts)
+	expected := "{\n" +
+		"~  \"a\": <\"01234567>\n" +
+		"~    <89abcdef\" =>>\n" +
+		"~    < \"x\">,\n" +
+		"  \"b\": 1\n" +
+		"}"
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+}
+
+func TestQuoteMode(t *testing.T) {
+	s := "h\u00e9llo\u200b\n\"\\\x01 \U0001F600"
+	cases := []struct {
+		mode     QuoteMode
+		expected string
+	}{
+		{QuoteGo, `"héllo\u200b\n\"\\\x01 😀"`},
+		{QuoteJSON, `"h\u00e9llo\u200b\n\"\\\u0001 \ud83d\ude00"`},
+		{QuoteRaw, "\"h\u00e9llo\u200b\\n\\\"\\\\\\u0001 \U0001F600\""},
+	}
+	for _, c := range cases {
+		if got := quoteString(s, c.mode); got != c.expected {
+			t.Errorf("mode %d: got %s, expected %s", c.mode, got, c.expected)
+		}
+	}
+
+	opts := Options{QuoteMode: QuoteRaw}
+	_, diff := Compare([]byte(`{"\u043a\u200b": 1}`), []byte(`{"\u043a\u200b": 1}`), &opts)
+	if expected := "{\n\"\u043a\u200b\": 1\n}"; diff != expected {
+		t.Errorf("got %s, expected %s", diff, expected)
+	}
+}
+
+func TestUnquotedKeys(t *testing.T) {
+	opts := Options{Indent: " ", ChangedSeparator: " => ", UnquotedKeys: true}
+	_, diff := Compare([]byte(`{"name": "a", "_id2": 1, "2x": 1, "a-b": 1, "": 1, "ключ": 1}`), []byte(`{"name": "b", "_id2": 1, "2x": 1, "a-b": 1, "": 1, "ключ": 1}`), &opts)
+	expected := `{
+ "": 1,
+ "2x": 1,
+ _id2: 1,
+ "a-b": 1,
+ name: "a" => "b",
+ ключ: 1
+}`
+	if diff != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", diff, expected)
+	}
+}